@@ -2,7 +2,11 @@ package deepseek
 
 import (
 	"context"
+	"errors"
+	"fmt"
 
+	"github.com/yu1ec/go-anyllm/models"
+	"github.com/yu1ec/go-anyllm/pricing"
 	"github.com/yu1ec/go-anyllm/providers"
 	"github.com/yu1ec/go-anyllm/response"
 	"github.com/yu1ec/go-anyllm/types"
@@ -10,9 +14,16 @@ import (
 	// 导入服务商包以触发注册
 	_ "github.com/yu1ec/go-anyllm/providers/alicloud"
 	_ "github.com/yu1ec/go-anyllm/providers/deepseek"
+	_ "github.com/yu1ec/go-anyllm/providers/ollama"
 	_ "github.com/yu1ec/go-anyllm/providers/openai"
 )
 
+// ErrEmbeddingsNotSupported 服务商不支持向量化
+var ErrEmbeddingsNotSupported = errors.New("deepseek: embeddings are not supported by this provider")
+
+// ErrListModelsNotSupported 服务商不支持列出模型
+var ErrListModelsNotSupported = errors.New("deepseek: listing models is not supported by this provider")
+
 // UnifiedClient 统一的AI客户端接口，兼容OpenAI风格
 type UnifiedClient interface {
 	// CreateChatCompletion 创建聊天完成（非流式）
@@ -28,6 +39,21 @@ type UnifiedClient interface {
 	GetProviderName() string
 }
 
+// EmbeddingClient 支持向量化的客户端
+// NewUnifiedClient 返回的客户端都实现了该接口，单独定义是为了不改变 UnifiedClient 的方法集
+type EmbeddingClient interface {
+	// CreateEmbeddings 创建向量，服务商不支持向量化时返回 ErrEmbeddingsNotSupported
+	CreateEmbeddings(ctx context.Context, req *types.EmbeddingRequest) (*types.EmbeddingResponse, error)
+}
+
+// ModelListClient 支持列出模型的客户端
+// NewUnifiedClient 返回的客户端都实现了该接口，单独定义是为了不改变 UnifiedClient 的方法集
+type ModelListClient interface {
+	// ListModels 列出服务商提供的模型，并附带内置能力表中的能力信息
+	// 服务商没有实现 providers.ModelLister 时返回 ErrListModelsNotSupported
+	ListModels(ctx context.Context) ([]models.Info, error)
+}
+
 // ClientConfig 统一客户端配置
 type ClientConfig struct {
 	Provider     providers.ProviderType
@@ -38,12 +64,26 @@ type ClientConfig struct {
 
 	// 特定服务商配置
 	OpenAIOrgID string // OpenAI组织ID
+
+	// 请求校验，发送请求前按模型约束校验请求
+	Registry       *models.Registry // 模型约束注册表，为nil时使用 models.DefaultRegistry
+	SkipValidation bool             // 跳过请求校验
+
+	// 费用统计，设置 CostTracker 后按价格表累计每次请求的费用
+	CostTracker *pricing.Tracker  // 为nil时不统计
+	Pricing     *pricing.Registry // 价格表，为nil时使用 pricing.DefaultRegistry
+	CostTag     string            // 默认的费用标签，可以用 pricing.WithTag 按请求覆盖
 }
 
 // unifiedClient 统一客户端实现
 type unifiedClient struct {
 	provider providers.Provider
 	factory  providers.ProviderFactory
+	registry *models.Registry // 为nil时不校验请求
+
+	costTracker *pricing.Tracker // 为nil时不统计费用
+	pricing     *pricing.Registry
+	costTag     string
 }
 
 // NewUnifiedClient 创建统一客户端
@@ -82,25 +122,103 @@ func NewUnifiedClient(config *ClientConfig) (UnifiedClient, error) {
 		return nil, err
 	}
 
-	return &unifiedClient{
+	client := &unifiedClient{
 		provider: provider,
 		factory:  factory,
-	}, nil
+	}
+	if !config.SkipValidation {
+		client.registry = config.Registry
+		if client.registry == nil {
+			client.registry = models.DefaultRegistry
+		}
+	}
+	if config.CostTracker != nil {
+		client.costTracker = config.CostTracker
+		client.costTag = config.CostTag
+		client.pricing = config.Pricing
+		if client.pricing == nil {
+			client.pricing = pricing.DefaultRegistry
+		}
+	}
+	return client, nil
 }
 
 // CreateChatCompletion 实现UnifiedClient接口
 func (c *unifiedClient) CreateChatCompletion(ctx context.Context, req *types.ChatCompletionRequest) (*types.ChatCompletionResponse, error) {
-	return c.provider.CreateChatCompletion(ctx, req)
+	if err := c.validate(req); err != nil {
+		return nil, err
+	}
+	resp, err := c.provider.CreateChatCompletion(ctx, req)
+	if err != nil {
+		return resp, err
+	}
+	model := resp.Model
+	if model == "" {
+		model = req.Model
+	}
+	c.recordCost(ctx, model, resp.Usage, resp.Created)
+	return resp, nil
 }
 
 // CreateChatCompletionStream 实现UnifiedClient接口
 func (c *unifiedClient) CreateChatCompletionStream(ctx context.Context, req *types.ChatCompletionRequest) (response.StreamReader, error) {
+	if err := c.validate(req); err != nil {
+		return nil, err
+	}
+	injectedUsage := c.costTracker != nil && req.StreamOptions == nil
+	if injectedUsage {
+		// 统计费用需要流的最后一个数据块带上用量，该数据块不返回给调用方
+		withUsage := *req
+		withUsage.StreamOptions = &types.StreamOptions{IncludeUsage: true}
+		req = &withUsage
+	}
 	respBody, err := c.provider.CreateChatCompletionStream(ctx, req)
 	if err != nil {
 		return nil, err
 	}
 
-	return response.NewStreamReader(respBody), nil
+	stream := response.NewStreamReader(respBody)
+	if c.costTracker == nil {
+		return stream, nil
+	}
+	return &costStreamReader{
+		StreamReader:   stream,
+		hideUsageChunk: injectedUsage,
+		model:          req.Model,
+		record: func(model string, usage *types.Usage, created int64) {
+			c.recordCost(ctx, model, usage, created)
+		},
+	}, nil
+}
+
+// validate 发送请求前按模型约束校验请求
+func (c *unifiedClient) validate(req *types.ChatCompletionRequest) error {
+	if c.registry == nil {
+		return nil
+	}
+	return c.registry.Validate(c.provider.GetName(), req)
+}
+
+// CreateEmbeddings 实现EmbeddingClient接口
+func (c *unifiedClient) CreateEmbeddings(ctx context.Context, req *types.EmbeddingRequest) (*types.EmbeddingResponse, error) {
+	embedder, ok := c.provider.(providers.EmbeddingProvider)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrEmbeddingsNotSupported, c.provider.GetName())
+	}
+	return embedder.CreateEmbeddings(ctx, req)
+}
+
+// ListModels 实现ModelListClient接口
+func (c *unifiedClient) ListModels(ctx context.Context) ([]models.Info, error) {
+	lister, ok := c.provider.(providers.ModelLister)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrListModelsNotSupported, c.provider.GetName())
+	}
+	listed, err := lister.ListModels(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return models.Merge(c.provider.GetName(), listed), nil
 }
 
 // GetProvider 实现UnifiedClient接口
@@ -148,6 +266,18 @@ func NewAliCloudClient(apiKey string) (UnifiedClient, error) {
 	return NewUnifiedClient(config)
 }
 
+// NewOllamaClient 创建Ollama客户端，默认连接 http://localhost:11434
+func NewOllamaClient(baseURL ...string) (UnifiedClient, error) {
+	config := &ClientConfig{
+		Provider: providers.ProviderOllama,
+		Timeout:  300,
+	}
+	if len(baseURL) > 0 {
+		config.BaseURL = baseURL[0]
+	}
+	return NewUnifiedClient(config)
+}
+
 // NewClientWithProvider 使用指定服务商创建客户端
 func NewClientWithProvider(providerType providers.ProviderType, apiKey string, baseURL ...string) (UnifiedClient, error) {
 	config := &ClientConfig{
@@ -0,0 +1,260 @@
+// Package conversation 管理多轮对话的历史消息
+// Conversation 负责追加用户、助手和工具消息，保证助手的工具调用和工具结果成对出现，
+// 并在发送前按上下文窗口策略裁剪历史
+package conversation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/yu1ec/go-anyllm/types"
+)
+
+var (
+	// ErrPendingToolCalls 助手的工具调用还有未返回的结果
+	ErrPendingToolCalls = errors.New("conversation: assistant tool calls are still waiting for results")
+	// ErrUnknownToolCall 工具结果没有对应的待处理工具调用
+	ErrUnknownToolCall = errors.New("conversation: tool result does not match a pending tool call")
+)
+
+// Conversation 一个会话的历史消息，可安全地并发使用
+type Conversation struct {
+	mutex          sync.RWMutex
+	systemPrompt   string
+	history        []types.ChatCompletionMessage
+	pending        []types.ToolCall
+	counter        TokenCounter
+	policy         ContextPolicy
+	maxTokens      int
+	reservedTokens int
+}
+
+// Option 会话配置选项
+type Option func(*Conversation)
+
+// WithSystemPrompt 设置系统提示词，系统提示词在裁剪时总是保留
+func WithSystemPrompt(prompt string) Option {
+	return func(c *Conversation) {
+		c.systemPrompt = prompt
+	}
+}
+
+// WithTokenCounter 设置token计数器，默认使用 EstimateTokenCounter
+func WithTokenCounter(counter TokenCounter) Option {
+	return func(c *Conversation) {
+		c.counter = counter
+	}
+}
+
+// WithContextWindow 设置上下文窗口大小（token数），为0时不裁剪
+func WithContextWindow(maxTokens int) Option {
+	return func(c *Conversation) {
+		c.maxTokens = maxTokens
+	}
+}
+
+// WithReservedTokens 为模型回复预留的token数，从上下文窗口中扣除
+func WithReservedTokens(tokens int) Option {
+	return func(c *Conversation) {
+		c.reservedTokens = tokens
+	}
+}
+
+// WithPolicy 设置上下文窗口策略，默认使用 DropOldestPolicy
+func WithPolicy(policy ContextPolicy) Option {
+	return func(c *Conversation) {
+		c.policy = policy
+	}
+}
+
+// New 创建会话
+func New(opts ...Option) *Conversation {
+	c := &Conversation{
+		counter: EstimateTokenCounter,
+		policy:  DropOldestPolicy{},
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// SetSystemPrompt 设置系统提示词
+func (c *Conversation) SetSystemPrompt(prompt string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.systemPrompt = prompt
+}
+
+// SystemPrompt 返回系统提示词
+func (c *Conversation) SystemPrompt() string {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return c.systemPrompt
+}
+
+// AddUser 追加一条用户文本消息
+func (c *Conversation) AddUser(content string) error {
+	return c.AddMessage(types.NewTextMessage(types.RoleUser, content))
+}
+
+// AddAssistant 追加一条助手文本消息
+func (c *Conversation) AddAssistant(content string) error {
+	return c.AddMessage(types.NewTextMessage(types.RoleAssistant, content))
+}
+
+// AddToolResult 追加一条工具结果，toolCallID 必须对应一个待处理的工具调用
+func (c *Conversation) AddToolResult(toolCallID, content string) error {
+	return c.AddMessage(types.ChatCompletionMessage{
+		Role:       types.RoleTool,
+		Content:    content,
+		ToolCallID: toolCallID,
+	})
+}
+
+// AddResponse 追加模型回复中第一个选择的消息
+func (c *Conversation) AddResponse(resp *types.ChatCompletionResponse) error {
+	if resp == nil || len(resp.Choices) == 0 || resp.Choices[0].Message == nil {
+		return errors.New("conversation: response has no message")
+	}
+	return c.AddMessage(*resp.Choices[0].Message)
+}
+
+// AddMessage 追加任意角色的消息
+// 系统消息会替换系统提示词；在工具调用的结果全部返回之前不能追加用户或助手消息
+func (c *Conversation) AddMessage(message types.ChatCompletionMessage) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	switch message.Role {
+	case types.RoleSystem:
+		c.systemPrompt = message.GetContentAsString()
+		return nil
+	case types.RoleTool:
+		index := -1
+		for i, toolCall := range c.pending {
+			if toolCall.ID == message.ToolCallID {
+				index = i
+				break
+			}
+		}
+		if index < 0 {
+			return fmt.Errorf("%w: %q", ErrUnknownToolCall, message.ToolCallID)
+		}
+		c.pending = append(c.pending[:index], c.pending[index+1:]...)
+	case types.RoleUser, types.RoleAssistant:
+		if len(c.pending) > 0 {
+			return ErrPendingToolCalls
+		}
+		if message.Role == types.RoleAssistant && len(message.ToolCalls) > 0 {
+			c.pending = append([]types.ToolCall(nil), message.ToolCalls...)
+		}
+	default:
+		return fmt.Errorf("conversation: unsupported role %q", message.Role)
+	}
+
+	c.history = append(c.history, message)
+	return nil
+}
+
+// PendingToolCalls 返回还没有结果的工具调用
+func (c *Conversation) PendingToolCalls() []types.ToolCall {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return append([]types.ToolCall(nil), c.pending...)
+}
+
+// Messages 返回完整的消息列表（包括系统提示词），不做裁剪
+func (c *Conversation) Messages() []types.ChatCompletionMessage {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return withSystemPrompt(c.systemPrompt, c.history)
+}
+
+// Len 返回历史消息数量，不包括系统提示词
+func (c *Conversation) Len() int {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return len(c.history)
+}
+
+// TokenCount 返回完整消息列表的token数
+func (c *Conversation) TokenCount() int {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return countMessages(c.counter, withSystemPrompt(c.systemPrompt, c.history))
+}
+
+// Clear 清空历史消息，保留系统提示词
+// 有状态的策略（如 SummarizePolicy）会同时被重置
+func (c *Conversation) Clear() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.history = nil
+	c.pending = nil
+	if resetter, ok := c.policy.(interface{ Reset() }); ok {
+		resetter.Reset()
+	}
+}
+
+// ContextMessages 返回按上下文窗口策略裁剪后的消息列表
+// 返回 ErrContextOverflow 时仍会返回尽量裁剪后的消息（至少包含系统提示词和最近一轮），由调用方决定是否继续发送
+func (c *Conversation) ContextMessages(ctx context.Context) ([]types.ChatCompletionMessage, error) {
+	c.mutex.RLock()
+	if len(c.pending) > 0 {
+		c.mutex.RUnlock()
+		return nil, ErrPendingToolCalls
+	}
+	history := append([]types.ChatCompletionMessage(nil), c.history...)
+	systemPrompt := c.systemPrompt
+	counter, policy := c.counter, c.policy
+	budget := 0
+	if c.maxTokens > 0 {
+		budget = c.maxTokens - c.reservedTokens
+		if systemPrompt != "" {
+			budget -= counter.CountTokens(types.NewTextMessage(types.RoleSystem, systemPrompt))
+		}
+	}
+	c.mutex.RUnlock()
+	if c.maxTokens > 0 && budget <= 0 {
+		// 系统提示词和预留token已经占满上下文窗口，只保留最近一轮
+		return withSystemPrompt(systemPrompt, latestTurn(history)), ErrContextOverflow
+	}
+
+	fitted, err := policy.Fit(ctx, history, budget, counter)
+	if fitted == nil && err != nil {
+		return nil, err
+	}
+	return withSystemPrompt(systemPrompt, fitted), err
+}
+
+// Apply 把裁剪后的消息列表写入请求
+// 返回 ErrContextOverflow 时同样会写入尽量裁剪后的消息，由调用方决定是否继续发送
+func (c *Conversation) Apply(ctx context.Context, req *types.ChatCompletionRequest) error {
+	messages, err := c.ContextMessages(ctx)
+	if err != nil && !errors.Is(err, ErrContextOverflow) {
+		return err
+	}
+	req.Messages = messages
+	return err
+}
+
+// latestTurn 返回历史中最近一轮的消息
+func latestTurn(history []types.ChatCompletionMessage) []types.ChatCompletionMessage {
+	turns := SplitTurns(history)
+	if len(turns) == 0 {
+		return nil
+	}
+	return turns[len(turns)-1].Messages()
+}
+
+// withSystemPrompt 在历史消息前加上系统提示词
+func withSystemPrompt(systemPrompt string, history []types.ChatCompletionMessage) []types.ChatCompletionMessage {
+	messages := make([]types.ChatCompletionMessage, 0, len(history)+1)
+	if systemPrompt != "" {
+		messages = append(messages, types.NewTextMessage(types.RoleSystem, systemPrompt))
+	}
+	return append(messages, history...)
+}
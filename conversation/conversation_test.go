@@ -0,0 +1,214 @@
+package conversation
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/yu1ec/go-anyllm/tokenizer"
+	"github.com/yu1ec/go-anyllm/types"
+)
+
+// 每条消息计为1个token，便于计算预算
+var oneTokenCounter = TokenCounterFunc(func(types.ChatCompletionMessage) int { return 1 })
+
+func toolCallMessage(ids ...string) types.ChatCompletionMessage {
+	message := types.ChatCompletionMessage{Role: types.RoleAssistant}
+	for _, id := range ids {
+		message.ToolCalls = append(message.ToolCalls, types.ToolCall{
+			ID:       id,
+			Type:     "function",
+			Function: types.ResponseToolFunction{Name: "get_weather", Arguments: `{"location":"北京"}`},
+		})
+	}
+	return message
+}
+
+func roles(messages []types.ChatCompletionMessage) string {
+	var parts []string
+	for _, message := range messages {
+		parts = append(parts, message.Role)
+	}
+	return strings.Join(parts, ",")
+}
+
+func TestConversationToolCallPairing(t *testing.T) {
+	conv := New(WithSystemPrompt("你是一个助手"))
+	if err := conv.AddUser("北京和上海天气如何？"); err != nil {
+		t.Fatal(err)
+	}
+	if err := conv.AddMessage(toolCallMessage("call_1", "call_2")); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := conv.AddUser("还在吗？"); !errors.Is(err, ErrPendingToolCalls) {
+		t.Errorf("期望 ErrPendingToolCalls，得到 %v", err)
+	}
+	if _, err := conv.ContextMessages(context.Background()); !errors.Is(err, ErrPendingToolCalls) {
+		t.Errorf("工具结果未返回时不应生成上下文，得到 %v", err)
+	}
+	if err := conv.AddToolResult("call_9", "{}"); !errors.Is(err, ErrUnknownToolCall) {
+		t.Errorf("期望 ErrUnknownToolCall，得到 %v", err)
+	}
+
+	if err := conv.AddToolResult("call_2", `{"temperature":25}`); err != nil {
+		t.Fatal(err)
+	}
+	if pending := conv.PendingToolCalls(); len(pending) != 1 || pending[0].ID != "call_1" {
+		t.Errorf("期望剩余 call_1，得到 %+v", pending)
+	}
+	if err := conv.AddToolResult("call_2", "{}"); !errors.Is(err, ErrUnknownToolCall) {
+		t.Errorf("重复的工具结果应被拒绝，得到 %v", err)
+	}
+	if err := conv.AddToolResult("call_1", `{"temperature":20}`); err != nil {
+		t.Fatal(err)
+	}
+
+	err := conv.AddResponse(&types.ChatCompletionResponse{Choices: []types.ChatCompletionChoice{
+		{Message: &types.ChatCompletionMessage{Role: types.RoleAssistant, Content: "北京20度，上海25度"}},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req := &types.ChatCompletionRequest{Model: "gpt-4o"}
+	if err := conv.Apply(context.Background(), req); err != nil {
+		t.Fatal(err)
+	}
+	if got := roles(req.Messages); got != "system,user,assistant,tool,tool,assistant" {
+		t.Errorf("消息顺序错误: %s", got)
+	}
+	if conv.Len() != 5 || conv.TokenCount() <= 0 {
+		t.Errorf("期望5条历史消息，得到 %d (tokens=%d)", conv.Len(), conv.TokenCount())
+	}
+}
+
+func TestDropOldestPolicy(t *testing.T) {
+	history := []types.ChatCompletionMessage{
+		types.NewTextMessage(types.RoleUser, "第一轮"),
+		toolCallMessage("call_1"),
+		{Role: types.RoleTool, ToolCallID: "call_1", Content: "结果1"},
+		types.NewTextMessage(types.RoleAssistant, "回答1"),
+		types.NewTextMessage(types.RoleUser, "第二轮"),
+		types.NewTextMessage(types.RoleAssistant, "回答2"),
+	}
+
+	fitted, err := DropOldestPolicy{}.Fit(context.Background(), history, 3, oneTokenCounter)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := roles(fitted); got != "user,assistant" || fitted[0].Content != "第二轮" {
+		t.Errorf("应整轮丢弃最早的对话，得到 %s", got)
+	}
+
+	// 最近一轮本身超出预算时，丢弃其中最早的工具调用组，但不产生孤立的工具消息
+	latest := []types.ChatCompletionMessage{
+		types.NewTextMessage(types.RoleUser, "查询"),
+		toolCallMessage("call_1"),
+		{Role: types.RoleTool, ToolCallID: "call_1", Content: "结果1"},
+		toolCallMessage("call_2"),
+		{Role: types.RoleTool, ToolCallID: "call_2", Content: "结果2"},
+		types.NewTextMessage(types.RoleAssistant, "完成"),
+	}
+	fitted, err = DropOldestPolicy{}.Fit(context.Background(), latest, 3, oneTokenCounter)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := roles(fitted); got != "user,assistant" || fitted[1].Content != "完成" {
+		t.Errorf("工具调用组应整体丢弃，得到 %s", got)
+	}
+	fitted, err = DropOldestPolicy{}.Fit(context.Background(), latest, 4, oneTokenCounter)
+	if err != nil || roles(fitted) != "user,assistant,tool,assistant" || fitted[1].ToolCalls[0].ID != "call_2" {
+		t.Errorf("应保留最近的工具调用组，得到 %s %v", roles(fitted), err)
+	}
+
+	if _, err := (DropOldestPolicy{}).Fit(context.Background(), latest, 1, oneTokenCounter); !errors.Is(err, ErrContextOverflow) {
+		t.Errorf("期望 ErrContextOverflow，得到 %v", err)
+	}
+}
+
+func TestConversationContextWindow(t *testing.T) {
+	conv := New(
+		WithSystemPrompt("system"),
+		WithTokenCounter(oneTokenCounter),
+		WithContextWindow(5),
+		WithReservedTokens(1),
+	)
+	for _, text := range []string{"1", "2", "3"} {
+		conv.AddUser("问题" + text)
+		conv.AddAssistant("回答" + text)
+	}
+
+	messages, err := conv.ContextMessages(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 预算 = 5 - 1(预留) - 1(系统提示词) = 3，只能保留最后一轮
+	if got := roles(messages); got != "system,user,assistant" || messages[1].Content != "问题3" {
+		t.Errorf("裁剪结果错误: %s", got)
+	}
+	if len(conv.Messages()) != 7 {
+		t.Errorf("裁剪不应修改历史，得到 %d 条消息", len(conv.Messages()))
+	}
+}
+
+func TestConversationContextWindowExhausted(t *testing.T) {
+	conv := New(
+		WithSystemPrompt("system"),
+		WithTokenCounter(oneTokenCounter),
+		WithContextWindow(2),
+		WithReservedTokens(1),
+	)
+	for _, text := range []string{"1", "2"} {
+		conv.AddUser("问题" + text)
+		conv.AddAssistant("回答" + text)
+	}
+
+	// 预算 = 2 - 1(预留) - 1(系统提示词) = 0，仍返回系统提示词和最近一轮
+	messages, err := conv.ContextMessages(context.Background())
+	if !errors.Is(err, ErrContextOverflow) {
+		t.Fatalf("期望 ErrContextOverflow，得到 %v", err)
+	}
+	if got := roles(messages); got != "system,user,assistant" || messages[1].Content != "问题2" {
+		t.Errorf("裁剪结果错误: %s", got)
+	}
+
+	req := &types.ChatCompletionRequest{}
+	if err := conv.Apply(context.Background(), req); !errors.Is(err, ErrContextOverflow) {
+		t.Fatalf("期望 ErrContextOverflow，得到 %v", err)
+	}
+	if got := roles(req.Messages); got != "system,user,assistant" {
+		t.Errorf("超出上下文窗口时也应写入裁剪后的消息，得到 %s", got)
+	}
+}
+
+func TestEstimateMessageTokens(t *testing.T) {
+	if got := EstimateTokens("你好世界"); got != 4 {
+		t.Errorf("期望4，得到 %d", got)
+	}
+	if got := EstimateTokens("hello world!"); got != 3 {
+		t.Errorf("期望3，得到 %d", got)
+	}
+
+	text := EstimateMessageTokens(types.NewTextMessage(types.RoleUser, "看图"))
+	image := EstimateMessageTokens(types.NewMultiModalMessage(types.RoleUser, []types.MessageContent{
+		types.NewTextContent("看图"),
+		types.NewImageContent("https://example.com/a.png", types.ImageDetailLow),
+	}))
+	if image-text != 85 {
+		t.Errorf("图像应计 85 个token，得到 %d", image-text)
+	}
+	if EstimateMessageTokens(toolCallMessage("call_1")) <= EstimateMessageTokens(types.NewTextMessage(types.RoleAssistant, "")) {
+		t.Error("工具调用应计入token")
+	}
+
+	message := toolCallMessage("call_1")
+	if got, want := EstimateMessageTokens(message), tokenizer.CountMessage(tokenizer.DefaultEstimator, message); got != want {
+		t.Errorf("期望与 tokenizer.CountMessage 一致（%d），得到 %d", want, got)
+	}
+	counter := TokenizerCounter(tokenizer.Estimator{BytesPerToken: 2})
+	if got := counter.CountTokens(types.NewTextMessage(types.RoleUser, "hello world!")); got <= EstimateMessageTokens(types.NewTextMessage(types.RoleUser, "hello world!")) {
+		t.Errorf("期望使用传入的分词器，得到 %d", got)
+	}
+}
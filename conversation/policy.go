@@ -0,0 +1,129 @@
+package conversation
+
+import (
+	"context"
+	"errors"
+
+	"github.com/yu1ec/go-anyllm/types"
+)
+
+// ErrContextOverflow 即使只保留最近的轮次，历史仍然超出token预算
+var ErrContextOverflow = errors.New("conversation: history exceeds the context window even after truncation")
+
+// ContextPolicy 上下文窗口策略，在发送前把历史消息裁剪到预算之内
+// history 不包含系统提示词，budget 是留给历史消息的token数
+type ContextPolicy interface {
+	Fit(ctx context.Context, history []types.ChatCompletionMessage, budget int, counter TokenCounter) ([]types.ChatCompletionMessage, error)
+}
+
+// ContextPolicyFunc 函数形式的ContextPolicy
+type ContextPolicyFunc func(ctx context.Context, history []types.ChatCompletionMessage, budget int, counter TokenCounter) ([]types.ChatCompletionMessage, error)
+
+// Fit 实现ContextPolicy接口
+func (f ContextPolicyFunc) Fit(ctx context.Context, history []types.ChatCompletionMessage, budget int, counter TokenCounter) ([]types.ChatCompletionMessage, error) {
+	return f(ctx, history, budget, counter)
+}
+
+// DropOldestPolicy 默认策略：从最早的轮次开始整轮丢弃
+// 如果最近一轮本身仍然超出预算，则保留该轮的用户消息，再从最早的工具调用组开始丢弃
+// 工具调用组（带ToolCalls的助手消息及其工具结果）总是整体保留或整体丢弃
+type DropOldestPolicy struct{}
+
+// Fit 实现ContextPolicy接口
+func (DropOldestPolicy) Fit(ctx context.Context, history []types.ChatCompletionMessage, budget int, counter TokenCounter) ([]types.ChatCompletionMessage, error) {
+	if budget <= 0 || countMessages(counter, history) <= budget {
+		return history, nil
+	}
+
+	turns := SplitTurns(history)
+	total := 0
+	for _, turn := range turns {
+		total += turn.tokens(counter)
+	}
+	for len(turns) > 1 && total > budget {
+		total -= turns[0].tokens(counter)
+		turns = turns[1:]
+	}
+
+	last := turns[len(turns)-1]
+	if total > budget && len(last.units) > 2 {
+		// 保留第一个单元（通常是用户消息）和最后一个单元
+		units := append([][]types.ChatCompletionMessage(nil), last.units...)
+		for len(units) > 2 && total > budget {
+			total -= countMessages(counter, units[1])
+			units = append(units[:1], units[2:]...)
+		}
+		turns[len(turns)-1] = Turn{units: units}
+	}
+
+	var fitted []types.ChatCompletionMessage
+	for _, turn := range turns {
+		fitted = append(fitted, turn.Messages()...)
+	}
+	if total > budget {
+		return fitted, ErrContextOverflow
+	}
+	return fitted, nil
+}
+
+// Turn 一轮对话：从一条用户消息开始，直到下一条用户消息之前
+// 轮次内部按原子单元划分，工具调用组是一个单元，其他消息各自是一个单元
+type Turn struct {
+	units [][]types.ChatCompletionMessage
+}
+
+// Messages 返回该轮的全部消息
+func (t Turn) Messages() []types.ChatCompletionMessage {
+	var messages []types.ChatCompletionMessage
+	for _, unit := range t.units {
+		messages = append(messages, unit...)
+	}
+	return messages
+}
+
+func (t Turn) tokens(counter TokenCounter) int {
+	total := 0
+	for _, unit := range t.units {
+		total += countMessages(counter, unit)
+	}
+	return total
+}
+
+// SplitTurns 把历史消息按轮次切分，第一条用户消息之前的消息单独成为一轮
+// 没有对应助手消息的工具结果会被丢弃，避免发送孤立的工具消息
+func SplitTurns(history []types.ChatCompletionMessage) []Turn {
+	var turns []Turn
+	var current Turn
+	flush := func() {
+		if len(current.units) > 0 {
+			turns = append(turns, current)
+		}
+		current = Turn{}
+	}
+
+	for i := 0; i < len(history); i++ {
+		message := history[i]
+		switch {
+		case message.Role == types.RoleUser:
+			flush()
+			current.units = append(current.units, []types.ChatCompletionMessage{message})
+		case message.Role == types.RoleAssistant && len(message.ToolCalls) > 0:
+			ids := make(map[string]bool, len(message.ToolCalls))
+			for _, toolCall := range message.ToolCalls {
+				ids[toolCall.ID] = true
+			}
+			unit := []types.ChatCompletionMessage{message}
+			for i+1 < len(history) && history[i+1].Role == types.RoleTool && ids[history[i+1].ToolCallID] {
+				i++
+				unit = append(unit, history[i])
+			}
+			current.units = append(current.units, unit)
+		case message.Role == types.RoleTool:
+			// 孤立的工具结果
+		default:
+			current.units = append(current.units, []types.ChatCompletionMessage{message})
+		}
+	}
+	flush()
+	return turns
+}
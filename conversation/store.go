@@ -0,0 +1,311 @@
+package conversation
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/yu1ec/go-anyllm/types"
+)
+
+// ErrSessionNotFound 会话不存在
+var ErrSessionNotFound = errors.New("conversation: session not found")
+
+// ConversationStore 会话持久化接口，按会话ID保存完整的消息列表（包括系统提示词）
+type ConversationStore interface {
+	// Load 加载会话的全部消息，会话不存在时返回 ErrSessionNotFound
+	Load(ctx context.Context, sessionID string) ([]types.ChatCompletionMessage, error)
+	// Save 用给定的消息替换会话的全部内容
+	Save(ctx context.Context, sessionID string, messages []types.ChatCompletionMessage) error
+	// Append 在会话末尾追加消息，会话不存在时自动创建
+	Append(ctx context.Context, sessionID string, messages ...types.ChatCompletionMessage) error
+	// List 列出所有会话ID
+	List(ctx context.Context) ([]string, error)
+	// Delete 删除会话，会话不存在时返回 ErrSessionNotFound
+	Delete(ctx context.Context, sessionID string) error
+}
+
+// Load 从存储中加载会话，存储中的系统消息会成为会话的系统提示词
+func Load(ctx context.Context, store ConversationStore, sessionID string, opts ...Option) (*Conversation, error) {
+	messages, err := store.Load(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	conv := New(opts...)
+	for i, message := range messages {
+		if err := conv.AddMessage(message); err != nil {
+			return nil, fmt.Errorf("conversation: session %q message %d: %w", sessionID, i, err)
+		}
+	}
+	return conv, nil
+}
+
+// Save 把会话的全部消息保存到存储
+func (c *Conversation) Save(ctx context.Context, store ConversationStore, sessionID string) error {
+	return store.Save(ctx, sessionID, c.Messages())
+}
+
+// MemoryStore 内存中的会话存储，进程重启后数据丢失，适合测试和单机场景
+type MemoryStore struct {
+	mutex    sync.RWMutex
+	sessions map[string][]types.ChatCompletionMessage
+}
+
+// NewMemoryStore 创建内存会话存储
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{sessions: make(map[string][]types.ChatCompletionMessage)}
+}
+
+// Load 实现ConversationStore接口
+func (s *MemoryStore) Load(ctx context.Context, sessionID string) ([]types.ChatCompletionMessage, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	messages, ok := s.sessions[sessionID]
+	if !ok {
+		return nil, ErrSessionNotFound
+	}
+	return append([]types.ChatCompletionMessage(nil), messages...), nil
+}
+
+// Save 实现ConversationStore接口
+func (s *MemoryStore) Save(ctx context.Context, sessionID string, messages []types.ChatCompletionMessage) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.sessions[sessionID] = append([]types.ChatCompletionMessage{}, messages...)
+	return nil
+}
+
+// Append 实现ConversationStore接口
+func (s *MemoryStore) Append(ctx context.Context, sessionID string, messages ...types.ChatCompletionMessage) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.sessions[sessionID] = append(s.sessions[sessionID], messages...)
+	return nil
+}
+
+// List 实现ConversationStore接口
+func (s *MemoryStore) List(ctx context.Context) ([]string, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	ids := make([]string, 0, len(s.sessions))
+	for id := range s.sessions {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
+
+// Delete 实现ConversationStore接口
+func (s *MemoryStore) Delete(ctx context.Context, sessionID string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if _, ok := s.sessions[sessionID]; !ok {
+		return ErrSessionNotFound
+	}
+	delete(s.sessions, sessionID)
+	return nil
+}
+
+// fileStoreExt 会话文件扩展名
+const fileStoreExt = ".jsonl"
+
+// FileStore 基于JSONL文件的会话存储，每个会话一个文件，每行一条消息
+// 会话ID经过base64编码后作为文件名，因此可以包含任意字符
+type FileStore struct {
+	dir   string
+	mutex sync.Mutex
+}
+
+// NewFileStore 创建文件会话存储，目录不存在时自动创建
+func NewFileStore(dir string) (*FileStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("conversation: create store directory: %w", err)
+	}
+	return &FileStore{dir: dir}, nil
+}
+
+// path 返回会话文件路径
+func (s *FileStore) path(sessionID string) string {
+	return filepath.Join(s.dir, base64.RawURLEncoding.EncodeToString([]byte(sessionID))+fileStoreExt)
+}
+
+// Load 实现ConversationStore接口
+// 进程在追加过程中退出时，文件末尾可能残留不完整的一行，该行会被忽略
+func (s *FileStore) Load(ctx context.Context, sessionID string) ([]types.ChatCompletionMessage, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	data, err := os.ReadFile(s.path(sessionID))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrSessionNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("conversation: load session %q: %w", sessionID, err)
+	}
+
+	// 每条消息都以换行结尾，缺少换行的最后一行是中断的写入
+	if i := bytes.LastIndexByte(data, '\n'); i != len(data)-1 {
+		data = data[:i+1]
+	}
+
+	var messages []types.ChatCompletionMessage
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), len(data)+1)
+	for line := 1; scanner.Scan(); line++ {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		var message types.ChatCompletionMessage
+		if err := json.Unmarshal(scanner.Bytes(), &message); err != nil {
+			return nil, fmt.Errorf("conversation: load session %q line %d: %w", sessionID, line, err)
+		}
+		messages = append(messages, message)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("conversation: load session %q: %w", sessionID, err)
+	}
+	return messages, nil
+}
+
+// Save 实现ConversationStore接口，先写入临时文件再替换，避免写到一半的文件
+func (s *FileStore) Save(ctx context.Context, sessionID string, messages []types.ChatCompletionMessage) error {
+	data, err := encodeMessages(messages)
+	if err != nil {
+		return fmt.Errorf("conversation: save session %q: %w", sessionID, err)
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	tmp, err := os.CreateTemp(s.dir, ".session-*")
+	if err != nil {
+		return fmt.Errorf("conversation: save session %q: %w", sessionID, err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("conversation: save session %q: %w", sessionID, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("conversation: save session %q: %w", sessionID, err)
+	}
+	if err := os.Rename(tmp.Name(), s.path(sessionID)); err != nil {
+		return fmt.Errorf("conversation: save session %q: %w", sessionID, err)
+	}
+	return nil
+}
+
+// Append 实现ConversationStore接口
+func (s *FileStore) Append(ctx context.Context, sessionID string, messages ...types.ChatCompletionMessage) error {
+	data, err := encodeMessages(messages)
+	if err != nil {
+		return fmt.Errorf("conversation: append to session %q: %w", sessionID, err)
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	path := s.path(sessionID)
+	if err := trimPartialLine(path); err != nil {
+		return fmt.Errorf("conversation: append to session %q: %w", sessionID, err)
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("conversation: append to session %q: %w", sessionID, err)
+	}
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return fmt.Errorf("conversation: append to session %q: %w", sessionID, err)
+	}
+	return file.Close()
+}
+
+// List 实现ConversationStore接口
+func (s *FileStore) List(ctx context.Context) ([]string, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, fmt.Errorf("conversation: list sessions: %w", err)
+	}
+	var ids []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, fileStoreExt) {
+			continue
+		}
+		id, err := base64.RawURLEncoding.DecodeString(strings.TrimSuffix(name, fileStoreExt))
+		if err != nil {
+			continue // 不是由FileStore创建的文件
+		}
+		ids = append(ids, string(id))
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
+
+// Delete 实现ConversationStore接口
+func (s *FileStore) Delete(ctx context.Context, sessionID string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	err := os.Remove(s.path(sessionID))
+	if errors.Is(err, os.ErrNotExist) {
+		return ErrSessionNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("conversation: delete session %q: %w", sessionID, err)
+	}
+	return nil
+}
+
+// trimPartialLine 截掉上一次中断的写入留下的不完整行，避免新消息和它拼在同一行
+func trimPartialLine(path string) error {
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil || info.Size() == 0 {
+		return err
+	}
+	last := make([]byte, 1)
+	if _, err := file.ReadAt(last, info.Size()-1); err != nil || last[0] == '\n' {
+		return err
+	}
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return err
+	}
+	return os.Truncate(path, int64(bytes.LastIndexByte(data, '\n')+1))
+}
+
+// encodeMessages 把消息编码为JSONL
+func encodeMessages(messages []types.ChatCompletionMessage) ([]byte, error) {
+	var buf bytes.Buffer
+	for _, message := range messages {
+		line, err := json.Marshal(message)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(line)
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes(), nil
+}
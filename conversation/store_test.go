@@ -0,0 +1,147 @@
+package conversation
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/yu1ec/go-anyllm/types"
+)
+
+func sampleMessages() []types.ChatCompletionMessage {
+	return []types.ChatCompletionMessage{
+		types.NewTextMessage(types.RoleSystem, "你是一个助手"),
+		types.NewMultiModalMessage(types.RoleUser, []types.MessageContent{
+			types.NewTextContent("这是哪里？"),
+			types.NewImageContent("data:image/png;base64,iVBORw0KGgo=", types.ImageDetailHigh),
+		}),
+		{
+			Role:             types.RoleAssistant,
+			ReasoningContent: "需要先查询天气",
+			ToolCalls: []types.ToolCall{{
+				Index:    types.ToPtr(0),
+				ID:       "call_1",
+				Type:     "function",
+				Function: types.ResponseToolFunction{Name: "get_weather", Arguments: `{"location":"北京"}`},
+			}},
+		},
+		{Role: types.RoleTool, ToolCallID: "call_1", Content: `{"temperature":20}`},
+		{Role: types.RoleAssistant, Content: "北京，20度"},
+	}
+}
+
+func TestStores(t *testing.T) {
+	fileStore, err := NewFileStore(filepath.Join(t.TempDir(), "sessions"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	stores := map[string]ConversationStore{
+		"memory": NewMemoryStore(),
+		"file":   fileStore,
+	}
+
+	for name, store := range stores {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			messages := sampleMessages()
+			sessionID := "user/1:../会话"
+
+			if _, err := store.Load(ctx, sessionID); !errors.Is(err, ErrSessionNotFound) {
+				t.Errorf("期望 ErrSessionNotFound，得到 %v", err)
+			}
+			if err := store.Save(ctx, sessionID, messages[:2]); err != nil {
+				t.Fatal(err)
+			}
+			if err := store.Append(ctx, sessionID, messages[2:]...); err != nil {
+				t.Fatal(err)
+			}
+			if err := store.Append(ctx, "other", messages[0]); err != nil {
+				t.Fatal(err)
+			}
+
+			loaded, err := store.Load(ctx, sessionID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(loaded, messages) {
+				t.Errorf("消息未能完整还原:\n期望 %+v\n得到 %+v", messages, loaded)
+			}
+
+			ids, err := store.List(ctx)
+			if err != nil || !reflect.DeepEqual(ids, []string{"other", sessionID}) {
+				t.Errorf("会话列表错误: %v %v", ids, err)
+			}
+
+			conv, err := Load(ctx, store, sessionID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if conv.SystemPrompt() != "你是一个助手" || conv.Len() != 4 {
+				t.Errorf("还原会话错误: %q %d", conv.SystemPrompt(), conv.Len())
+			}
+
+			if err := store.Delete(ctx, sessionID); err != nil {
+				t.Fatal(err)
+			}
+			if err := store.Delete(ctx, sessionID); !errors.Is(err, ErrSessionNotFound) {
+				t.Errorf("重复删除应返回 ErrSessionNotFound，得到 %v", err)
+			}
+		})
+	}
+}
+
+func TestFileStorePartialLine(t *testing.T) {
+	ctx := context.Background()
+	store, err := NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	messages := sampleMessages()
+	if err := store.Save(ctx, "s", messages[:1]); err != nil {
+		t.Fatal(err)
+	}
+
+	// 模拟追加过程中进程退出
+	file, err := os.OpenFile(store.path("s"), os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	file.WriteString(`{"role":"user","con`)
+	file.Close()
+
+	loaded, err := store.Load(ctx, "s")
+	if err != nil || len(loaded) != 1 {
+		t.Fatalf("应忽略不完整的最后一行: %d %v", len(loaded), err)
+	}
+
+	if err := store.Append(ctx, "s", messages[4]); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err = store.Load(ctx, "s")
+	if err != nil || len(loaded) != 2 || loaded[1].Content != "北京，20度" {
+		t.Errorf("追加前应清理不完整的行: %+v %v", loaded, err)
+	}
+}
+
+func TestConversationSave(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+	conv := New(WithSystemPrompt("system"))
+	conv.AddUser("你好")
+	conv.AddMessage(toolCallMessage("call_1"))
+	if err := conv.Save(ctx, store, "s"); err != nil {
+		t.Fatal(err)
+	}
+
+	// 还原后仍然记得未返回结果的工具调用
+	restored, err := Load(ctx, store, "s")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pending := restored.PendingToolCalls(); len(pending) != 1 || pending[0].ID != "call_1" {
+		t.Errorf("期望待处理的工具调用 call_1，得到 %+v", pending)
+	}
+}
@@ -0,0 +1,263 @@
+package conversation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/yu1ec/go-anyllm/types"
+)
+
+// DefaultSummaryPrompt 默认的摘要提示词
+const DefaultSummaryPrompt = "You maintain a running summary of a conversation between a user and an assistant. " +
+	"Merge the previous summary (if any) with the new messages into one concise summary. " +
+	"Keep facts, decisions, user preferences, open questions and tool results that are needed to continue the conversation. " +
+	"Reply with the summary only."
+
+// summaryPrefix 摘要系统消息的前缀
+const summaryPrefix = "Summary of the earlier conversation:\n"
+
+// ChatCompleter 用于生成摘要的客户端，deepseek.UnifiedClient 实现了该接口
+type ChatCompleter interface {
+	CreateChatCompletion(ctx context.Context, req *types.ChatCompletionRequest) (*types.ChatCompletionResponse, error)
+}
+
+// SummaryRecord 一次摘要的记录
+type SummaryRecord struct {
+	From      int       // 被替换的历史消息起始下标（包含，不计系统提示词）
+	To        int       // 被替换的历史消息结束下标（不包含）
+	Summary   string    // 摘要完成后的滚动摘要
+	CreatedAt time.Time // 摘要时间
+}
+
+// SummarizePolicy 摘要策略：历史超过阈值时，调用模型把窗口外的旧轮次合并成一条滚动摘要的系统消息
+// 策略是有状态的，每个 Conversation 应使用独立的实例
+type SummarizePolicy struct {
+	client     ChatCompleter
+	model      string
+	prompt     string
+	threshold  int
+	keepRecent int
+	maxTokens  int
+
+	mutex   sync.Mutex
+	summary string
+	covered int
+	records []SummaryRecord
+}
+
+// SummarizeOption 摘要策略配置选项
+type SummarizeOption func(*SummarizePolicy)
+
+// WithSummaryPrompt 设置摘要提示词
+func WithSummaryPrompt(prompt string) SummarizeOption {
+	return func(p *SummarizePolicy) {
+		p.prompt = prompt
+	}
+}
+
+// WithSummaryThreshold 设置触发摘要的token阈值，默认等于上下文预算
+func WithSummaryThreshold(tokens int) SummarizeOption {
+	return func(p *SummarizePolicy) {
+		p.threshold = tokens
+	}
+}
+
+// WithSummaryKeepRecent 设置摘要时原样保留的最近消息的token数，默认为阈值的一半
+// 最近一轮总是原样保留
+func WithSummaryKeepRecent(tokens int) SummarizeOption {
+	return func(p *SummarizePolicy) {
+		p.keepRecent = tokens
+	}
+}
+
+// WithSummaryMaxTokens 限制摘要回复的最大token数
+func WithSummaryMaxTokens(tokens int) SummarizeOption {
+	return func(p *SummarizePolicy) {
+		p.maxTokens = tokens
+	}
+}
+
+// NewSummarizePolicy 创建摘要策略，client 可以是比对话模型更便宜的模型
+func NewSummarizePolicy(client ChatCompleter, model string, opts ...SummarizeOption) *SummarizePolicy {
+	p := &SummarizePolicy{
+		client: client,
+		model:  model,
+		prompt: DefaultSummaryPrompt,
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
+
+// Summary 返回当前的滚动摘要
+func (p *SummarizePolicy) Summary() string {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return p.summary
+}
+
+// Summaries 返回每次摘要替换的消息区间
+func (p *SummarizePolicy) Summaries() []SummaryRecord {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return append([]SummaryRecord(nil), p.records...)
+}
+
+// Reset 清除摘要状态
+func (p *SummarizePolicy) Reset() {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.summary = ""
+	p.covered = 0
+	p.records = nil
+}
+
+// Fit 实现ContextPolicy接口
+func (p *SummarizePolicy) Fit(ctx context.Context, history []types.ChatCompletionMessage, budget int, counter TokenCounter) ([]types.ChatCompletionMessage, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	// 历史被清空或替换后重新开始
+	if p.covered > len(history) {
+		p.summary, p.covered, p.records = "", 0, nil
+	}
+
+	threshold := p.threshold
+	if threshold <= 0 {
+		threshold = budget
+	}
+	recent := history[p.covered:]
+	if threshold > 0 && p.tokens(counter, recent) > threshold {
+		keepRecent := p.keepRecent
+		if keepRecent <= 0 {
+			keepRecent = threshold / 2
+		}
+		if split := splitForSummary(counter, recent, keepRecent); split > 0 {
+			summary, err := p.summarize(ctx, recent[:split])
+			if err != nil {
+				return nil, fmt.Errorf("conversation: summarize history: %w", err)
+			}
+			p.records = append(p.records, SummaryRecord{
+				From:      p.covered,
+				To:        p.covered + split,
+				Summary:   summary,
+				CreatedAt: time.Now(),
+			})
+			p.summary = summary
+			p.covered += split
+			recent = recent[split:]
+		}
+	}
+
+	var fitted []types.ChatCompletionMessage
+	if p.summary != "" {
+		message := summaryMessage(p.summary)
+		fitted = append(fitted, message)
+		if budget > 0 {
+			budget -= counter.CountTokens(message)
+			if budget <= 0 {
+				return fitted, ErrContextOverflow
+			}
+		}
+	}
+	// 摘要后仍然超出预算时退回到丢弃最早的轮次
+	rest, err := DropOldestPolicy{}.Fit(ctx, recent, budget, counter)
+	return append(fitted, rest...), err
+}
+
+// tokens 计算摘要消息和消息列表的总token数
+func (p *SummarizePolicy) tokens(counter TokenCounter, messages []types.ChatCompletionMessage) int {
+	total := countMessages(counter, messages)
+	if p.summary != "" {
+		total += counter.CountTokens(summaryMessage(p.summary))
+	}
+	return total
+}
+
+// summarize 把上一次摘要和新的消息合并成新的摘要
+func (p *SummarizePolicy) summarize(ctx context.Context, messages []types.ChatCompletionMessage) (string, error) {
+	var transcript strings.Builder
+	if p.summary != "" {
+		transcript.WriteString("Previous summary:\n")
+		transcript.WriteString(p.summary)
+		transcript.WriteString("\n\n")
+	}
+	transcript.WriteString("New messages:\n")
+	for _, message := range messages {
+		writeTranscript(&transcript, message)
+	}
+
+	req := &types.ChatCompletionRequest{
+		Model: p.model,
+		Messages: []types.ChatCompletionMessage{
+			types.NewTextMessage(types.RoleSystem, p.prompt),
+			types.NewTextMessage(types.RoleUser, transcript.String()),
+		},
+	}
+	if p.maxTokens > 0 {
+		req.MaxTokens = types.ToPtr(p.maxTokens)
+	}
+
+	resp, err := p.client.CreateChatCompletion(ctx, req)
+	if err != nil {
+		return "", err
+	}
+	if len(resp.Choices) == 0 || resp.Choices[0].Message == nil {
+		return "", errors.New("empty summary response")
+	}
+	summary := strings.TrimSpace(resp.Choices[0].Message.GetContentAsString())
+	if summary == "" {
+		return "", errors.New("empty summary response")
+	}
+	return summary, nil
+}
+
+// splitForSummary 返回需要摘要的消息数量，分割点总在用户消息处，保证工具调用组不被拆开
+// 返回0表示没有可以摘要的旧轮次
+func splitForSummary(counter TokenCounter, messages []types.ChatCompletionMessage, keepRecent int) int {
+	split := 0
+	kept := 0
+	for i := len(messages) - 1; i > 0; i-- {
+		kept += counter.CountTokens(messages[i])
+		if messages[i].Role != types.RoleUser {
+			continue
+		}
+		if split != 0 && kept > keepRecent {
+			break
+		}
+		split = i
+	}
+	return split
+}
+
+// summaryMessage 构造摘要系统消息
+func summaryMessage(summary string) types.ChatCompletionMessage {
+	return types.NewTextMessage(types.RoleSystem, summaryPrefix+summary)
+}
+
+// writeTranscript 把消息写成摘要用的纯文本
+func writeTranscript(b *strings.Builder, message types.ChatCompletionMessage) {
+	content := message.GetContentAsString()
+	if images := len(message.GetImageContents()); images > 0 {
+		content += fmt.Sprintf(" [%d image(s)]", images)
+	}
+
+	switch {
+	case message.Role == types.RoleTool:
+		fmt.Fprintf(b, "tool result (%s): %s\n", message.ToolCallID, content)
+	case len(message.ToolCalls) > 0:
+		if content != "" {
+			fmt.Fprintf(b, "%s: %s\n", message.Role, content)
+		}
+		for _, toolCall := range message.ToolCalls {
+			fmt.Fprintf(b, "%s called %s(%v) (%s)\n", message.Role, toolCall.Function.Name, toolCall.Function.Arguments, toolCall.ID)
+		}
+	default:
+		fmt.Fprintf(b, "%s: %s\n", message.Role, content)
+	}
+}
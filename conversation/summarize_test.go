@@ -0,0 +1,157 @@
+package conversation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/yu1ec/go-anyllm/types"
+)
+
+// fakeSummarizer 记录收到的摘要请求，并返回固定格式的摘要
+type fakeSummarizer struct {
+	requests []*types.ChatCompletionRequest
+	err      error
+}
+
+func (f *fakeSummarizer) CreateChatCompletion(ctx context.Context, req *types.ChatCompletionRequest) (*types.ChatCompletionResponse, error) {
+	f.requests = append(f.requests, req)
+	if f.err != nil {
+		return nil, f.err
+	}
+	summary := fmt.Sprintf("摘要%d", len(f.requests))
+	return &types.ChatCompletionResponse{Choices: []types.ChatCompletionChoice{
+		{Message: &types.ChatCompletionMessage{Role: types.RoleAssistant, Content: summary}},
+	}}, nil
+}
+
+func TestSummarizePolicy(t *testing.T) {
+	summarizer := &fakeSummarizer{}
+	policy := NewSummarizePolicy(summarizer, "cheap-model",
+		WithSummaryPrompt("请总结对话"),
+		WithSummaryThreshold(6),
+		WithSummaryKeepRecent(2),
+		WithSummaryMaxTokens(200),
+	)
+	conv := New(WithSystemPrompt("system"), WithTokenCounter(oneTokenCounter), WithPolicy(policy))
+
+	conv.AddUser("问题1")
+	conv.AddMessage(toolCallMessage("call_1"))
+	conv.AddToolResult("call_1", "晴")
+	conv.AddAssistant("回答1")
+
+	// 未超过阈值时不摘要
+	messages, err := conv.ContextMessages(context.Background())
+	if err != nil || len(summarizer.requests) != 0 || len(messages) != 5 {
+		t.Fatalf("未超过阈值时不应摘要: %s %v", roles(messages), err)
+	}
+
+	conv.AddUser("问题2")
+	conv.AddAssistant("回答2")
+	conv.AddUser("问题3")
+	conv.AddAssistant("回答3")
+
+	messages, err = conv.ContextMessages(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := roles(messages); got != "system,system,user,assistant" {
+		t.Errorf("消息顺序错误: %s", got)
+	}
+	if content := messages[1].Content.(string); !strings.HasSuffix(content, "摘要1") || messages[2].Content != "问题3" {
+		t.Errorf("摘要消息错误: %q", content)
+	}
+
+	req := summarizer.requests[0]
+	transcript := req.Messages[1].Content.(string)
+	if req.Model != "cheap-model" || req.Messages[0].Content != "请总结对话" || *req.MaxTokens != 200 {
+		t.Errorf("摘要请求错误: %+v", req)
+	}
+	if !strings.Contains(transcript, "called get_weather") || !strings.Contains(transcript, "tool result (call_1): 晴") || strings.Contains(transcript, "问题3") {
+		t.Errorf("摘要内容错误: %s", transcript)
+	}
+
+	records := policy.Summaries()
+	if len(records) != 1 || records[0].From != 0 || records[0].To != 6 {
+		t.Errorf("摘要区间错误: %+v", records)
+	}
+
+	// 再次超过阈值时，把上一次摘要合并进新的摘要
+	for _, text := range []string{"4", "5", "6"} {
+		conv.AddUser("问题" + text)
+		conv.AddAssistant("回答" + text)
+	}
+	if _, err := conv.ContextMessages(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	records = policy.Summaries()
+	if len(records) != 2 || records[1].From != 6 || records[1].To != 12 || policy.Summary() != "摘要2" {
+		t.Errorf("滚动摘要错误: %+v", records)
+	}
+	if !strings.Contains(summarizer.requests[1].Messages[1].Content.(string), "Previous summary:\n摘要1") {
+		t.Error("新的摘要请求应包含上一次摘要")
+	}
+
+	// 清空历史后摘要状态重置
+	conv.Clear()
+	conv.AddUser("新话题")
+	messages, _ = conv.ContextMessages(context.Background())
+	if got := roles(messages); got != "system,user" || policy.Summary() != "" {
+		t.Errorf("清空后不应保留摘要: %s", got)
+	}
+}
+
+func TestSummarizePolicyClearAndRegrow(t *testing.T) {
+	summarizer := &fakeSummarizer{}
+	policy := NewSummarizePolicy(summarizer, "cheap-model", WithSummaryThreshold(6), WithSummaryKeepRecent(2))
+	conv := New(WithTokenCounter(oneTokenCounter), WithPolicy(policy))
+
+	addTurns := func(prefix string, n int) {
+		for i := 1; i <= n; i++ {
+			conv.AddUser(fmt.Sprintf("%s问题%d", prefix, i))
+			conv.AddAssistant(fmt.Sprintf("%s回答%d", prefix, i))
+		}
+	}
+	addTurns("旧", 4)
+	if _, err := conv.ContextMessages(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if policy.Summary() != "摘要1" {
+		t.Fatalf("期望生成摘要，得到 %q", policy.Summary())
+	}
+
+	// 清空后历史重新增长到超过原摘要覆盖的长度，不应复用旧摘要
+	conv.Clear()
+	addTurns("新", 4)
+	messages, err := conv.ContextMessages(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, message := range messages {
+		if content, _ := message.Content.(string); strings.Contains(content, "摘要1") {
+			t.Errorf("清空后不应复用旧摘要: %q", content)
+		}
+	}
+	records := policy.Summaries()
+	if len(records) != 1 || records[0].From != 0 {
+		t.Errorf("清空后应重新开始摘要: %+v", records)
+	}
+	if transcript := summarizer.requests[1].Messages[1].Content.(string); strings.Contains(transcript, "旧") {
+		t.Errorf("新的摘要不应包含清空前的内容: %s", transcript)
+	}
+}
+
+func TestSummarizePolicyError(t *testing.T) {
+	summarizer := &fakeSummarizer{err: errors.New("rate limited")}
+	conv := New(WithTokenCounter(oneTokenCounter), WithContextWindow(3), WithPolicy(NewSummarizePolicy(summarizer, "cheap-model")))
+	for _, text := range []string{"1", "2", "3"} {
+		conv.AddUser("问题" + text)
+		conv.AddAssistant("回答" + text)
+	}
+
+	if _, err := conv.ContextMessages(context.Background()); err == nil || !strings.Contains(err.Error(), "rate limited") {
+		t.Errorf("摘要失败时应返回错误，得到 %v", err)
+	}
+}
@@ -0,0 +1,50 @@
+package conversation
+
+import (
+	"github.com/yu1ec/go-anyllm/tokenizer"
+	"github.com/yu1ec/go-anyllm/types"
+)
+
+// TokenCounter 计算单条消息占用的token数
+type TokenCounter interface {
+	CountTokens(message types.ChatCompletionMessage) int
+}
+
+// TokenCounterFunc 函数形式的TokenCounter
+type TokenCounterFunc func(message types.ChatCompletionMessage) int
+
+// CountTokens 实现TokenCounter接口
+func (f TokenCounterFunc) CountTokens(message types.ChatCompletionMessage) int {
+	return f(message)
+}
+
+// EstimateTokenCounter 默认的token估算器，不依赖具体模型的分词器
+var EstimateTokenCounter TokenCounter = TokenCounterFunc(EstimateMessageTokens)
+
+// TokenizerCounter 使用分词器按 tokenizer.CountMessage 计算消息的token数
+// 例如 TokenizerCounter(tokenizer.For("openai", "gpt-4o"))
+func TokenizerCounter(t tokenizer.Tokenizer) TokenCounter {
+	return TokenCounterFunc(func(message types.ChatCompletionMessage) int {
+		return tokenizer.CountMessage(t, message)
+	})
+}
+
+// EstimateMessageTokens 估算消息的token数，包括文本、图像、工具调用和推理内容
+// 等同于使用 tokenizer.DefaultEstimator 的 tokenizer.CountMessage
+func EstimateMessageTokens(message types.ChatCompletionMessage) int {
+	return tokenizer.CountMessage(tokenizer.DefaultEstimator, message)
+}
+
+// EstimateTokens 粗略估算文本的token数，等同于 tokenizer.DefaultEstimator.Count
+func EstimateTokens(text string) int {
+	return tokenizer.DefaultEstimator.Count(text)
+}
+
+// countMessages 计算消息列表的总token数
+func countMessages(counter TokenCounter, messages []types.ChatCompletionMessage) int {
+	total := 0
+	for _, message := range messages {
+		total += counter.CountTokens(message)
+	}
+	return total
+}
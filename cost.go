@@ -0,0 +1,114 @@
+package deepseek
+
+import (
+	"context"
+	"time"
+
+	"github.com/yu1ec/go-anyllm/pricing"
+	"github.com/yu1ec/go-anyllm/response"
+	"github.com/yu1ec/go-anyllm/types"
+)
+
+// recordCost 按价格表把一次请求的费用累加到context或客户端的费用标签
+func (c *unifiedClient) recordCost(ctx context.Context, model string, usage *types.Usage, created int64) {
+	if c.costTracker == nil || usage == nil {
+		return
+	}
+	tag, ok := pricing.TagFromContext(ctx)
+	if !ok {
+		tag = c.costTag
+	}
+	at := time.Now()
+	if created > 0 {
+		at = time.Unix(created, 0)
+	}
+	cost, _ := c.pricing.Cost(c.provider.GetName(), model, usage, at)
+	c.costTracker.Add(tag, cost)
+}
+
+// costStreamReader 在流结束时按最后一个带用量的数据块累计费用
+// 流没有读到结尾时不会累计
+type costStreamReader struct {
+	response.StreamReader
+	record func(model string, usage *types.Usage, created int64)
+
+	// hideUsageChunk 为true时不返回只带用量、choices为空的数据块
+	// 客户端为统计费用自动请求 include_usage 时设置，调用方不会因此读到空的 Choices
+	hideUsageChunk bool
+
+	model    string
+	usage    *types.Usage
+	created  int64
+	finished bool
+}
+
+// Next 实现StreamReader接口
+func (r *costStreamReader) Next() bool {
+	for r.StreamReader.Next() {
+		chunk := r.Current()
+		r.observe(chunk)
+		if !r.hidden(chunk) {
+			return true
+		}
+	}
+	r.finish()
+	return false
+}
+
+// Read 实现StreamReader接口
+func (r *costStreamReader) Read() (*response.ChatCompletionsResponse, error) {
+	for {
+		chunk, err := r.StreamReader.Read()
+		if err != nil {
+			r.finish()
+			return chunk, err
+		}
+		r.observe(chunk)
+		if !r.hidden(chunk) {
+			return chunk, nil
+		}
+	}
+}
+
+// hidden 数据块是否是不返回给调用方的用量数据块
+func (r *costStreamReader) hidden(chunk *response.ChatCompletionsResponse) bool {
+	return r.hideUsageChunk && chunk != nil && chunk.Usage != nil && len(chunk.Choices) == 0
+}
+
+// observe 记录数据块中的模型名称和用量
+func (r *costStreamReader) observe(chunk *response.ChatCompletionsResponse) {
+	if chunk == nil {
+		return
+	}
+	if chunk.Model != "" {
+		r.model = chunk.Model
+	}
+	if chunk.Created > 0 {
+		r.created = int64(chunk.Created)
+	}
+	if chunk.Usage != nil {
+		r.usage = convertStreamUsage(chunk.Usage)
+	}
+}
+
+// finish 流结束时累计一次费用
+func (r *costStreamReader) finish() {
+	if r.finished {
+		return
+	}
+	r.finished = true
+	r.record(r.model, r.usage, r.created)
+}
+
+// convertStreamUsage 把流式响应的用量转换为 types.Usage
+func convertStreamUsage(usage *response.Usage) *types.Usage {
+	return &types.Usage{
+		PromptTokens:            usage.PromptTokens,
+		CompletionTokens:        usage.CompletionTokens,
+		TotalTokens:             usage.TotalTokens,
+		PromptCacheHitTokens:    usage.PromptCacheHitTokens,
+		PromptCacheMissTokens:   usage.PromptCacheMissTokens,
+		PromptTokensDetails:     &types.PromptTokensDetails{CachedTokens: usage.PromptTokensDetails.CachedTokens},
+		CompletionTokensDetails: &types.CompletionTokensDetails{ReasoningTokens: usage.CompletionTokensDetails.ReasoningTokens},
+	}
+}
@@ -4,7 +4,6 @@ import (
 	"context"
 	"encoding/base64"
 	"fmt"
-	"io"
 	"log"
 	"os"
 
@@ -100,11 +99,17 @@ func multiModalExample(client deepseek.UnifiedClient) {
 
 // 使用辅助函数的多模态示例
 func helperFunctionExample(client deepseek.UnifiedClient) {
-	base64Image := getExampleBase64Image()
+	// 从图像数据创建内容，自动识别格式，超出限制时缩小并重新编码
+	// 本地文件可以直接使用 types.NewImageContentFromFile("path/to/image.png")
+	image, err := types.NewImageContentFromBytes(getExampleImage())
+	if err != nil {
+		log.Printf("处理图像失败: %v", err)
+		return
+	}
 
 	// 使用辅助函数创建多模态内容
 	contents := []types.MessageContent{
-		types.NewImageContent(fmt.Sprintf("data:image/png;base64,%s", base64Image), types.ImageDetailAuto),
+		image,
 		types.NewTextContent("请分析这张图片的内容，并详细描述你看到了什么。"),
 	}
 
@@ -143,11 +148,16 @@ func helperFunctionExample(client deepseek.UnifiedClient) {
 }
 
 // 获取示例base64图像数据
-// 这里使用一个1x1像素的PNG图片作为示例
 func getExampleBase64Image() string {
-	// 1x1像素的透明PNG图片的base64编码
+	return base64.StdEncoding.EncodeToString(getExampleImage())
+}
+
+// 获取示例图像数据
+// 这里使用一个1x1像素的PNG图片作为示例
+func getExampleImage() []byte {
+	// 1x1像素的透明PNG图片
 	// 实际使用时，您应该提供真实的图像数据
-	pngData := []byte{
+	return []byte{
 		0x89, 0x50, 0x4E, 0x47, 0x0D, 0x0A, 0x1A, 0x0A, 0x00, 0x00, 0x00, 0x0D,
 		0x49, 0x48, 0x44, 0x52, 0x00, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00, 0x01,
 		0x08, 0x06, 0x00, 0x00, 0x00, 0x1F, 0x15, 0xC4, 0x89, 0x00, 0x00, 0x00,
@@ -155,21 +165,4 @@ func getExampleBase64Image() string {
 		0x05, 0x00, 0x01, 0x0D, 0x0A, 0x2D, 0xB4, 0x00, 0x00, 0x00, 0x00, 0x49,
 		0x45, 0x4E, 0x44, 0xAE, 0x42, 0x60, 0x82,
 	}
-	return base64.StdEncoding.EncodeToString(pngData)
-}
-
-// 从文件读取图像并转换为base64的辅助函数
-func imageToBase64(imagePath string) (string, error) {
-	imageFile, err := os.Open(imagePath)
-	if err != nil {
-		return "", err
-	}
-	defer imageFile.Close()
-
-	imageData, err := io.ReadAll(imageFile)
-	if err != nil {
-		return "", err
-	}
-
-	return base64.StdEncoding.EncodeToString(imageData), nil
 }
@@ -538,7 +538,7 @@ func main() {
 			fmt.Printf("\n[DEBUG] 收到工具调用Delta，数量: %d\n", len(choice.Delta.ToolCalls))
 
 			// 使用累积器处理Delta
-			accumulator.ProcessDelta(choice.Delta.ToolCalls)
+			accumulator.ProcessChoiceDelta(choice.Index, choice.Delta.ToolCalls)
 
 			// 检查是否有完成的工具调用
 			completed := accumulator.GetCompletedToolCalls()
@@ -13,21 +13,14 @@ import (
 	"github.com/yu1ec/go-anyllm/types"
 )
 
-// WeatherHandler 天气查询处理器
-type WeatherHandler struct{}
-
-func (h *WeatherHandler) HandleToolCall(toolCall types.ToolCall) (string, error) {
-	// 解析参数
-	type WeatherParams struct {
-		Location string `json:"location"`
-		Unit     string `json:"unit"`
-	}
-
-	params, err := tools.ParseToolCallArguments[WeatherParams](toolCall)
-	if err != nil {
-		return "", fmt.Errorf("解析参数失败: %w", err)
-	}
+// WeatherArgs 天气查询参数
+type WeatherArgs struct {
+	Location string `json:"location" description:"城市和州，例如：北京, 中国"`
+	Unit     string `json:"unit,omitempty" description:"温度单位" enum:"celsius,fahrenheit"`
+}
 
+// getWeather 天气查询
+func getWeather(ctx context.Context, params WeatherArgs) (string, error) {
 	// 模拟天气查询
 	temperature := 25
 	if params.Unit == "fahrenheit" {
@@ -42,27 +35,26 @@ func (h *WeatherHandler) HandleToolCall(toolCall types.ToolCall) (string, error)
 	return fmt.Sprintf("%s 当前天气：晴天，温度 %d%s，湿度 60%%", params.Location, temperature, unit), nil
 }
 
-// CalculatorHandler 计算器处理器
-type CalculatorHandler struct{}
-
-func (h *CalculatorHandler) HandleToolCall(toolCall types.ToolCall) (string, error) {
-	// 解析参数
-	type CalculatorParams struct {
-		Expression string `json:"expression"`
-	}
+// CalculatorArgs 计算器参数
+type CalculatorArgs struct {
+	Expression string `json:"expression" description:"要计算的数学表达式，例如：2+3*4"`
+}
 
-	params, err := tools.ParseToolCallArguments[CalculatorParams](toolCall)
-	if err != nil {
-		return "", fmt.Errorf("解析参数失败: %w", err)
-	}
+// CalculatorResult 计算结果
+type CalculatorResult struct {
+	Expression string  `json:"expression"`
+	Result     float64 `json:"result"`
+}
 
+// calculate 计算器
+func calculate(ctx context.Context, params CalculatorArgs) (CalculatorResult, error) {
 	// 简单的计算器实现（仅支持基本运算）
 	result, err := evaluateExpression(params.Expression)
 	if err != nil {
-		return "", fmt.Errorf("计算失败: %w", err)
+		return CalculatorResult{}, fmt.Errorf("计算失败: %w", err)
 	}
 
-	return fmt.Sprintf("计算结果：%s = %g", params.Expression, result), nil
+	return CalculatorResult{Expression: params.Expression, Result: result}, nil
 }
 
 // 简单的表达式计算器（仅支持 +, -, *, /）
@@ -123,13 +115,13 @@ func main() {
 	// 2. 创建工具注册表
 	registry := tools.NewFunctionRegistry()
 
-	// 3. 注册工具处理器
-	registry.Register("get_weather", &WeatherHandler{})
-	registry.Register("calculator", &CalculatorHandler{})
-
-	// 4. 定义工具
-	weatherTool := tools.GetWeatherTool()
-	calculatorTool := tools.CalculatorTool()
+	// 3. 注册类型化工具函数（自动生成工具定义，自动解析和校验参数）
+	if _, err := tools.RegisterFunc(registry, "get_weather", "获取指定地点的天气信息", getWeather); err != nil {
+		log.Fatal("注册工具失败:", err)
+	}
+	if _, err := tools.RegisterFunc(registry, "calculator", "执行数学计算", calculate); err != nil {
+		log.Fatal("注册工具失败:", err)
+	}
 
 	// 5. 创建带工具的聊天请求
 	req := &types.ChatCompletionRequest{
@@ -144,7 +136,7 @@ func main() {
 				Content: "请查询北京的天气，然后计算 15 + 27 的结果。",
 			},
 		},
-		Tools:      []types.Tool{weatherTool, calculatorTool},
+		Tools:      registry.Tools(),    // 4. 使用注册表中的工具定义
 		ToolChoice: tools.Choice.Auto(), // 自动选择合适的工具
 		Stream:     false,
 	}
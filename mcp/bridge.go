@@ -0,0 +1,288 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/yu1ec/go-anyllm/tools"
+	"github.com/yu1ec/go-anyllm/types"
+)
+
+// Bridge 将MCP服务器的工具注册到 tools.FunctionRegistry，
+// 模型发起的工具调用会被转发到MCP服务器执行
+type Bridge struct {
+	client   *Client
+	registry *tools.FunctionRegistry
+
+	prefix       string
+	filter       func(Tool) bool
+	resourceTool string
+	onChange     func([]types.Tool)
+	syncTimeout  time.Duration
+
+	mutex      sync.Mutex
+	registered map[string]types.Tool // 注册名 -> 工具定义
+	order      []string
+}
+
+// BridgeOption 桥接选项
+type BridgeOption func(*Bridge)
+
+// WithToolPrefix 为注册的工具名添加前缀，避免多个服务器的工具重名
+func WithToolPrefix(prefix string) BridgeOption {
+	return func(b *Bridge) {
+		b.prefix = prefix
+	}
+}
+
+// WithToolFilter 只桥接filter返回true的工具
+func WithToolFilter(filter func(Tool) bool) BridgeOption {
+	return func(b *Bridge) {
+		b.filter = filter
+	}
+}
+
+// WithResourceTool 额外注册一个按URI读取服务器资源的工具
+func WithResourceTool(name string) BridgeOption {
+	return func(b *Bridge) {
+		b.resourceTool = name
+	}
+}
+
+// WithToolsChangedCallback 服务器工具列表变化并重新同步后回调
+func WithToolsChangedCallback(fn func([]types.Tool)) BridgeOption {
+	return func(b *Bridge) {
+		b.onChange = fn
+	}
+}
+
+// NewBridge 创建桥接，需要调用Sync完成首次注册
+func NewBridge(client *Client, registry *tools.FunctionRegistry, opts ...BridgeOption) *Bridge {
+	b := &Bridge{
+		client:      client,
+		registry:    registry,
+		syncTimeout: 30 * time.Second,
+		registered:  make(map[string]types.Tool),
+	}
+	for _, opt := range opts {
+		opt(b)
+	}
+
+	client.OnNotification(NotificationToolsListChanged, func(json.RawMessage) {
+		// 通知在读取循环中回调，需要异步发起请求
+		go func() {
+			ctx, cancel := context.WithTimeout(context.Background(), b.syncTimeout)
+			defer cancel()
+			if synced, err := b.Sync(ctx); err == nil && b.onChange != nil {
+				b.onChange(synced)
+			}
+		}()
+	})
+	return b
+}
+
+// Sync 重新获取服务器工具列表，注册新增工具并移除已下线的工具
+func (b *Bridge) Sync(ctx context.Context) ([]types.Tool, error) {
+	remoteTools, err := b.client.ListTools(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	current := make(map[string]types.Tool)
+	var order []string
+	for _, remote := range remoteTools {
+		if b.filter != nil && !b.filter(remote) {
+			continue
+		}
+		name := b.prefix + remote.Name
+		tool := ToTypesTool(remote)
+		tool.Function.Name = name
+
+		b.registry.RegisterTool(tool, &toolProxy{client: b.client, name: remote.Name})
+		current[name] = tool
+		order = append(order, name)
+	}
+
+	if b.resourceTool != "" {
+		tool := resourceToolDefinition(b.resourceTool)
+		b.registry.RegisterTool(tool, &resourceProxy{client: b.client})
+		current[b.resourceTool] = tool
+		order = append(order, b.resourceTool)
+	}
+
+	for name := range b.registered {
+		if _, exists := current[name]; !exists {
+			b.registry.Unregister(name)
+		}
+	}
+	b.registered = current
+	b.order = order
+
+	return b.toolsLocked(), nil
+}
+
+// Tools 返回当前桥接的工具定义
+func (b *Bridge) Tools() []types.Tool {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.toolsLocked()
+}
+
+func (b *Bridge) toolsLocked() []types.Tool {
+	result := make([]types.Tool, 0, len(b.order))
+	for _, name := range b.order {
+		result = append(result, b.registered[name])
+	}
+	return result
+}
+
+// ToTypesTool 将MCP工具定义转换为请求中使用的工具定义
+func ToTypesTool(tool Tool) types.Tool {
+	var parameters interface{} = map[string]interface{}{
+		"type":       "object",
+		"properties": map[string]interface{}{},
+	}
+	if len(tool.InputSchema) > 0 && string(tool.InputSchema) != "null" {
+		parameters = tool.InputSchema
+	}
+
+	description := tool.Description
+	if description == "" && tool.Annotations != nil {
+		description = tool.Annotations.Title
+	}
+
+	return types.Tool{
+		Type: "function",
+		Function: types.RequestToolFunction{
+			Name:        tool.Name,
+			Description: description,
+			Parameters:  parameters,
+		},
+	}
+}
+
+// ResultText 将工具结果的内容拼接为文本，非文本内容以占位说明代替
+func ResultText(result *CallToolResult) string {
+	parts := make([]string, 0, len(result.Content))
+	for _, content := range result.Content {
+		switch content.Type {
+		case ContentTypeText:
+			parts = append(parts, content.Text)
+		case ContentTypeResource:
+			if content.Resource != nil {
+				parts = append(parts, resourceText(*content.Resource))
+			}
+		default:
+			parts = append(parts, fmt.Sprintf("[%s content: %s]", content.Type, content.MimeType))
+		}
+	}
+	if len(parts) == 0 && len(result.StructuredContent) > 0 {
+		return string(result.StructuredContent)
+	}
+	return strings.Join(parts, "\n")
+}
+
+// resourceText 返回资源的文本内容，二进制资源以占位说明代替
+func resourceText(contents ResourceContents) string {
+	if contents.Blob != "" && contents.Text == "" {
+		return fmt.Sprintf("[binary resource %s: %s]", contents.URI, contents.MimeType)
+	}
+	return contents.Text
+}
+
+// toolProxy 将工具调用转发到MCP服务器
+type toolProxy struct {
+	client *Client
+	name   string
+}
+
+// HandleToolCall 实现ToolCallHandler接口
+func (p *toolProxy) HandleToolCall(toolCall types.ToolCall) (string, error) {
+	return p.HandleToolCallContext(context.Background(), toolCall)
+}
+
+// HandleToolCallContext 实现ContextToolCallHandler接口
+func (p *toolProxy) HandleToolCallContext(ctx context.Context, toolCall types.ToolCall) (string, error) {
+	arguments, err := toolCallArguments(toolCall)
+	if err != nil {
+		return "", err
+	}
+
+	result, err := p.client.CallTool(ctx, p.name, arguments)
+	if err != nil {
+		return "", err
+	}
+
+	text := ResultText(result)
+	if result.IsError {
+		return "", errors.New(text)
+	}
+	return text, nil
+}
+
+// toolCallArguments 将工具调用参数转换为JSON对象
+func toolCallArguments(toolCall types.ToolCall) (json.RawMessage, error) {
+	switch args := toolCall.Function.Arguments.(type) {
+	case nil:
+		return json.RawMessage("{}"), nil
+	case string:
+		if strings.TrimSpace(args) == "" {
+			return json.RawMessage("{}"), nil
+		}
+		return json.RawMessage(args), nil
+	case json.RawMessage:
+		return args, nil
+	default:
+		data, err := json.Marshal(args)
+		if err != nil {
+			return nil, fmt.Errorf("mcp: failed to marshal arguments: %w", err)
+		}
+		return data, nil
+	}
+}
+
+// resourceToolDefinition 读取资源工具的定义
+func resourceToolDefinition(name string) types.Tool {
+	return tools.NewTool(name, "Read a resource exposed by the MCP server").
+		AddStringParam("uri", "The URI of the resource to read", true).
+		BuildForTypes()
+}
+
+// resourceProxy 读取MCP服务器资源
+type resourceProxy struct {
+	client *Client
+}
+
+// HandleToolCall 实现ToolCallHandler接口
+func (p *resourceProxy) HandleToolCall(toolCall types.ToolCall) (string, error) {
+	return p.HandleToolCallContext(context.Background(), toolCall)
+}
+
+// HandleToolCallContext 实现ContextToolCallHandler接口
+func (p *resourceProxy) HandleToolCallContext(ctx context.Context, toolCall types.ToolCall) (string, error) {
+	args, err := tools.ParseToolCallArguments[struct {
+		URI string `json:"uri"`
+	}](toolCall)
+	if err != nil {
+		return "", err
+	}
+
+	result, err := p.client.ReadResource(ctx, args.URI)
+	if err != nil {
+		return "", err
+	}
+
+	parts := make([]string, 0, len(result.Contents))
+	for _, contents := range result.Contents {
+		parts = append(parts, resourceText(contents))
+	}
+	return strings.Join(parts, "\n"), nil
+}
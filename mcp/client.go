@@ -0,0 +1,296 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"sync"
+	"sync/atomic"
+)
+
+// NotificationHandler 服务器通知处理函数
+type NotificationHandler func(params json.RawMessage)
+
+// ProgressHandler 工具调用进度处理函数
+type ProgressHandler func(progress ProgressParams)
+
+// Client MCP客户端
+type Client struct {
+	transport  Transport
+	clientInfo Implementation
+
+	nextID   int64
+	mutex    sync.Mutex
+	pending  map[string]chan *Message
+	progress map[string]ProgressHandler
+	handlers map[string][]NotificationHandler
+
+	initResult *InitializeResult
+}
+
+// ClientOption 客户端选项
+type ClientOption func(*Client)
+
+// WithClientInfo 设置上报给服务器的客户端信息
+func WithClientInfo(name, version string) ClientOption {
+	return func(c *Client) {
+		c.clientInfo = Implementation{Name: name, Version: version}
+	}
+}
+
+// NewClient 创建MCP客户端
+func NewClient(transport Transport, opts ...ClientOption) *Client {
+	c := &Client{
+		transport:  transport,
+		clientInfo: Implementation{Name: "go-anyllm", Version: "1.0.0"},
+		pending:    make(map[string]chan *Message),
+		progress:   make(map[string]ProgressHandler),
+		handlers:   make(map[string][]NotificationHandler),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Connect 启动传输并完成初始化握手
+func (c *Client) Connect(ctx context.Context) (*InitializeResult, error) {
+	if err := c.transport.Start(ctx, c.handleMessage); err != nil {
+		return nil, err
+	}
+
+	params := InitializeParams{
+		ProtocolVersion: ProtocolVersion,
+		ClientInfo:      c.clientInfo,
+	}
+	var result InitializeResult
+	if err := c.call(ctx, MethodInitialize, params, &result); err != nil {
+		return nil, fmt.Errorf("mcp: initialize failed: %w", err)
+	}
+
+	if err := c.notify(ctx, NotificationInitialized, nil); err != nil {
+		return nil, err
+	}
+
+	c.mutex.Lock()
+	c.initResult = &result
+	c.mutex.Unlock()
+	return &result, nil
+}
+
+// ServerInfo 返回初始化时服务器上报的信息，未连接时返回nil
+func (c *Client) ServerInfo() *InitializeResult {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.initResult
+}
+
+// OnNotification 注册服务器通知处理函数
+func (c *Client) OnNotification(method string, handler NotificationHandler) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.handlers[method] = append(c.handlers[method], handler)
+}
+
+// Ping 检查服务器是否可用
+func (c *Client) Ping(ctx context.Context) error {
+	return c.call(ctx, MethodPing, struct{}{}, nil)
+}
+
+// ListTools 获取服务器提供的全部工具（自动处理分页）
+func (c *Client) ListTools(ctx context.Context) ([]Tool, error) {
+	var all []Tool
+	cursor := ""
+	for {
+		var result ListToolsResult
+		if err := c.call(ctx, MethodToolsList, PaginatedParams{Cursor: cursor}, &result); err != nil {
+			return nil, err
+		}
+		all = append(all, result.Tools...)
+		if result.NextCursor == "" {
+			return all, nil
+		}
+		cursor = result.NextCursor
+	}
+}
+
+// CallTool 调用工具，arguments为JSON对象（可为nil）
+func (c *Client) CallTool(ctx context.Context, name string, arguments json.RawMessage) (*CallToolResult, error) {
+	return c.CallToolWithProgress(ctx, name, arguments, nil)
+}
+
+// CallToolWithProgress 调用工具并接收服务器的进度通知
+func (c *Client) CallToolWithProgress(ctx context.Context, name string, arguments json.RawMessage, onProgress ProgressHandler) (*CallToolResult, error) {
+	id := atomic.AddInt64(&c.nextID, 1)
+	params := CallToolParams{Name: name, Arguments: arguments}
+	if onProgress != nil {
+		params.Meta = &RequestMeta{ProgressToken: id}
+
+		key := strconv.FormatInt(id, 10)
+		c.mutex.Lock()
+		c.progress[key] = onProgress
+		c.mutex.Unlock()
+		defer func() {
+			c.mutex.Lock()
+			delete(c.progress, key)
+			c.mutex.Unlock()
+		}()
+	}
+
+	var result CallToolResult
+	if err := c.callWithID(ctx, id, MethodToolsCall, params, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ListResources 获取服务器提供的全部资源（自动处理分页）
+func (c *Client) ListResources(ctx context.Context) ([]Resource, error) {
+	var all []Resource
+	cursor := ""
+	for {
+		var result ListResourcesResult
+		if err := c.call(ctx, MethodResourcesList, PaginatedParams{Cursor: cursor}, &result); err != nil {
+			return nil, err
+		}
+		all = append(all, result.Resources...)
+		if result.NextCursor == "" {
+			return all, nil
+		}
+		cursor = result.NextCursor
+	}
+}
+
+// ReadResource 读取资源内容
+func (c *Client) ReadResource(ctx context.Context, uri string) (*ReadResourceResult, error) {
+	var result ReadResourceResult
+	if err := c.call(ctx, MethodResourcesRead, ReadResourceParams{URI: uri}, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// Close 关闭连接
+func (c *Client) Close() error {
+	return c.transport.Close()
+}
+
+// call 发送请求并等待响应
+func (c *Client) call(ctx context.Context, method string, params interface{}, result interface{}) error {
+	return c.callWithID(ctx, atomic.AddInt64(&c.nextID, 1), method, params, result)
+}
+
+// callWithID 使用指定ID发送请求并等待响应
+func (c *Client) callWithID(ctx context.Context, id int64, method string, params interface{}, result interface{}) error {
+	msg, err := newRequest(id, method, params)
+	if err != nil {
+		return err
+	}
+
+	key := string(msg.ID)
+	respChan := make(chan *Message, 1)
+	c.mutex.Lock()
+	c.pending[key] = respChan
+	c.mutex.Unlock()
+	defer func() {
+		c.mutex.Lock()
+		delete(c.pending, key)
+		c.mutex.Unlock()
+	}()
+
+	if err := c.transport.Send(ctx, msg); err != nil {
+		return err
+	}
+
+	select {
+	case resp := <-respChan:
+		if resp.Error != nil {
+			return resp.Error
+		}
+		if result != nil && len(resp.Result) > 0 {
+			if err := json.Unmarshal(resp.Result, result); err != nil {
+				return fmt.Errorf("mcp: failed to decode %s result: %w", method, err)
+			}
+		}
+		return nil
+	case <-ctx.Done():
+		// 通知服务器放弃该请求
+		_ = c.notify(context.Background(), NotificationCancelled, CancelledParams{
+			RequestID: msg.ID,
+			Reason:    ctx.Err().Error(),
+		})
+		return ctx.Err()
+	case <-c.transport.Done():
+		return ErrTransportClosed
+	}
+}
+
+// notify 发送通知
+func (c *Client) notify(ctx context.Context, method string, params interface{}) error {
+	msg, err := newNotification(method, params)
+	if err != nil {
+		return err
+	}
+	return c.transport.Send(ctx, msg)
+}
+
+// handleMessage 分发收到的消息，通知处理函数在读取循环中同步执行，不能在其中发起请求
+func (c *Client) handleMessage(msg *Message) {
+	switch {
+	case msg.IsResponse():
+		c.mutex.Lock()
+		respChan, exists := c.pending[string(msg.ID)]
+		c.mutex.Unlock()
+		if exists {
+			select {
+			case respChan <- msg:
+			default:
+			}
+		}
+
+	case msg.IsNotification():
+		if msg.Method == NotificationProgress {
+			c.handleProgress(msg.Params)
+		}
+		c.mutex.Lock()
+		handlers := append([]NotificationHandler(nil), c.handlers[msg.Method]...)
+		c.mutex.Unlock()
+		for _, handler := range handlers {
+			handler(msg.Params)
+		}
+
+	case msg.IsRequest():
+		go c.handleServerRequest(msg)
+	}
+}
+
+// handleProgress 将进度通知路由到对应的工具调用
+func (c *Client) handleProgress(params json.RawMessage) {
+	var progress ProgressParams
+	if err := json.Unmarshal(params, &progress); err != nil {
+		return
+	}
+
+	token, err := json.Marshal(progress.ProgressToken)
+	if err != nil {
+		return
+	}
+	c.mutex.Lock()
+	handler, exists := c.progress[string(token)]
+	c.mutex.Unlock()
+	if exists {
+		handler(progress)
+	}
+}
+
+// handleServerRequest 响应服务器发起的请求
+func (c *Client) handleServerRequest(msg *Message) {
+	var resp *Message
+	if msg.Method == MethodPing {
+		resp, _ = newResponse(msg.ID, struct{}{})
+	} else {
+		resp = newErrorResponse(msg.ID, ErrorCodeMethodNotFound, "method not found: "+msg.Method)
+	}
+	_ = c.transport.Send(context.Background(), resp)
+}
@@ -0,0 +1,401 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/yu1ec/go-anyllm/tools"
+	"github.com/yu1ec/go-anyllm/types"
+)
+
+// 设置该环境变量时，测试二进制作为stdio MCP服务器运行
+const fakeServerEnv = "GO_ANYLLM_FAKE_MCP_SERVER"
+
+func TestMain(m *testing.M) {
+	if os.Getenv(fakeServerEnv) == "1" {
+		server := newFakeServer()
+		transport := NewStreamTransport(os.Stdin, os.Stdout)
+		transport.Start(context.Background(), func(msg *Message) {
+			server.handle(msg, func(out *Message) {
+				transport.Send(context.Background(), out)
+			})
+		})
+		<-transport.Done()
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+// fakeServer 测试用的最小MCP服务器
+type fakeServer struct {
+	mutex sync.Mutex
+	extra bool
+}
+
+func newFakeServer() *fakeServer {
+	return &fakeServer{}
+}
+
+func (s *fakeServer) tools() []Tool {
+	list := []Tool{
+		{Name: "echo", Description: "Echo text", InputSchema: json.RawMessage(`{"type":"object","properties":{"text":{"type":"string"},"lang":{"type":["string","null"]}},"required":["text"]}`)},
+		{Name: "add", Description: "Add numbers", InputSchema: json.RawMessage(`{"type":"object","properties":{"a":{"type":"number"},"b":{"type":"number"}},"required":["a","b"]}`)},
+		{Name: "fail", Description: "Always fails", InputSchema: json.RawMessage(`{"type":"object"}`)},
+		{Name: "toggle", Description: "Add or remove the extra tool"},
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.extra {
+		list = append(list, Tool{Name: "extra", Description: "Extra tool"})
+	}
+	return list
+}
+
+func (s *fakeServer) handle(msg *Message, send func(*Message)) {
+	if !msg.IsRequest() {
+		return
+	}
+
+	reply := func(result interface{}) {
+		resp, _ := newResponse(msg.ID, result)
+		send(resp)
+	}
+
+	switch msg.Method {
+	case MethodInitialize:
+		reply(InitializeResult{
+			ProtocolVersion: ProtocolVersion,
+			Capabilities: ServerCapabilities{
+				Tools:     &ToolsCapability{ListChanged: true},
+				Resources: &ResourcesCapability{},
+			},
+			ServerInfo: Implementation{Name: "fake", Version: "0.1"},
+		})
+
+	case MethodPing:
+		reply(struct{}{})
+
+	case MethodToolsList:
+		// 每页两个工具，验证客户端分页
+		var params PaginatedParams
+		json.Unmarshal(msg.Params, &params)
+		start, _ := strconv.Atoi(params.Cursor)
+		all := s.tools()
+		end := start + 2
+		result := ListToolsResult{}
+		if end < len(all) {
+			result.NextCursor = strconv.Itoa(end)
+		} else {
+			end = len(all)
+		}
+		result.Tools = all[start:end]
+		reply(result)
+
+	case MethodToolsCall:
+		var params CallToolParams
+		json.Unmarshal(msg.Params, &params)
+		var args map[string]interface{}
+		json.Unmarshal(params.Arguments, &args)
+
+		if params.Meta != nil && params.Meta.ProgressToken != nil {
+			progress, _ := newNotification(NotificationProgress, ProgressParams{
+				ProgressToken: params.Meta.ProgressToken, Progress: 1, Total: 2, Message: "working",
+			})
+			send(progress)
+		}
+
+		switch params.Name {
+		case "echo":
+			reply(CallToolResult{Content: []Content{NewTextContent(fmt.Sprint(args["text"]))}})
+		case "add":
+			a, _ := args["a"].(float64)
+			b, _ := args["b"].(float64)
+			reply(CallToolResult{Content: []Content{NewTextContent(strconv.FormatFloat(a+b, 'f', -1, 64))}})
+		case "fail":
+			reply(CallToolResult{Content: []Content{NewTextContent("something went wrong")}, IsError: true})
+		case "toggle":
+			s.mutex.Lock()
+			s.extra = !s.extra
+			s.mutex.Unlock()
+			reply(CallToolResult{Content: []Content{NewTextContent("ok")}})
+			changed, _ := newNotification(NotificationToolsListChanged, nil)
+			send(changed)
+		default:
+			send(newErrorResponse(msg.ID, ErrorCodeInvalidParams, "unknown tool: "+params.Name))
+		}
+
+	case MethodResourcesList:
+		reply(ListResourcesResult{Resources: []Resource{{URI: "file:///readme.md", Name: "readme", MimeType: "text/markdown"}}})
+
+	case MethodResourcesRead:
+		var params ReadResourceParams
+		json.Unmarshal(msg.Params, &params)
+		if params.URI != "file:///readme.md" {
+			send(newErrorResponse(msg.ID, ErrorCodeInvalidParams, "resource not found"))
+			return
+		}
+		reply(ReadResourceResult{Contents: []ResourceContents{{URI: params.URI, MimeType: "text/markdown", Text: "# Readme"}}})
+
+	default:
+		send(newErrorResponse(msg.ID, ErrorCodeMethodNotFound, "method not found"))
+	}
+}
+
+// connectStdio 启动测试二进制作为MCP服务器并完成连接
+func connectStdio(t *testing.T) *Client {
+	t.Helper()
+
+	cmd := exec.Command(os.Args[0], "-test.run=^$")
+	cmd.Env = append(os.Environ(), fakeServerEnv+"=1")
+
+	client := NewClient(NewCommandTransportFromCmd(cmd))
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	result, err := client.Connect(ctx)
+	if err != nil {
+		t.Fatalf("连接失败: %v", err)
+	}
+	if result.ServerInfo.Name != "fake" || result.Capabilities.Tools == nil {
+		t.Fatalf("初始化结果错误: %+v", result)
+	}
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+func TestClientStdio(t *testing.T) {
+	client := connectStdio(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := client.Ping(ctx); err != nil {
+		t.Fatalf("ping失败: %v", err)
+	}
+
+	list, err := client.ListTools(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 4 || list[0].Name != "echo" || list[3].Name != "toggle" {
+		t.Errorf("分页获取工具列表错误: %+v", list)
+	}
+
+	var progress []ProgressParams
+	result, err := client.CallToolWithProgress(ctx, "add", json.RawMessage(`{"a":1,"b":2.5}`), func(p ProgressParams) {
+		progress = append(progress, p)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ResultText(result) != "3.5" {
+		t.Errorf("期望 3.5，得到 %s", ResultText(result))
+	}
+	if len(progress) != 1 || progress[0].Message != "working" {
+		t.Errorf("进度通知错误: %+v", progress)
+	}
+
+	if _, err := client.CallTool(ctx, "missing", nil); err == nil || !strings.Contains(err.Error(), "unknown tool") {
+		t.Errorf("期望RPC错误，得到 %v", err)
+	}
+
+	resources, err := client.ListResources(ctx)
+	if err != nil || len(resources) != 1 {
+		t.Fatalf("资源列表错误: %v %+v", err, resources)
+	}
+	contents, err := client.ReadResource(ctx, resources[0].URI)
+	if err != nil || contents.Contents[0].Text != "# Readme" {
+		t.Errorf("读取资源错误: %v %+v", err, contents)
+	}
+}
+
+func TestBridgeRegistersRemoteTools(t *testing.T) {
+	client := connectStdio(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	changed := make(chan []types.Tool, 1)
+	registry := tools.NewFunctionRegistry()
+	bridge := NewBridge(client, registry,
+		WithToolPrefix("fake_"),
+		WithResourceTool("fake_read_resource"),
+		WithToolsChangedCallback(func(list []types.Tool) { changed <- list }),
+	)
+
+	bridged, err := bridge.Sync(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(bridged) != 5 || len(registry.Tools()) != 5 {
+		t.Fatalf("期望注册5个工具，得到 %d", len(registry.Tools()))
+	}
+
+	call := func(name, arguments string) *tools.ToolCallResult {
+		return registry.HandleContext(ctx, types.ToolCall{
+			ID:       "call_1",
+			Type:     "function",
+			Function: types.ResponseToolFunction{Name: name, Arguments: arguments},
+		})
+	}
+
+	if result := call("fake_echo", `{"text":"你好"}`); result.Error != "" || result.Content != "你好" {
+		t.Errorf("echo结果错误: %+v", result)
+	}
+	// 远程schema中的类型数组（可空字段）
+	if result := call("fake_echo", `{"text":"hi","lang":null}`); result.Error != "" || result.Content != "hi" {
+		t.Errorf("可空字段为null时应通过校验: %+v", result)
+	}
+	if result := call("fake_echo", `{"text":"hi","lang":1}`); len(result.Violations) == 0 {
+		t.Errorf("可空字段类型错误时应校验失败，得到 %+v", result)
+	}
+	if result := call("fake_fail", `{}`); result.Error != "something went wrong" {
+		t.Errorf("期望工具错误，得到 %+v", result)
+	}
+	// 参数不符合远程schema时在本地拦截
+	if result := call("fake_add", `{"a":"1"}`); len(result.Violations) == 0 {
+		t.Errorf("期望参数校验失败，得到 %+v", result)
+	}
+	if result := call("fake_read_resource", `{"uri":"file:///readme.md"}`); result.Content != "# Readme" {
+		t.Errorf("读取资源结果错误: %+v", result)
+	}
+
+	// 服务器通知工具列表变化后自动重新同步
+	call("fake_toggle", `{}`)
+	select {
+	case list := <-changed:
+		if len(list) != 6 {
+			t.Errorf("同步后期望6个工具，得到 %d", len(list))
+		}
+	case <-ctx.Done():
+		t.Fatal("没有收到工具列表变化")
+	}
+	if _, ok := registry.GetTool("fake_extra"); !ok {
+		t.Error("新工具未注册")
+	}
+
+	call("fake_toggle", `{}`)
+	select {
+	case <-changed:
+	case <-ctx.Done():
+		t.Fatal("没有收到工具列表变化")
+	}
+	if _, ok := registry.GetTool("fake_extra"); ok {
+		t.Error("下线的工具应被移除")
+	}
+	if result := call("fake_extra", `{}`); result.Error == "" {
+		t.Error("移除的工具不应再能调用")
+	}
+}
+
+func TestClientHTTP(t *testing.T) {
+	server := newFakeServer()
+	var deleted bool
+	var mutex sync.Mutex
+
+	httpServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		case http.MethodDelete:
+			mutex.Lock()
+			deleted = r.Header.Get(headerSessionID) == "session-1"
+			mutex.Unlock()
+			return
+		}
+
+		var msg Message
+		if err := json.NewDecoder(r.Body).Decode(&msg); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if msg.Method == MethodInitialize {
+			w.Header().Set(headerSessionID, "session-1")
+		} else if r.Header.Get(headerSessionID) != "session-1" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if !msg.IsRequest() {
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+
+		var out []*Message
+		server.handle(&msg, func(m *Message) { out = append(out, m) })
+
+		// 工具调用以SSE返回（先进度通知再响应），其他请求返回JSON
+		if msg.Method == MethodToolsCall {
+			w.Header().Set("Content-Type", "text/event-stream")
+			var buf bytes.Buffer
+			for _, m := range out {
+				data, _ := json.Marshal(m)
+				fmt.Fprintf(&buf, "event: message\ndata: %s\n\n", data)
+			}
+			w.Write(buf.Bytes())
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(out[0])
+	}))
+	defer httpServer.Close()
+
+	transport := NewHTTPTransport(httpServer.URL)
+	client := NewClient(transport)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if _, err := client.Connect(ctx); err != nil {
+		t.Fatalf("连接失败: %v", err)
+	}
+	if transport.SessionID() != "session-1" {
+		t.Errorf("会话ID错误: %q", transport.SessionID())
+	}
+
+	list, err := client.ListTools(ctx)
+	if err != nil || len(list) != 4 {
+		t.Fatalf("工具列表错误: %v %+v", err, list)
+	}
+
+	var progressCount int
+	result, err := client.CallToolWithProgress(ctx, "echo", json.RawMessage(`{"text":"hi"}`), func(ProgressParams) { progressCount++ })
+	if err != nil || ResultText(result) != "hi" {
+		t.Fatalf("调用工具错误: %v %+v", err, result)
+	}
+	if progressCount != 1 {
+		t.Errorf("期望1个进度通知，得到 %d", progressCount)
+	}
+
+	client.Close()
+	mutex.Lock()
+	defer mutex.Unlock()
+	if !deleted {
+		t.Error("关闭时应发送DELETE结束会话")
+	}
+}
+
+func TestResultText(t *testing.T) {
+	result := &CallToolResult{Content: []Content{
+		NewTextContent("line1"),
+		{Type: ContentTypeImage, Data: "aGk=", MimeType: "image/png"},
+		{Type: ContentTypeResource, Resource: &ResourceContents{URI: "file:///a", Text: "line2"}},
+	}}
+	want := "line1\n[image content: image/png]\nline2"
+	if got := ResultText(result); got != want {
+		t.Errorf("期望 %q，得到 %q", want, got)
+	}
+
+	structured := &CallToolResult{StructuredContent: json.RawMessage(`{"ok":true}`)}
+	if got := ResultText(structured); got != `{"ok":true}` {
+		t.Errorf("期望结构化内容，得到 %q", got)
+	}
+}
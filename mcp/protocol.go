@@ -0,0 +1,303 @@
+// Package mcp 实现 Model Context Protocol (MCP) 的客户端和服务器，
+// 支持 stdio 和 Streamable HTTP 传输：客户端可将远程工具桥接到 tools.FunctionRegistry，
+// 服务器可将 tools.FunctionRegistry 中的工具提供给其他MCP客户端。
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// ProtocolVersion 客户端支持的MCP协议版本
+const ProtocolVersion = "2025-03-26"
+
+// JSON-RPC 版本
+const jsonrpcVersion = "2.0"
+
+// MCP方法名常量
+const (
+	MethodInitialize    = "initialize"
+	MethodPing          = "ping"
+	MethodToolsList     = "tools/list"
+	MethodToolsCall     = "tools/call"
+	MethodResourcesList = "resources/list"
+	MethodResourcesRead = "resources/read"
+
+	NotificationInitialized          = "notifications/initialized"
+	NotificationProgress             = "notifications/progress"
+	NotificationCancelled            = "notifications/cancelled"
+	NotificationToolsListChanged     = "notifications/tools/list_changed"
+	NotificationResourcesListChanged = "notifications/resources/list_changed"
+	NotificationResourceUpdated      = "notifications/resources/updated"
+)
+
+// JSON-RPC 错误码
+const (
+	ErrorCodeParseError     = -32700
+	ErrorCodeInvalidRequest = -32600
+	ErrorCodeMethodNotFound = -32601
+	ErrorCodeInvalidParams  = -32602
+	ErrorCodeInternalError  = -32603
+)
+
+// Message JSON-RPC 2.0 消息（请求、响应或通知）
+type Message struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method,omitempty"`
+	Params  json.RawMessage `json:"params,omitempty"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *RPCError       `json:"error,omitempty"`
+}
+
+// IsRequest 检查消息是否为请求
+func (m *Message) IsRequest() bool {
+	return m.Method != "" && len(m.ID) > 0
+}
+
+// IsNotification 检查消息是否为通知
+func (m *Message) IsNotification() bool {
+	return m.Method != "" && len(m.ID) == 0
+}
+
+// IsResponse 检查消息是否为响应
+func (m *Message) IsResponse() bool {
+	return m.Method == "" && len(m.ID) > 0
+}
+
+// RPCError JSON-RPC 错误
+type RPCError struct {
+	Code    int             `json:"code"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data,omitempty"`
+}
+
+// Error 实现error接口
+func (e *RPCError) Error() string {
+	return fmt.Sprintf("mcp: rpc error %d: %s", e.Code, e.Message)
+}
+
+// Implementation 客户端或服务器的实现信息
+type Implementation struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// ClientCapabilities 客户端能力
+type ClientCapabilities struct {
+	Experimental map[string]interface{} `json:"experimental,omitempty"`
+}
+
+// ServerCapabilities 服务器能力
+type ServerCapabilities struct {
+	Tools     *ToolsCapability     `json:"tools,omitempty"`
+	Resources *ResourcesCapability `json:"resources,omitempty"`
+	Prompts   *PromptsCapability   `json:"prompts,omitempty"`
+	Logging   *struct{}            `json:"logging,omitempty"`
+}
+
+// ToolsCapability 工具能力
+type ToolsCapability struct {
+	ListChanged bool `json:"listChanged,omitempty"`
+}
+
+// ResourcesCapability 资源能力
+type ResourcesCapability struct {
+	Subscribe   bool `json:"subscribe,omitempty"`
+	ListChanged bool `json:"listChanged,omitempty"`
+}
+
+// PromptsCapability 提示词能力
+type PromptsCapability struct {
+	ListChanged bool `json:"listChanged,omitempty"`
+}
+
+// InitializeParams initialize请求参数
+type InitializeParams struct {
+	ProtocolVersion string             `json:"protocolVersion"`
+	Capabilities    ClientCapabilities `json:"capabilities"`
+	ClientInfo      Implementation     `json:"clientInfo"`
+}
+
+// InitializeResult initialize响应
+type InitializeResult struct {
+	ProtocolVersion string             `json:"protocolVersion"`
+	Capabilities    ServerCapabilities `json:"capabilities"`
+	ServerInfo      Implementation     `json:"serverInfo"`
+	Instructions    string             `json:"instructions,omitempty"`
+}
+
+// Tool MCP工具定义
+type Tool struct {
+	Name        string           `json:"name"`
+	Description string           `json:"description,omitempty"`
+	InputSchema json.RawMessage  `json:"inputSchema"`
+	Annotations *ToolAnnotations `json:"annotations,omitempty"`
+}
+
+// ToolAnnotations 工具行为提示
+type ToolAnnotations struct {
+	Title           string `json:"title,omitempty"`
+	ReadOnlyHint    *bool  `json:"readOnlyHint,omitempty"`
+	DestructiveHint *bool  `json:"destructiveHint,omitempty"`
+	IdempotentHint  *bool  `json:"idempotentHint,omitempty"`
+	OpenWorldHint   *bool  `json:"openWorldHint,omitempty"`
+}
+
+// PaginatedParams 分页请求参数
+type PaginatedParams struct {
+	Cursor string `json:"cursor,omitempty"`
+}
+
+// ListToolsResult tools/list响应
+type ListToolsResult struct {
+	Tools      []Tool `json:"tools"`
+	NextCursor string `json:"nextCursor,omitempty"`
+}
+
+// RequestMeta 请求元数据
+type RequestMeta struct {
+	ProgressToken interface{} `json:"progressToken,omitempty"`
+}
+
+// CallToolParams tools/call请求参数
+type CallToolParams struct {
+	Name      string          `json:"name"`
+	Arguments json.RawMessage `json:"arguments,omitempty"`
+	Meta      *RequestMeta    `json:"_meta,omitempty"`
+}
+
+// CallToolResult tools/call响应
+type CallToolResult struct {
+	Content           []Content       `json:"content"`
+	StructuredContent json.RawMessage `json:"structuredContent,omitempty"`
+	IsError           bool            `json:"isError,omitempty"`
+}
+
+// 内容类型常量
+const (
+	ContentTypeText     = "text"
+	ContentTypeImage    = "image"
+	ContentTypeAudio    = "audio"
+	ContentTypeResource = "resource"
+)
+
+// Content 工具结果中的内容项
+type Content struct {
+	Type     string            `json:"type"`
+	Text     string            `json:"text,omitempty"`
+	Data     string            `json:"data,omitempty"` // base64编码的图像或音频
+	MimeType string            `json:"mimeType,omitempty"`
+	Resource *ResourceContents `json:"resource,omitempty"`
+}
+
+// NewTextContent 创建文本内容
+func NewTextContent(text string) Content {
+	return Content{Type: ContentTypeText, Text: text}
+}
+
+// ProgressParams notifications/progress参数
+type ProgressParams struct {
+	ProgressToken interface{} `json:"progressToken"`
+	Progress      float64     `json:"progress"`
+	Total         float64     `json:"total,omitempty"`
+	Message       string      `json:"message,omitempty"`
+}
+
+// CancelledParams notifications/cancelled参数
+type CancelledParams struct {
+	RequestID json.RawMessage `json:"requestId"`
+	Reason    string          `json:"reason,omitempty"`
+}
+
+// Resource MCP资源
+type Resource struct {
+	URI         string `json:"uri"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	MimeType    string `json:"mimeType,omitempty"`
+}
+
+// ListResourcesResult resources/list响应
+type ListResourcesResult struct {
+	Resources  []Resource `json:"resources"`
+	NextCursor string     `json:"nextCursor,omitempty"`
+}
+
+// ReadResourceParams resources/read请求参数
+type ReadResourceParams struct {
+	URI string `json:"uri"`
+}
+
+// ResourceContents 资源内容，Text和Blob二选一
+type ResourceContents struct {
+	URI      string `json:"uri"`
+	MimeType string `json:"mimeType,omitempty"`
+	Text     string `json:"text,omitempty"`
+	Blob     string `json:"blob,omitempty"` // base64编码的二进制内容
+}
+
+// ReadResourceResult resources/read响应
+type ReadResourceResult struct {
+	Contents []ResourceContents `json:"contents"`
+}
+
+// ResourceUpdatedParams notifications/resources/updated参数
+type ResourceUpdatedParams struct {
+	URI string `json:"uri"`
+}
+
+// newRequest 创建请求消息
+func newRequest(id int64, method string, params interface{}) (*Message, error) {
+	msg := &Message{
+		JSONRPC: jsonrpcVersion,
+		ID:      json.RawMessage(fmt.Sprintf("%d", id)),
+		Method:  method,
+	}
+	if params != nil {
+		data, err := json.Marshal(params)
+		if err != nil {
+			return nil, fmt.Errorf("mcp: failed to marshal params: %w", err)
+		}
+		msg.Params = data
+	}
+	return msg, nil
+}
+
+// newNotification 创建通知消息
+func newNotification(method string, params interface{}) (*Message, error) {
+	msg := &Message{
+		JSONRPC: jsonrpcVersion,
+		Method:  method,
+	}
+	if params != nil {
+		data, err := json.Marshal(params)
+		if err != nil {
+			return nil, fmt.Errorf("mcp: failed to marshal params: %w", err)
+		}
+		msg.Params = data
+	}
+	return msg, nil
+}
+
+// newResponse 创建响应消息
+func newResponse(id json.RawMessage, result interface{}) (*Message, error) {
+	data, err := json.Marshal(result)
+	if err != nil {
+		return nil, fmt.Errorf("mcp: failed to marshal result: %w", err)
+	}
+	return &Message{
+		JSONRPC: jsonrpcVersion,
+		ID:      id,
+		Result:  data,
+	}, nil
+}
+
+// newErrorResponse 创建错误响应消息
+func newErrorResponse(id json.RawMessage, code int, message string) *Message {
+	return &Message{
+		JSONRPC: jsonrpcVersion,
+		ID:      id,
+		Error:   &RPCError{Code: code, Message: message},
+	}
+}
@@ -0,0 +1,494 @@
+package mcp
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/yu1ec/go-anyllm/tools"
+	"github.com/yu1ec/go-anyllm/types"
+)
+
+// Server 将 tools.FunctionRegistry 中带定义的工具以MCP服务器的形式提供
+//
+// 流式处理器输出的每个StreamChunk会作为进度通知发送（请求携带progressToken时），
+// ToolCallResult.Error 会转换为 isError 的工具结果，便于客户端把错误交给模型处理。
+type Server struct {
+	registry     *tools.FunctionRegistry
+	info         Implementation
+	instructions string
+
+	mutex      sync.Mutex
+	inflight   map[string]context.CancelFunc // 会话ID + 请求ID -> 取消函数
+	sessions   map[string]time.Time          // 会话ID -> 最近一次请求的时间
+	sessionTTL time.Duration
+	peers      map[Transport]struct{}
+}
+
+// DefaultSessionTTL HTTP会话默认的空闲过期时间
+const DefaultSessionTTL = 30 * time.Minute
+
+// ServerOption 服务器选项
+type ServerOption func(*Server)
+
+// WithServerInfo 设置服务器信息
+func WithServerInfo(name, version string) ServerOption {
+	return func(s *Server) {
+		s.info = Implementation{Name: name, Version: version}
+	}
+}
+
+// WithInstructions 设置初始化时返回给客户端的使用说明
+func WithInstructions(instructions string) ServerOption {
+	return func(s *Server) {
+		s.instructions = instructions
+	}
+}
+
+// WithSessionTTL 设置HTTP会话的空闲过期时间，超过该时间没有请求的会话失效，客户端需要重新初始化
+func WithSessionTTL(ttl time.Duration) ServerOption {
+	return func(s *Server) {
+		s.sessionTTL = ttl
+	}
+}
+
+// NewServer 创建MCP服务器
+func NewServer(registry *tools.FunctionRegistry, opts ...ServerOption) *Server {
+	s := &Server{
+		registry:   registry,
+		info:       Implementation{Name: "go-anyllm", Version: "1.0.0"},
+		inflight:   make(map[string]context.CancelFunc),
+		sessions:   make(map[string]time.Time),
+		sessionTTL: DefaultSessionTTL,
+		peers:      make(map[Transport]struct{}),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// ServeStdio 通过标准输入输出提供服务，直到输入结束或ctx取消
+func (s *Server) ServeStdio(ctx context.Context) error {
+	return s.Serve(ctx, NewStreamTransport(os.Stdin, os.Stdout))
+}
+
+// Serve 在指定传输上提供服务，直到传输关闭或ctx取消
+func (s *Server) Serve(ctx context.Context, transport Transport) error {
+	send := func(msg *Message) {
+		_ = transport.Send(context.Background(), msg)
+	}
+	s.mutex.Lock()
+	s.peers[transport] = struct{}{}
+	s.mutex.Unlock()
+	defer func() {
+		s.mutex.Lock()
+		delete(s.peers, transport)
+		s.mutex.Unlock()
+	}()
+
+	// 每个连接使用独立的会话ID，避免多个连接的请求ID互相冲突
+	sessionID := newSessionID()
+	err := transport.Start(ctx, func(msg *Message) {
+		// 请求并发处理，通知（如取消）需要立即生效
+		if msg.IsRequest() {
+			go s.handleMessage(ctx, sessionID, msg, send)
+		} else {
+			s.handleMessage(ctx, sessionID, msg, send)
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	select {
+	case <-ctx.Done():
+		transport.Close()
+		return ctx.Err()
+	case <-transport.Done():
+		return nil
+	}
+}
+
+// NotifyToolsChanged 通知已连接的stdio客户端工具列表发生变化
+func (s *Server) NotifyToolsChanged() {
+	msg, _ := newNotification(NotificationToolsListChanged, nil)
+
+	s.mutex.Lock()
+	peers := make([]Transport, 0, len(s.peers))
+	for peer := range s.peers {
+		peers = append(peers, peer)
+	}
+	s.mutex.Unlock()
+
+	for _, peer := range peers {
+		_ = peer.Send(context.Background(), msg)
+	}
+}
+
+// ServeHTTP 实现 Streamable HTTP 传输的服务端
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPost:
+	case http.MethodDelete:
+		if !s.endSession(r.Header.Get(headerSessionID)) {
+			http.Error(w, "unknown session", http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		return
+	default:
+		// 不提供服务器主动推送的GET流
+		w.Header().Set("Allow", "POST, DELETE")
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	var msg Message
+	if err := json.NewDecoder(r.Body).Decode(&msg); err != nil {
+		writeJSON(w, http.StatusBadRequest, newErrorResponse(nil, ErrorCodeParseError, "invalid JSON-RPC message"))
+		return
+	}
+
+	sessionID := r.Header.Get(headerSessionID)
+	if msg.Method == MethodInitialize {
+		sessionID = s.startSession()
+		w.Header().Set(headerSessionID, sessionID)
+	} else if !s.touchSession(sessionID) {
+		http.Error(w, "unknown session", http.StatusNotFound)
+		return
+	}
+
+	if !msg.IsRequest() {
+		s.handleMessage(r.Context(), sessionID, &msg, func(*Message) {})
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+
+	// 携带progressToken且客户端接受SSE时，以事件流返回进度通知和最终响应
+	flusher, canFlush := w.(http.Flusher)
+	if canFlush && hasProgressToken(&msg) && strings.Contains(r.Header.Get("Accept"), "text/event-stream") {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.WriteHeader(http.StatusOK)
+
+		var writeMutex sync.Mutex
+		s.handleMessage(r.Context(), sessionID, &msg, func(out *Message) {
+			data, err := json.Marshal(out)
+			if err != nil {
+				return
+			}
+			writeMutex.Lock()
+			defer writeMutex.Unlock()
+			fmt.Fprintf(w, "event: message\ndata: %s\n\n", data)
+			flusher.Flush()
+		})
+		return
+	}
+
+	var response *Message
+	s.handleMessage(r.Context(), sessionID, &msg, func(out *Message) {
+		if out.IsResponse() {
+			response = out
+		}
+	})
+	writeJSON(w, http.StatusOK, response)
+}
+
+// startSession 创建新会话，同时清理已过期的会话
+func (s *Server) startSession() string {
+	sessionID := newSessionID()
+	now := time.Now()
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for id, lastSeen := range s.sessions {
+		if s.expired(lastSeen, now) {
+			delete(s.sessions, id)
+		}
+	}
+	s.sessions[sessionID] = now
+	return sessionID
+}
+
+// touchSession 检查会话是否有效并更新最近请求时间，过期的会话会被移除
+func (s *Server) touchSession(sessionID string) bool {
+	now := time.Now()
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	lastSeen, exists := s.sessions[sessionID]
+	if !exists {
+		return false
+	}
+	if s.expired(lastSeen, now) {
+		delete(s.sessions, sessionID)
+		return false
+	}
+	s.sessions[sessionID] = now
+	return true
+}
+
+// endSession 结束会话并取消其进行中的请求，会话不存在时返回false
+func (s *Server) endSession(sessionID string) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if _, exists := s.sessions[sessionID]; !exists {
+		return false
+	}
+	delete(s.sessions, sessionID)
+	for key, cancel := range s.inflight {
+		if strings.HasPrefix(key, sessionID+"/") {
+			cancel()
+		}
+	}
+	return true
+}
+
+// expired 检查会话是否超过空闲过期时间，调用方需持有锁
+func (s *Server) expired(lastSeen, now time.Time) bool {
+	return s.sessionTTL > 0 && now.Sub(lastSeen) > s.sessionTTL
+}
+
+// writeJSON 写入JSON响应
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// newSessionID 生成随机会话ID
+func newSessionID() string {
+	buf := make([]byte, 16)
+	_, _ = rand.Read(buf)
+	return hex.EncodeToString(buf)
+}
+
+// hasProgressToken 检查请求是否携带progressToken
+func hasProgressToken(msg *Message) bool {
+	var params struct {
+		Meta *RequestMeta `json:"_meta"`
+	}
+	if err := json.Unmarshal(msg.Params, &params); err != nil {
+		return false
+	}
+	return params.Meta != nil && params.Meta.ProgressToken != nil
+}
+
+// handleMessage 处理一条消息，send用于发送响应和通知
+func (s *Server) handleMessage(ctx context.Context, sessionID string, msg *Message, send func(*Message)) {
+	if msg.IsNotification() {
+		if msg.Method == NotificationCancelled {
+			var params CancelledParams
+			if err := json.Unmarshal(msg.Params, &params); err == nil {
+				s.mutex.Lock()
+				cancel, exists := s.inflight[sessionID+"/"+string(params.RequestID)]
+				s.mutex.Unlock()
+				if exists {
+					cancel()
+				}
+			}
+		}
+		return
+	}
+	if !msg.IsRequest() {
+		return
+	}
+
+	key := sessionID + "/" + string(msg.ID)
+	ctx, cancel := context.WithCancel(ctx)
+	s.mutex.Lock()
+	s.inflight[key] = cancel
+	s.mutex.Unlock()
+	defer func() {
+		s.mutex.Lock()
+		delete(s.inflight, key)
+		s.mutex.Unlock()
+		cancel()
+	}()
+
+	result, rpcErr := s.dispatch(ctx, msg, send)
+	if ctx.Err() != nil && msg.Method == MethodToolsCall {
+		// 已取消的请求不再响应
+		return
+	}
+	if rpcErr != nil {
+		send(&Message{JSONRPC: jsonrpcVersion, ID: msg.ID, Error: rpcErr})
+		return
+	}
+
+	resp, err := newResponse(msg.ID, result)
+	if err != nil {
+		send(newErrorResponse(msg.ID, ErrorCodeInternalError, err.Error()))
+		return
+	}
+	send(resp)
+}
+
+// dispatch 按方法处理请求
+func (s *Server) dispatch(ctx context.Context, msg *Message, send func(*Message)) (interface{}, *RPCError) {
+	switch msg.Method {
+	case MethodInitialize:
+		return InitializeResult{
+			ProtocolVersion: ProtocolVersion,
+			Capabilities: ServerCapabilities{
+				Tools: &ToolsCapability{ListChanged: true},
+			},
+			ServerInfo:   s.info,
+			Instructions: s.instructions,
+		}, nil
+
+	case MethodPing:
+		return struct{}{}, nil
+
+	case MethodToolsList:
+		return ListToolsResult{Tools: s.listTools()}, nil
+
+	case MethodToolsCall:
+		var params CallToolParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return nil, &RPCError{Code: ErrorCodeInvalidParams, Message: "invalid tools/call params"}
+		}
+		if _, exists := s.registry.GetTool(params.Name); !exists {
+			return nil, &RPCError{Code: ErrorCodeInvalidParams, Message: "unknown tool: " + params.Name}
+		}
+		return s.callTool(ctx, msg.ID, params, send), nil
+
+	default:
+		return nil, &RPCError{Code: ErrorCodeMethodNotFound, Message: "method not found: " + msg.Method}
+	}
+}
+
+// listTools 将注册表中的工具定义转换为MCP工具
+func (s *Server) listTools() []Tool {
+	registered := s.registry.Tools()
+	result := make([]Tool, 0, len(registered))
+	for _, tool := range registered {
+		result = append(result, Tool{
+			Name:        tool.Function.Name,
+			Description: tool.Function.Description,
+			InputSchema: inputSchema(tool.Function.Parameters),
+		})
+	}
+	return result
+}
+
+// inputSchema 将工具参数定义转换为MCP要求的对象Schema，去掉值为null的字段
+func inputSchema(parameters interface{}) json.RawMessage {
+	schema := map[string]interface{}{}
+	if data, err := json.Marshal(parameters); err == nil {
+		_ = json.Unmarshal(data, &schema)
+	}
+	for key, value := range schema {
+		if value == nil {
+			delete(schema, key)
+		}
+	}
+	if _, exists := schema["type"]; !exists {
+		schema["type"] = "object"
+	}
+
+	data, _ := json.Marshal(schema)
+	return data
+}
+
+// callTool 执行工具调用
+func (s *Server) callTool(ctx context.Context, requestID json.RawMessage, params CallToolParams, send func(*Message)) *CallToolResult {
+	arguments := "{}"
+	if len(params.Arguments) > 0 && string(params.Arguments) != "null" {
+		arguments = string(params.Arguments)
+	}
+	toolCall := types.ToolCall{
+		ID:       "mcp_" + strings.Trim(string(requestID), `"`),
+		Type:     "function",
+		Function: types.ResponseToolFunction{Name: params.Name, Arguments: arguments},
+	}
+
+	if params.Meta != nil && params.Meta.ProgressToken != nil && s.registry.CanHandleStreaming(params.Name) {
+		return s.callToolStreaming(ctx, toolCall, params.Meta.ProgressToken, send)
+	}
+	return toCallToolResult(s.registry.HandleContext(ctx, toolCall))
+}
+
+// callToolStreaming 执行流式工具调用，每个块作为一条进度通知发送
+// 参数校验由 HandleStreaming 完成，校验失败时第一个块即为错误
+func (s *Server) callToolStreaming(ctx context.Context, toolCall types.ToolCall, token interface{}, send func(*Message)) *CallToolResult {
+	chunks, err := s.registry.HandleStreaming(toolCall)
+	if err != nil {
+		return toCallToolResult(&tools.ToolCallResult{ToolCallID: toolCall.ID, Error: err.Error()})
+	}
+
+	var content strings.Builder
+	var progress float64
+	for {
+		select {
+		case <-ctx.Done():
+			// 处理器不感知取消，继续读取直到其结束，避免goroutine阻塞
+			go func() {
+				for range chunks {
+				}
+			}()
+			return toCallToolResult(&tools.ToolCallResult{ToolCallID: toolCall.ID, Error: ctx.Err().Error()})
+
+		case chunk, ok := <-chunks:
+			if !ok {
+				return toCallToolResult(&tools.ToolCallResult{ToolCallID: toolCall.ID, Content: content.String()})
+			}
+			if chunk.Error != nil {
+				go func() {
+					for range chunks {
+					}
+				}()
+				result := validationResult(toolCall.ID, chunk.Error)
+				result.Content = content.String()
+				return toCallToolResult(result)
+			}
+
+			content.WriteString(chunk.Content)
+			if chunk.Content != "" {
+				progress++
+				if msg, err := newNotification(NotificationProgress, ProgressParams{
+					ProgressToken: token,
+					Progress:      progress,
+					Message:       chunk.Content,
+				}); err == nil {
+					send(msg)
+				}
+			}
+		}
+	}
+}
+
+// validationResult 构造错误结果，参数校验失败时附带违规项
+func validationResult(toolCallID string, err error) *tools.ToolCallResult {
+	result := &tools.ToolCallResult{ToolCallID: toolCallID, Error: err.Error()}
+	var validationErr *tools.SchemaValidationError
+	if errors.As(err, &validationErr) {
+		result.Violations = validationErr.Violations
+	}
+	return result
+}
+
+// toCallToolResult 将注册表的调用结果转换为MCP工具结果
+func toCallToolResult(result *tools.ToolCallResult) *CallToolResult {
+	if result.Error == "" {
+		return &CallToolResult{Content: []Content{NewTextContent(result.Content)}}
+	}
+
+	text := result.Error
+	if len(result.Violations) > 0 {
+		// 参数校验失败时返回逐项说明，便于模型修正后重试
+		if content, ok := result.ToToolMessage().Content.(string); ok {
+			text = content
+		}
+	}
+	return &CallToolResult{Content: []Content{NewTextContent(text)}, IsError: true}
+}
@@ -0,0 +1,314 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yu1ec/go-anyllm/tools"
+	"github.com/yu1ec/go-anyllm/types"
+)
+
+type greetArgs struct {
+	Name string `json:"name" description:"要问候的人"`
+}
+
+// countdownHandler 流式工具处理器，每个数字输出一个块
+type countdownHandler struct{}
+
+func (countdownHandler) HandleToolCallStream(toolCall types.ToolCall) (<-chan tools.StreamChunk, error) {
+	chunks := make(chan tools.StreamChunk, 4)
+	go func() {
+		defer close(chunks)
+		for i := 3; i > 0; i-- {
+			chunks <- tools.StreamChunk{Content: fmt.Sprintf("%d ", i)}
+		}
+		chunks <- tools.StreamChunk{Content: "go", Done: true}
+	}()
+	return chunks, nil
+}
+
+// newTestRegistry 创建包含同步、失败和流式工具的注册表
+func newTestRegistry(t *testing.T) *tools.FunctionRegistry {
+	t.Helper()
+
+	registry := tools.NewFunctionRegistry()
+	if _, err := tools.RegisterFunc(registry, "greet", "问候",
+		func(ctx context.Context, args greetArgs) (string, error) {
+			return "你好, " + args.Name, nil
+		}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tools.RegisterFunc(registry, "broken", "总是失败",
+		func(ctx context.Context, args struct{}) (string, error) {
+			return "", errors.New("disk full")
+		}); err != nil {
+		t.Fatal(err)
+	}
+
+	countdown := tools.NewTool("countdown", "倒计时").BuildForTypes()
+	registry.RegisterTool(countdown, nil)
+	registry.RegisterStreaming("countdown", countdownHandler{})
+	return registry
+}
+
+// connectPipe 通过内存管道连接客户端和服务器
+func connectPipe(t *testing.T, server *Server) *Client {
+	t.Helper()
+
+	clientReader, serverWriter := io.Pipe()
+	serverReader, clientWriter := io.Pipe()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go server.Serve(ctx, NewStreamTransport(serverReader, serverWriter, serverWriter))
+
+	client := NewClient(NewStreamTransport(clientReader, clientWriter, clientWriter))
+	connectCtx, connectCancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer connectCancel()
+	if _, err := client.Connect(connectCtx); err != nil {
+		t.Fatalf("连接失败: %v", err)
+	}
+
+	t.Cleanup(func() {
+		client.Close()
+		cancel()
+	})
+	return client
+}
+
+func TestServerTools(t *testing.T) {
+	server := NewServer(newTestRegistry(t), WithServerInfo("test-server", "0.1"))
+	client := connectPipe(t, server)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if info := client.ServerInfo(); info.ServerInfo.Name != "test-server" {
+		t.Errorf("服务器信息错误: %+v", info.ServerInfo)
+	}
+
+	list, err := client.ListTools(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 3 || list[0].Name != "greet" {
+		t.Fatalf("工具列表错误: %+v", list)
+	}
+	var schema map[string]interface{}
+	if err := json.Unmarshal(list[0].InputSchema, &schema); err != nil {
+		t.Fatal(err)
+	}
+	if schema["type"] != "object" || schema["required"] == nil {
+		t.Errorf("inputSchema错误: %s", list[0].InputSchema)
+	}
+	if err := json.Unmarshal(list[2].InputSchema, &schema); err != nil || strings.Contains(string(list[2].InputSchema), "null") {
+		t.Errorf("inputSchema不应包含null: %s", list[2].InputSchema)
+	}
+
+	result, err := client.CallTool(ctx, "greet", json.RawMessage(`{"name":"小明"}`))
+	if err != nil || result.IsError || ResultText(result) != "你好, 小明" {
+		t.Errorf("调用结果错误: %v %+v", err, result)
+	}
+
+	result, err = client.CallTool(ctx, "broken", nil)
+	if err != nil || !result.IsError || ResultText(result) != "disk full" {
+		t.Errorf("处理器错误应转换为isError结果: %v %+v", err, result)
+	}
+
+	result, err = client.CallTool(ctx, "greet", json.RawMessage(`{"name":1}`))
+	if err != nil || !result.IsError || !strings.Contains(ResultText(result), "invalid_arguments") {
+		t.Errorf("参数校验失败应返回违规说明: %v %+v", err, result)
+	}
+
+	if _, err := client.CallTool(ctx, "missing", nil); err == nil {
+		t.Error("未知工具应返回RPC错误")
+	}
+}
+
+func TestServerStreamingProgress(t *testing.T) {
+	server := NewServer(newTestRegistry(t))
+	client := connectPipe(t, server)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var messages []string
+	result, err := client.CallToolWithProgress(ctx, "countdown", nil, func(p ProgressParams) {
+		messages = append(messages, p.Message)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ResultText(result) != "3 2 1 go" {
+		t.Errorf("期望 %q，得到 %q", "3 2 1 go", ResultText(result))
+	}
+	if strings.Join(messages, "") != "3 2 1 go" || len(messages) != 4 {
+		t.Errorf("每个块应作为一条进度通知: %q", messages)
+	}
+
+	// 不请求进度时同样返回完整结果
+	result, err = client.CallTool(ctx, "countdown", nil)
+	if err != nil || ResultText(result) != "3 2 1 go" {
+		t.Errorf("同步调用流式工具错误: %v %+v", err, result)
+	}
+}
+
+func TestServerStdioCancelIsolation(t *testing.T) {
+	registry := tools.NewFunctionRegistry()
+	started := make(chan string, 2)
+	stopped := make(chan string, 2)
+	release := make(chan struct{})
+	if _, err := tools.RegisterFunc(registry, "wait", "等待释放",
+		func(ctx context.Context, args greetArgs) (string, error) {
+			started <- args.Name
+			select {
+			case <-release:
+				return "done", nil
+			case <-ctx.Done():
+				stopped <- args.Name
+				return "", ctx.Err()
+			}
+		}); err != nil {
+		t.Fatal(err)
+	}
+	server := NewServer(registry)
+	clientA := connectPipe(t, server)
+	clientB := connectPipe(t, server)
+
+	// 两个连接的请求ID相同，A先开始，B后开始
+	ctxA, cancelA := context.WithCancel(context.Background())
+	go clientA.CallTool(ctxA, "wait", json.RawMessage(`{"name":"A"}`))
+	<-started
+
+	ctxB, cancelB := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancelB()
+	resultB := make(chan *CallToolResult, 1)
+	go func() {
+		result, _ := clientB.CallTool(ctxB, "wait", json.RawMessage(`{"name":"B"}`))
+		resultB <- result
+	}()
+	<-started
+
+	cancelA()
+	if name := <-stopped; name != "A" {
+		t.Fatalf("取消A的请求不应影响B，被取消的是 %s", name)
+	}
+	close(release)
+	if result := <-resultB; result == nil || ResultText(result) != "done" {
+		t.Errorf("B的请求应正常完成，得到 %+v", result)
+	}
+}
+
+func TestServerHTTPWithBridge(t *testing.T) {
+	server := NewServer(newTestRegistry(t))
+	httpServer := httptest.NewServer(server)
+	defer httpServer.Close()
+
+	client := NewClient(NewHTTPTransport(httpServer.URL))
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if _, err := client.Connect(ctx); err != nil {
+		t.Fatalf("连接失败: %v", err)
+	}
+	defer client.Close()
+
+	var progressCount int
+	result, err := client.CallToolWithProgress(ctx, "countdown", nil, func(ProgressParams) { progressCount++ })
+	if err != nil || ResultText(result) != "3 2 1 go" || progressCount != 4 {
+		t.Errorf("SSE流式调用错误: %v %+v 进度数 %d", err, result, progressCount)
+	}
+
+	// 远程注册表通过桥接注册到本地注册表
+	local := tools.NewFunctionRegistry()
+	if _, err := NewBridge(client, local, WithToolPrefix("remote_")).Sync(ctx); err != nil {
+		t.Fatal(err)
+	}
+	got := local.HandleContext(ctx, types.ToolCall{
+		ID:       "call_1",
+		Function: types.ResponseToolFunction{Name: "remote_greet", Arguments: `{"name":"世界"}`},
+	})
+	if got.Error != "" || got.Content != "你好, 世界" {
+		t.Errorf("桥接调用结果错误: %+v", got)
+	}
+
+	// 未知会话返回404
+	resp, err := httpServer.Client().Post(httpServer.URL, "application/json", strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"ping"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != 404 {
+		t.Errorf("期望404，得到 %d", resp.StatusCode)
+	}
+}
+
+func TestServerHTTPSessionLifecycle(t *testing.T) {
+	server := NewServer(newTestRegistry(t), WithSessionTTL(50*time.Millisecond))
+	httpServer := httptest.NewServer(server)
+	defer httpServer.Close()
+
+	post := func(sessionID, body string) *http.Response {
+		t.Helper()
+		req, err := http.NewRequest(http.MethodPost, httpServer.URL, strings.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+		if sessionID != "" {
+			req.Header.Set(headerSessionID, sessionID)
+		}
+		resp, err := httpServer.Client().Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		return resp
+	}
+	initialize := func() string {
+		resp := post("", `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{}}`)
+		sessionID := resp.Header.Get(headerSessionID)
+		if sessionID == "" {
+			t.Fatal("初始化应返回会话ID")
+		}
+		return sessionID
+	}
+	ping := `{"jsonrpc":"2.0","id":2,"method":"ping"}`
+
+	// 空闲超过TTL后会话失效
+	sessionID := initialize()
+	if resp := post(sessionID, ping); resp.StatusCode != http.StatusOK {
+		t.Fatalf("期望200，得到 %d", resp.StatusCode)
+	}
+	time.Sleep(100 * time.Millisecond)
+	if resp := post(sessionID, ping); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("过期会话期望404，得到 %d", resp.StatusCode)
+	}
+
+	// DELETE结束会话
+	sessionID = initialize()
+	req, _ := http.NewRequest(http.MethodDelete, httpServer.URL, nil)
+	req.Header.Set(headerSessionID, sessionID)
+	resp, err := httpServer.Client().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("DELETE期望200，得到 %d", resp.StatusCode)
+	}
+	if resp := post(sessionID, ping); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("已结束的会话期望404，得到 %d", resp.StatusCode)
+	}
+
+	server.mutex.Lock()
+	remaining := len(server.sessions)
+	server.mutex.Unlock()
+	if remaining != 0 {
+		t.Errorf("期望没有残留会话，得到 %d", remaining)
+	}
+}
@@ -0,0 +1,471 @@
+package mcp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrTransportClosed 传输已关闭
+var ErrTransportClosed = errors.New("mcp: transport closed")
+
+// Transport MCP消息传输层
+type Transport interface {
+	// Start 启动传输，收到的每条消息都会回调handler
+	Start(ctx context.Context, handler func(*Message)) error
+
+	// Send 发送一条消息
+	Send(ctx context.Context, msg *Message) error
+
+	// Close 关闭传输
+	Close() error
+
+	// Done 传输关闭时关闭的通道
+	Done() <-chan struct{}
+}
+
+// StreamTransport 基于字节流的传输，每行一条JSON-RPC消息（MCP stdio传输格式）
+type StreamTransport struct {
+	reader  io.Reader
+	writer  io.Writer
+	closers []io.Closer
+
+	writeMutex sync.Mutex
+	done       chan struct{}
+	closeOnce  sync.Once
+}
+
+// NewStreamTransport 创建基于字节流的传输，closers会在Close时依次关闭
+func NewStreamTransport(reader io.Reader, writer io.Writer, closers ...io.Closer) *StreamTransport {
+	return &StreamTransport{
+		reader:  reader,
+		writer:  writer,
+		closers: closers,
+		done:    make(chan struct{}),
+	}
+}
+
+// Start 实现Transport接口
+func (t *StreamTransport) Start(ctx context.Context, handler func(*Message)) error {
+	go func() {
+		defer t.Close()
+
+		reader := bufio.NewReader(t.reader)
+		for {
+			line, err := reader.ReadBytes('\n')
+			if len(bytes.TrimSpace(line)) > 0 {
+				var msg Message
+				if jsonErr := json.Unmarshal(line, &msg); jsonErr == nil {
+					handler(&msg)
+				}
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+// Send 实现Transport接口
+func (t *StreamTransport) Send(ctx context.Context, msg *Message) error {
+	select {
+	case <-t.done:
+		return ErrTransportClosed
+	default:
+	}
+
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("mcp: failed to marshal message: %w", err)
+	}
+	data = append(data, '\n')
+
+	t.writeMutex.Lock()
+	defer t.writeMutex.Unlock()
+	if _, err := t.writer.Write(data); err != nil {
+		return fmt.Errorf("mcp: failed to write message: %w", err)
+	}
+	return nil
+}
+
+// Close 实现Transport接口
+func (t *StreamTransport) Close() error {
+	var firstErr error
+	t.closeOnce.Do(func() {
+		close(t.done)
+		for _, closer := range t.closers {
+			if err := closer.Close(); err != nil && firstErr == nil {
+				firstErr = err
+			}
+		}
+	})
+	return firstErr
+}
+
+// Done 实现Transport接口
+func (t *StreamTransport) Done() <-chan struct{} {
+	return t.done
+}
+
+// CommandTransport 启动子进程并通过其标准输入输出通信的stdio传输
+type CommandTransport struct {
+	cmd *exec.Cmd
+	*StreamTransport
+}
+
+// NewCommandTransport 创建stdio传输，Start时启动命令
+func NewCommandTransport(command string, args ...string) *CommandTransport {
+	return NewCommandTransportFromCmd(exec.Command(command, args...))
+}
+
+// NewCommandTransportFromCmd 使用自定义的命令（环境变量、工作目录等）创建stdio传输
+func NewCommandTransportFromCmd(cmd *exec.Cmd) *CommandTransport {
+	return &CommandTransport{cmd: cmd}
+}
+
+// Start 实现Transport接口
+func (t *CommandTransport) Start(ctx context.Context, handler func(*Message)) error {
+	stdin, err := t.cmd.StdinPipe()
+	if err != nil {
+		return fmt.Errorf("mcp: failed to create stdin pipe: %w", err)
+	}
+	stdout, err := t.cmd.StdoutPipe()
+	if err != nil {
+		return fmt.Errorf("mcp: failed to create stdout pipe: %w", err)
+	}
+	if err := t.cmd.Start(); err != nil {
+		return fmt.Errorf("mcp: failed to start server process: %w", err)
+	}
+
+	t.StreamTransport = NewStreamTransport(stdout, stdin, stdin, processCloser{t.cmd})
+	return t.StreamTransport.Start(ctx, handler)
+}
+
+// Send 实现Transport接口
+func (t *CommandTransport) Send(ctx context.Context, msg *Message) error {
+	if t.StreamTransport == nil {
+		return ErrTransportClosed
+	}
+	return t.StreamTransport.Send(ctx, msg)
+}
+
+// Close 实现Transport接口
+func (t *CommandTransport) Close() error {
+	if t.StreamTransport == nil {
+		return nil
+	}
+	return t.StreamTransport.Close()
+}
+
+// Done 实现Transport接口
+func (t *CommandTransport) Done() <-chan struct{} {
+	if t.StreamTransport == nil {
+		closed := make(chan struct{})
+		close(closed)
+		return closed
+	}
+	return t.StreamTransport.Done()
+}
+
+// processCloser 等待子进程退出，超时后强制结束
+type processCloser struct {
+	cmd *exec.Cmd
+}
+
+func (p processCloser) Close() error {
+	exited := make(chan error, 1)
+	go func() { exited <- p.cmd.Wait() }()
+
+	select {
+	case <-exited:
+		return nil
+	case <-time.After(5 * time.Second):
+		_ = p.cmd.Process.Kill()
+		<-exited
+		return nil
+	}
+}
+
+// 会话ID请求头
+const headerSessionID = "Mcp-Session-Id"
+
+// HTTPTransport MCP Streamable HTTP 传输
+//
+// 每条消息通过POST发送，服务器以JSON或SSE流返回响应；
+// 初始化完成后会尝试打开GET SSE流接收服务器主动发送的通知（服务器不支持时忽略）。
+type HTTPTransport struct {
+	endpoint   string
+	httpClient *http.Client
+	headers    map[string]string
+
+	handler   func(*Message)
+	sessionID string
+	mutex     sync.Mutex
+
+	listenCtx    context.Context
+	listenCancel context.CancelFunc
+	done         chan struct{}
+	closeOnce    sync.Once
+}
+
+// HTTPTransportOption HTTP传输选项
+type HTTPTransportOption func(*HTTPTransport)
+
+// WithHTTPClient 设置HTTP客户端
+func WithHTTPClient(client *http.Client) HTTPTransportOption {
+	return func(t *HTTPTransport) {
+		t.httpClient = client
+	}
+}
+
+// WithHTTPHeader 设置额外的请求头（如认证信息）
+func WithHTTPHeader(key, value string) HTTPTransportOption {
+	return func(t *HTTPTransport) {
+		t.headers[key] = value
+	}
+}
+
+// NewHTTPTransport 创建Streamable HTTP传输
+func NewHTTPTransport(endpoint string, opts ...HTTPTransportOption) *HTTPTransport {
+	t := &HTTPTransport{
+		endpoint:   endpoint,
+		httpClient: &http.Client{},
+		headers:    make(map[string]string),
+		done:       make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(t)
+	}
+	t.listenCtx, t.listenCancel = context.WithCancel(context.Background())
+	return t
+}
+
+// Start 实现Transport接口
+func (t *HTTPTransport) Start(ctx context.Context, handler func(*Message)) error {
+	t.handler = handler
+	return nil
+}
+
+// SessionID 返回服务器分配的会话ID
+func (t *HTTPTransport) SessionID() string {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	return t.sessionID
+}
+
+// setHeaders 设置公共请求头
+func (t *HTTPTransport) setHeaders(req *http.Request) {
+	for k, v := range t.headers {
+		req.Header.Set(k, v)
+	}
+	if sessionID := t.SessionID(); sessionID != "" {
+		req.Header.Set(headerSessionID, sessionID)
+	}
+}
+
+// Send 实现Transport接口
+func (t *HTTPTransport) Send(ctx context.Context, msg *Message) error {
+	select {
+	case <-t.done:
+		return ErrTransportClosed
+	default:
+	}
+
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("mcp: failed to marshal message: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json, text/event-stream")
+	t.setHeaders(req)
+
+	resp, err := t.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("mcp: HTTP request failed: %w", err)
+	}
+
+	if sessionID := resp.Header.Get(headerSessionID); sessionID != "" {
+		t.mutex.Lock()
+		t.sessionID = sessionID
+		t.mutex.Unlock()
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		defer resp.Body.Close()
+		errBody, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return fmt.Errorf("mcp: HTTP %d - %s", resp.StatusCode, strings.TrimSpace(string(errBody)))
+	}
+
+	if msg.Method == NotificationInitialized {
+		go t.listen()
+	}
+
+	if resp.StatusCode == http.StatusAccepted {
+		resp.Body.Close()
+		return nil
+	}
+
+	contentType := resp.Header.Get("Content-Type")
+	if strings.HasPrefix(contentType, "text/event-stream") {
+		// SSE流中可能先返回进度通知，再返回响应，异步读取
+		go func() {
+			defer resp.Body.Close()
+			t.readEvents(resp.Body)
+		}()
+		return nil
+	}
+
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("mcp: failed to read response: %w", err)
+	}
+	t.dispatch(data)
+	return nil
+}
+
+// dispatch 解析单条消息或批量消息并回调
+func (t *HTTPTransport) dispatch(data []byte) {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 || t.handler == nil {
+		return
+	}
+
+	if data[0] == '[' {
+		var batch []*Message
+		if err := json.Unmarshal(data, &batch); err == nil {
+			for _, msg := range batch {
+				t.handler(msg)
+			}
+		}
+		return
+	}
+
+	var msg Message
+	if err := json.Unmarshal(data, &msg); err == nil {
+		t.handler(&msg)
+	}
+}
+
+// readEvents 读取SSE事件流
+func (t *HTTPTransport) readEvents(r io.Reader) {
+	readSSE(r, func(event, data string) {
+		if event == "" || event == "message" {
+			t.dispatch([]byte(data))
+		}
+	})
+}
+
+// listen 打开GET SSE流接收服务器通知，服务器不支持时退出
+func (t *HTTPTransport) listen() {
+	backoff := time.Second
+	for {
+		req, err := http.NewRequestWithContext(t.listenCtx, http.MethodGet, t.endpoint, nil)
+		if err != nil {
+			return
+		}
+		req.Header.Set("Accept", "text/event-stream")
+		t.setHeaders(req)
+
+		resp, err := t.httpClient.Do(req)
+		if err == nil {
+			if resp.StatusCode != http.StatusOK {
+				resp.Body.Close()
+				// 405表示服务器不提供通知流
+				return
+			}
+			t.readEvents(resp.Body)
+			resp.Body.Close()
+			backoff = time.Second
+		}
+
+		select {
+		case <-t.listenCtx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		if backoff < 30*time.Second {
+			backoff *= 2
+		}
+	}
+}
+
+// Close 实现Transport接口，结束会话
+func (t *HTTPTransport) Close() error {
+	t.closeOnce.Do(func() {
+		t.listenCancel()
+
+		if sessionID := t.SessionID(); sessionID != "" {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			if req, err := http.NewRequestWithContext(ctx, http.MethodDelete, t.endpoint, nil); err == nil {
+				t.setHeaders(req)
+				if resp, err := t.httpClient.Do(req); err == nil {
+					resp.Body.Close()
+				}
+			}
+		}
+
+		close(t.done)
+	})
+	return nil
+}
+
+// Done 实现Transport接口
+func (t *HTTPTransport) Done() <-chan struct{} {
+	return t.done
+}
+
+// readSSE 解析SSE事件流，每个事件回调一次
+func readSSE(r io.Reader, fn func(event, data string)) {
+	reader := bufio.NewReader(r)
+	var event string
+	var data strings.Builder
+
+	flush := func() {
+		if data.Len() > 0 {
+			fn(event, strings.TrimSuffix(data.String(), "\n"))
+		}
+		event = ""
+		data.Reset()
+	}
+
+	for {
+		line, err := reader.ReadString('\n')
+		line = strings.TrimRight(line, "\r\n")
+
+		switch {
+		case line == "":
+			if err == nil {
+				flush()
+			}
+		case strings.HasPrefix(line, ":"):
+			// 注释（心跳）
+		case strings.HasPrefix(line, "event:"):
+			event = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+		case strings.HasPrefix(line, "data:"):
+			data.WriteString(strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+			data.WriteByte('\n')
+		}
+
+		if err != nil {
+			flush()
+			return
+		}
+	}
+}
@@ -0,0 +1,40 @@
+// Package models 提供模型能力和请求约束的注册表，用于在发送请求前检查请求是否适用于所选模型
+package models
+
+import (
+	"github.com/yu1ec/go-anyllm/types"
+)
+
+// Capabilities 模型能力
+type Capabilities struct {
+	ContextWindow   int  // 上下文窗口（token），0表示未知
+	MaxOutputTokens int  // 单次最多输出的token，0表示未知
+	Vision          bool // 是否支持图像输入
+	Tools           bool // 是否支持工具调用
+	JSONMode        bool // 是否支持 response_format json_object
+	JSONSchema      bool // 是否支持 response_format json_schema
+	Thinking        bool // 是否支持（或总是使用）思考模式
+}
+
+// Info 模型信息，合并了 /models 接口的结果和内置能力表
+type Info struct {
+	ID       string
+	Provider string
+	OwnedBy  string
+	Created  int64
+
+	// Capabilities 模型能力，不在能力表中的模型为nil
+	Capabilities *Capabilities
+}
+
+// Lookup 在 DefaultRegistry 中查找模型能力
+// 先精确匹配，再匹配最长的前缀（前缀之后必须是 "-" 或 ":"），都不匹配时 ok 为false
+func Lookup(provider, model string) (caps Capabilities, ok bool) {
+	constraints, ok := DefaultRegistry.Lookup(provider, model)
+	return constraints.Capabilities, ok
+}
+
+// Merge 把 /models 接口返回的模型与 DefaultRegistry 中的能力合并
+func Merge(provider string, listed []types.Model) []Info {
+	return DefaultRegistry.Merge(provider, listed)
+}
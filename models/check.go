@@ -0,0 +1,114 @@
+package models
+
+import (
+	"fmt"
+
+	"github.com/yu1ec/go-anyllm/types"
+)
+
+// 请求可能用到的功能
+const (
+	FeatureVision     = "vision"
+	FeatureTools      = "tools"
+	FeatureJSONMode   = "json_mode"
+	FeatureJSONSchema = "json_schema"
+	FeatureThinking   = "thinking"
+)
+
+// UnsupportedFeatureError 模型不支持请求用到的功能
+type UnsupportedFeatureError struct {
+	Provider string
+	Model    string
+	Feature  string
+}
+
+// Error 实现error接口
+func (e *UnsupportedFeatureError) Error() string {
+	return fmt.Sprintf("models: %s model %s does not support %s", e.Provider, e.Model, e.Feature)
+}
+
+// OutputLimitError max_tokens 超过模型的输出上限
+type OutputLimitError struct {
+	Provider  string
+	Model     string
+	MaxTokens int
+	Limit     int
+}
+
+// Error 实现error接口
+func (e *OutputLimitError) Error() string {
+	return fmt.Sprintf("models: max_tokens %d exceeds the %d output token limit of %s model %s", e.MaxTokens, e.Limit, e.Provider, e.Model)
+}
+
+// RequiredFeatures 返回请求用到的功能
+func RequiredFeatures(req *types.ChatCompletionRequest) []string {
+	var features []string
+	for i := range req.Messages {
+		if len(req.Messages[i].GetContent().Images()) > 0 {
+			features = append(features, FeatureVision)
+			break
+		}
+	}
+	if len(req.Tools) > 0 {
+		features = append(features, FeatureTools)
+	}
+	if req.ResponseFormat != nil {
+		switch req.ResponseFormat.Type {
+		case types.ResponseFormatJSONObject:
+			features = append(features, FeatureJSONMode)
+		case types.ResponseFormatJSONSchema:
+			features = append(features, FeatureJSONSchema)
+		}
+	}
+	if req.IsThinkingEnabled() {
+		features = append(features, FeatureThinking)
+	}
+	return features
+}
+
+// Supports 模型能力是否包含指定功能
+func (c Capabilities) Supports(feature string) bool {
+	switch feature {
+	case FeatureVision:
+		return c.Vision
+	case FeatureTools:
+		return c.Tools
+	case FeatureJSONMode:
+		return c.JSONMode
+	case FeatureJSONSchema:
+		return c.JSONSchema
+	case FeatureThinking:
+		return c.Thinking
+	default:
+		return true
+	}
+}
+
+// Check 检查模型是否支持请求用到的功能，以及 max_tokens 是否超过输出上限
+// 使用 DefaultRegistry，不在注册表中的模型不做检查
+func Check(provider string, req *types.ChatCompletionRequest) error {
+	return DefaultRegistry.Check(provider, req)
+}
+
+// Check 检查模型是否支持请求用到的功能，以及 max_tokens 是否超过输出上限
+// 只检查精确匹配的模型，前缀匹配的模型（例如 qwen3-vl-plus 匹配 qwen3）能力可能不同
+func (r *Registry) Check(provider string, req *types.ChatCompletionRequest) error {
+	constraints, exact, _ := r.lookup(provider, req.Model)
+	if !exact {
+		return nil
+	}
+	return checkCapabilities(provider, req, constraints.Capabilities)
+}
+
+// checkCapabilities 按模型能力检查请求
+func checkCapabilities(provider string, req *types.ChatCompletionRequest, caps Capabilities) error {
+	for _, feature := range RequiredFeatures(req) {
+		if !caps.Supports(feature) {
+			return &UnsupportedFeatureError{Provider: provider, Model: req.Model, Feature: feature}
+		}
+	}
+	if req.MaxTokens != nil && caps.MaxOutputTokens > 0 && *req.MaxTokens > caps.MaxOutputTokens {
+		return &OutputLimitError{Provider: provider, Model: req.Model, MaxTokens: *req.MaxTokens, Limit: caps.MaxOutputTokens}
+	}
+	return nil
+}
@@ -0,0 +1,197 @@
+package models
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/yu1ec/go-anyllm/types"
+)
+
+func TestLookup(t *testing.T) {
+	tests := []struct {
+		provider string
+		model    string
+		ok       bool
+		vision   bool
+	}{
+		{"openai", "gpt-4o", true, true},
+		{"openai", "gpt-4o-2024-08-06", true, true},
+		{"openai", "gpt-4o-mini-2024-07-18", true, true},
+		{"openai", "gpt-4-0613", true, false},
+		{"openai", "gpt-4omni", false, false},
+		{"alicloud", "qwen-plus-latest", true, false},
+		{"alicloud", "qwen-vl-max-latest", true, true},
+		{"deepseek", "gpt-4o", false, false},
+		{"ollama", "llama3", false, false},
+	}
+	for _, tt := range tests {
+		caps, ok := Lookup(tt.provider, tt.model)
+		if ok != tt.ok || caps.Vision != tt.vision {
+			t.Errorf("%s/%s: 期望 ok=%v vision=%v，得到 ok=%v vision=%v", tt.provider, tt.model, tt.ok, tt.vision, ok, caps.Vision)
+		}
+	}
+
+	if caps, _ := Lookup("openai", "gpt-4o-mini"); caps.MaxOutputTokens != 16384 {
+		t.Errorf("期望最长前缀匹配，得到 %+v", caps)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	image := types.NewMultiModalMessage(types.RoleUser, []types.MessageContent{
+		types.NewTextContent("这是什么"),
+		types.NewImageContent("https://example.com/a.png"),
+	})
+	maxTokens := 10000
+	enabled := true
+
+	tests := []struct {
+		name     string
+		provider string
+		req      types.ChatCompletionRequest
+		feature  string
+	}{
+		{"不支持图像", "deepseek", types.ChatCompletionRequest{Model: "deepseek-chat", Messages: []types.ChatCompletionMessage{image}}, FeatureVision},
+		{"不支持工具", "openai", types.ChatCompletionRequest{Model: "o1-mini", Tools: []types.Tool{{Type: "function"}}}, FeatureTools},
+		{"不支持json_schema", "deepseek", types.ChatCompletionRequest{Model: "deepseek-chat", ResponseFormat: &types.ResponseFormat{Type: types.ResponseFormatJSONSchema}}, FeatureJSONSchema},
+		{"不支持思考", "alicloud", types.ChatCompletionRequest{Model: "qwen-max", EnableThinking: &enabled}, FeatureThinking},
+		{"支持图像", "openai", types.ChatCompletionRequest{Model: "gpt-4o", Messages: []types.ChatCompletionMessage{image}}, ""},
+		{"未知模型", "ollama", types.ChatCompletionRequest{Model: "llava", Messages: []types.ChatCompletionMessage{image}}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Check(tt.provider, &tt.req)
+			var featureErr *UnsupportedFeatureError
+			if tt.feature == "" {
+				if err != nil {
+					t.Errorf("期望通过检查，得到 %v", err)
+				}
+			} else if !errors.As(err, &featureErr) || featureErr.Feature != tt.feature {
+				t.Errorf("期望不支持 %s，得到 %v", tt.feature, err)
+			}
+		})
+	}
+
+	var limitErr *OutputLimitError
+	err := Check("deepseek", &types.ChatCompletionRequest{Model: "deepseek-chat", MaxTokens: &maxTokens})
+	if !errors.As(err, &limitErr) || limitErr.Limit != 8192 {
+		t.Errorf("期望 OutputLimitError，得到 %v", err)
+	}
+}
+
+func TestMerge(t *testing.T) {
+	infos := Merge("openai", []types.Model{{ID: "gpt-4o", OwnedBy: "system"}, {ID: "whisper-1"}})
+	if infos[0].Capabilities == nil || !infos[0].Capabilities.JSONSchema || infos[0].Provider != "openai" {
+		t.Errorf("gpt-4o 应带有能力信息: %+v", infos[0])
+	}
+	if infos[1].Capabilities != nil {
+		t.Errorf("未知模型的能力应为nil: %+v", infos[1])
+	}
+}
+
+func TestValidate(t *testing.T) {
+	float := func(v float32) *float32 { return &v }
+	intPtr := func(v int) *int { return &v }
+	user := []types.ChatCompletionMessage{types.NewTextMessage(types.RoleUser, "你好")}
+
+	tests := []struct {
+		name      string
+		provider  string
+		req       types.ChatCompletionRequest
+		parameter string
+	}{
+		{"缺少模型", "openai", types.ChatCompletionRequest{Messages: user}, "model"},
+		{"没有消息", "openai", types.ChatCompletionRequest{Model: "gpt-4o"}, "messages"},
+		{"未知角色", "openai", types.ChatCompletionRequest{Model: "gpt-4o", Messages: []types.ChatCompletionMessage{{Role: "bot", Content: "hi"}}}, "messages[0].role"},
+		{"工具消息缺少ID", "openai", types.ChatCompletionRequest{Model: "gpt-4o", Messages: []types.ChatCompletionMessage{{Role: types.RoleTool, Content: "ok"}}}, "messages[0].tool_call_id"},
+		{"temperature超出范围", "openai", types.ChatCompletionRequest{Model: "gpt-4o", Messages: user, Temperature: float(2.5)}, ParamTemperature},
+		{"通义千问temperature不包含2", "alicloud", types.ChatCompletionRequest{Model: "qwen-plus", Messages: user, Temperature: float(2)}, ParamTemperature},
+		{"top_p超出范围", "deepseek", types.ChatCompletionRequest{Model: "deepseek-chat", Messages: user, TopP: float(1.5)}, ParamTopP},
+		{"top_p为0", "openai", types.ChatCompletionRequest{Model: "gpt-4o", Messages: user, TopP: float(0)}, ""},
+		{"developer角色", "openai", types.ChatCompletionRequest{Model: "o3", Messages: []types.ChatCompletionMessage{{Role: types.RoleDeveloper, Content: "简洁回答"}, user[0]}}, ""},
+		{"前缀匹配不继承不支持的参数", "openai", types.ChatCompletionRequest{Model: "gpt-5-chat-latest", Messages: user, Temperature: float(0.7)}, ""},
+		{"前缀匹配收紧参数范围", "alicloud", types.ChatCompletionRequest{Model: "qwen3-vl-plus", Messages: user, Temperature: float(2)}, ParamTemperature},
+		{"top_logprobs需要logprobs", "openai", types.ChatCompletionRequest{Model: "gpt-4o", Messages: user, TopLogprobs: intPtr(3)}, ParamTopLogprobs},
+		{"推理模型不支持temperature", "openai", types.ChatCompletionRequest{Model: "o3-mini", Messages: user, Temperature: float(0.5)}, ParamTemperature},
+		{"不支持logprobs", "deepseek", types.ChatCompletionRequest{Model: "deepseek-reasoner", Messages: user, Logprobs: true}, ParamLogprobs},
+		{"未知模型使用通用范围", "ollama", types.ChatCompletionRequest{Model: "llama3", Messages: user, PresencePenalty: float(-3)}, ParamPresencePenalty},
+		{"有效请求", "alicloud", types.ChatCompletionRequest{Model: "qwen-plus", Messages: user, Temperature: float(1.9)}, ""},
+		{"推理模型忽略temperature", "deepseek", types.ChatCompletionRequest{Model: "deepseek-reasoner", Messages: user, Temperature: float(0.7)}, ""},
+		{"推理模型不能关闭推理", "openai", types.ChatCompletionRequest{Model: "o3-mini", Messages: user, Reasoning: &types.Reasoning{Enabled: types.ToPtr(false)}}, ParamReasoning},
+		{"OpenAI不支持推理预算", "openai", types.ChatCompletionRequest{Model: "o3-mini", Messages: user, Reasoning: &types.Reasoning{BudgetTokens: 1024}}, ParamReasoning},
+		{"通义千问不支持推理强度", "alicloud", types.ChatCompletionRequest{Model: "qwen-plus", Messages: user, Reasoning: &types.Reasoning{Effort: types.ReasoningEffortLow}}, ParamReasoning},
+		{"deepseek-reasoner不能调整", "deepseek", types.ChatCompletionRequest{Model: "deepseek-reasoner", Messages: user, Reasoning: &types.Reasoning{Effort: types.ReasoningEffortLow}}, ParamReasoning},
+		{"通义千问推理预算", "alicloud", types.ChatCompletionRequest{Model: "qwen-plus", Messages: user, Reasoning: &types.Reasoning{BudgetTokens: 1024}}, ""},
+		{"推理强度", "openai", types.ChatCompletionRequest{Model: "o3-mini", Messages: user, Reasoning: &types.Reasoning{Effort: types.ReasoningEffortHigh}}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(tt.provider, &tt.req)
+			var validationErr *ValidationError
+			if tt.parameter == "" {
+				if err != nil {
+					t.Errorf("期望通过校验，得到 %v", err)
+				}
+			} else if !errors.As(err, &validationErr) || validationErr.Parameter != tt.parameter {
+				t.Errorf("期望 %s 参数错误，得到 %v", tt.parameter, err)
+			}
+		})
+	}
+
+	// 不支持推理的模型开启推理
+	req := types.ChatCompletionRequest{Model: "gpt-4o", Messages: user, Reasoning: &types.Reasoning{Effort: types.ReasoningEffortLow}}
+	var featureErr *UnsupportedFeatureError
+	if err := Validate("openai", &req); !errors.As(err, &featureErr) || featureErr.Feature != FeatureThinking {
+		t.Errorf("期望 UnsupportedFeatureError，得到 %v", err)
+	}
+
+	// 功能和输出上限检查同样生效
+	req = types.ChatCompletionRequest{Model: "deepseek-chat", Messages: user, MaxTokens: intPtr(9000)}
+	var limitErr *OutputLimitError
+	if err := Validate("deepseek", &req); !errors.As(err, &limitErr) {
+		t.Errorf("期望 OutputLimitError，得到 %v", err)
+	}
+
+	// 前缀匹配的模型不继承功能限制
+	image := types.NewMultiModalMessage(types.RoleUser, []types.MessageContent{
+		types.NewTextContent("这是什么"),
+		types.NewImageContent("https://example.com/a.png"),
+	})
+	for _, tt := range []struct{ provider, model string }{{"alicloud", "qwen3-vl-plus"}, {"openai", "gpt-4-vision-preview"}} {
+		req = types.ChatCompletionRequest{Model: tt.model, Messages: []types.ChatCompletionMessage{image}}
+		if err := Validate(tt.provider, &req); err != nil {
+			t.Errorf("%s: 期望通过校验，得到 %v", tt.model, err)
+		}
+	}
+
+	req = types.ChatCompletionRequest{Model: "deepseek-reasoner", Messages: user, Temperature: float(0.7), TopP: float(0.9)}
+	if ignored := IgnoredParameters("deepseek", &req); len(ignored) != 2 || ignored[0] != ParamTemperature {
+		t.Errorf("期望忽略temperature和top_p，得到 %v", ignored)
+	}
+}
+
+func TestRegistryRegister(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register("ollama", "llama3.1", Constraints{
+		Capabilities: Capabilities{ContextWindow: 131072, MaxOutputTokens: 4096, Tools: true},
+		Temperature:  &Range{Min: 0, Max: 1},
+	})
+
+	if _, ok := registry.Lookup("ollama", "llama3.1:8b"); !ok {
+		t.Error("带标签的模型应匹配前缀")
+	}
+	temperature := float32(1.5)
+	req := &types.ChatCompletionRequest{
+		Model:       "llama3.1:70b",
+		Messages:    []types.ChatCompletionMessage{types.NewTextMessage(types.RoleUser, "hi")},
+		Temperature: &temperature,
+	}
+	if err := registry.Validate("ollama", req); err == nil || err.Error() != "models: invalid temperature for ollama model llama3.1:70b: 1.5 is outside [0, 1]" {
+		t.Errorf("期望使用注册的范围，得到 %v", err)
+	}
+	if names := registry.Models("ollama"); len(names) != 1 || names[0] != "llama3.1" {
+		t.Errorf("模型列表错误: %v", names)
+	}
+	if _, ok := DefaultRegistry.Lookup("ollama", "llama3.1"); ok {
+		t.Error("独立的注册表不应影响 DefaultRegistry")
+	}
+}
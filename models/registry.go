@@ -0,0 +1,257 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/yu1ec/go-anyllm/types"
+)
+
+// 请求参数名称，用于 UnsupportedParameters 和 IgnoredParameters
+const (
+	ParamTemperature      = "temperature"
+	ParamTopP             = "top_p"
+	ParamPresencePenalty  = "presence_penalty"
+	ParamFrequencyPenalty = "frequency_penalty"
+	ParamLogprobs         = "logprobs"
+	ParamTopLogprobs      = "top_logprobs"
+	ParamLogitBias        = "logit_bias"
+	ParamN                = "n"
+	ParamStop             = "stop"
+	ParamReasoning        = "reasoning"
+)
+
+// samplingParameters 推理模型通常不使用的采样参数
+var samplingParameters = []string{ParamTemperature, ParamTopP, ParamPresencePenalty, ParamFrequencyPenalty}
+
+// Range 数值参数的取值范围
+type Range struct {
+	Min          float64
+	Max          float64
+	ExclusiveMin bool // 为true时不包含Min
+	ExclusiveMax bool // 为true时不包含Max
+}
+
+// Contains 值是否在范围内
+func (r Range) Contains(v float64) bool {
+	if v < r.Min || (r.ExclusiveMin && v == r.Min) {
+		return false
+	}
+	return v < r.Max || (!r.ExclusiveMax && v == r.Max)
+}
+
+// String 返回区间表示，例如 "(0, 1]"
+func (r Range) String() string {
+	left, right := "[", "]"
+	if r.ExclusiveMin {
+		left = "("
+	}
+	if r.ExclusiveMax {
+		right = ")"
+	}
+	return fmt.Sprintf("%s%g, %g%s", left, r.Min, r.Max, right)
+}
+
+// 参数的默认取值范围，与OpenAI一致
+var (
+	DefaultTemperatureRange = Range{Min: 0, Max: 2}
+	DefaultTopPRange        = Range{Min: 0, Max: 1}
+	DefaultPenaltyRange     = Range{Min: -2, Max: 2}
+)
+
+// DefaultMaxTopLogprobs top_logprobs 的默认上限
+const DefaultMaxTopLogprobs = 20
+
+// ReasoningControl 模型控制推理的方式
+type ReasoningControl int
+
+const (
+	// ReasoningUnspecified 未指定，只按 Capabilities.Thinking 检查是否支持推理
+	ReasoningUnspecified ReasoningControl = iota
+	// ReasoningAlways 总是推理，不能关闭，也不能调整强度或预算，例如 deepseek-reasoner
+	ReasoningAlways
+	// ReasoningToggle 可以开关推理并设置预算，例如通义千问的 enable_thinking 和 thinking_budget
+	ReasoningToggle
+	// ReasoningEffort 总是推理，可以调整强度，例如OpenAI o系列的 reasoning_effort
+	ReasoningEffort
+)
+
+// Constraints 模型的能力和请求约束
+type Constraints struct {
+	Capabilities
+
+	Logprobs       bool // 是否支持logprobs
+	MaxTopLogprobs int  // top_logprobs 上限，0表示使用 DefaultMaxTopLogprobs
+
+	// 参数取值范围，nil表示使用默认范围
+	Temperature      *Range
+	TopP             *Range
+	PresencePenalty  *Range
+	FrequencyPenalty *Range
+
+	// UnsupportedParameters 设置后服务商会返回错误的参数
+	UnsupportedParameters []string
+	// IgnoredParameters 模型接受但不起作用的参数，例如推理模型的采样参数
+	IgnoredParameters []string
+
+	// Reasoning 模型控制推理的方式，用于检查 types.Reasoning
+	Reasoning ReasoningControl
+}
+
+// Registry 模型约束注册表，按服务商和模型名称（或前缀）组织，可以并发使用
+type Registry struct {
+	mu     sync.RWMutex
+	models map[string]map[string]Constraints
+}
+
+// NewRegistry 创建空的注册表
+func NewRegistry() *Registry {
+	return &Registry{models: make(map[string]map[string]Constraints)}
+}
+
+// DefaultRegistry 包含内置模型约束的注册表，UnifiedClient 默认使用它校验请求
+var DefaultRegistry = newBuiltinRegistry()
+
+// Register 在 DefaultRegistry 中注册或覆盖模型约束
+func Register(provider, model string, constraints Constraints) {
+	DefaultRegistry.Register(provider, model, constraints)
+}
+
+// Register 注册或覆盖模型约束
+// model 同时作为前缀使用：注册 "my-model" 后 "my-model-v2" 和 "my-model:7b" 也会匹配，
+// 但前缀匹配的模型只检查参数取值范围，不检查功能、输出上限和不支持的参数
+func (r *Registry) Register(provider, model string, constraints Constraints) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.models[provider] == nil {
+		r.models[provider] = make(map[string]Constraints)
+	}
+	r.models[provider][model] = constraints
+}
+
+// Lookup 查找模型约束
+// 先精确匹配，再匹配最长的前缀（前缀之后必须是 "-" 或 ":"），都不匹配时 ok 为false
+func (r *Registry) Lookup(provider, model string) (Constraints, bool) {
+	constraints, _, ok := r.lookup(provider, model)
+	return constraints, ok
+}
+
+// lookup 查找模型约束，exact 表示是否精确匹配
+func (r *Registry) lookup(provider, model string) (constraints Constraints, exact, ok bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	table := r.models[provider]
+	if constraints, ok := table[model]; ok {
+		return constraints, true, true
+	}
+
+	best := ""
+	for prefix := range table {
+		if len(prefix) > len(best) && (strings.HasPrefix(model, prefix+"-") || strings.HasPrefix(model, prefix+":")) {
+			best = prefix
+		}
+	}
+	if best == "" {
+		return Constraints{}, false, false
+	}
+	return table[best], false, true
+}
+
+// Models 返回服务商已注册的模型名称，按字母排序
+func (r *Registry) Models(provider string) []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	names := make([]string, 0, len(r.models[provider]))
+	for name := range r.models[provider] {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Merge 把 /models 接口返回的模型与注册表中的能力合并
+func (r *Registry) Merge(provider string, listed []types.Model) []Info {
+	merged := make([]Info, len(listed))
+	for i, model := range listed {
+		merged[i] = Info{ID: model.ID, Provider: provider, OwnedBy: model.OwnedBy, Created: model.Created}
+		if constraints, ok := r.Lookup(provider, model.ID); ok {
+			caps := constraints.Capabilities
+			merged[i].Capabilities = &caps
+		}
+	}
+	return merged
+}
+
+// newBuiltinRegistry 创建包含内置模型约束的注册表
+// 带版本或日期后缀的模型（例如 gpt-4o-2024-08-06、qwen-plus-latest）通过前缀匹配
+func newBuiltinRegistry() *Registry {
+	r := NewRegistry()
+
+	// DeepSeek
+	r.Register("deepseek", "deepseek-chat", Constraints{
+		Capabilities: Capabilities{ContextWindow: 128000, MaxOutputTokens: 8192, Tools: true, JSONMode: true},
+		Logprobs:     true,
+	})
+	r.Register("deepseek", "deepseek-reasoner", Constraints{
+		Capabilities:          Capabilities{ContextWindow: 128000, MaxOutputTokens: 65536, Tools: true, JSONMode: true, Thinking: true},
+		UnsupportedParameters: []string{ParamLogprobs, ParamTopLogprobs},
+		IgnoredParameters:     samplingParameters,
+		Reasoning:             ReasoningAlways,
+	})
+
+	// OpenAI
+	gpt := func(contextWindow, maxOutput int, vision, jsonMode, jsonSchema bool) Constraints {
+		return Constraints{
+			Capabilities: Capabilities{ContextWindow: contextWindow, MaxOutputTokens: maxOutput, Vision: vision, Tools: true, JSONMode: jsonMode, JSONSchema: jsonSchema},
+			Logprobs:     true,
+		}
+	}
+	r.Register("openai", "gpt-3.5-turbo", gpt(16385, 4096, false, true, false))
+	r.Register("openai", "gpt-4", gpt(8192, 8192, false, false, false))
+	r.Register("openai", "gpt-4-turbo", gpt(128000, 4096, true, true, false))
+	r.Register("openai", "gpt-4o", gpt(128000, 16384, true, true, true))
+	r.Register("openai", "gpt-4o-mini", gpt(128000, 16384, true, true, true))
+	r.Register("openai", "gpt-4.1", gpt(1047576, 32768, true, true, true))
+
+	// OpenAI推理模型不接受采样参数和logprobs
+	reasoning := func(contextWindow, maxOutput int, vision, tools bool) Constraints {
+		return Constraints{
+			Capabilities:          Capabilities{ContextWindow: contextWindow, MaxOutputTokens: maxOutput, Vision: vision, Tools: tools, JSONMode: tools, JSONSchema: tools, Thinking: true},
+			UnsupportedParameters: append([]string{ParamLogprobs, ParamTopLogprobs, ParamLogitBias}, samplingParameters...),
+			Reasoning:             ReasoningEffort,
+		}
+	}
+	r.Register("openai", "gpt-5", reasoning(400000, 128000, true, true))
+	r.Register("openai", "o1", reasoning(200000, 100000, true, true))
+	r.Register("openai", "o1-mini", reasoning(128000, 65536, false, false))
+	r.Register("openai", "o3", reasoning(200000, 100000, true, true))
+	r.Register("openai", "o3-mini", reasoning(200000, 100000, false, true))
+	r.Register("openai", "o4-mini", reasoning(200000, 100000, true, true))
+
+	// 阿里云通义千问，temperature 不包含2
+	qwenTemperature := &Range{Min: 0, Max: 2, ExclusiveMax: true}
+	qwen := func(caps Capabilities, logprobs bool) Constraints {
+		constraints := Constraints{Capabilities: caps, Logprobs: logprobs, Temperature: qwenTemperature}
+		if caps.Thinking {
+			constraints.Reasoning = ReasoningToggle
+		}
+		return constraints
+	}
+	r.Register("alicloud", "qwen-turbo", qwen(Capabilities{ContextWindow: 1000000, MaxOutputTokens: 16384, Tools: true, JSONMode: true, Thinking: true}, true))
+	r.Register("alicloud", "qwen-plus", qwen(Capabilities{ContextWindow: 131072, MaxOutputTokens: 16384, Tools: true, JSONMode: true, Thinking: true}, true))
+	r.Register("alicloud", "qwen-max", qwen(Capabilities{ContextWindow: 32768, MaxOutputTokens: 8192, Tools: true, JSONMode: true}, true))
+	r.Register("alicloud", "qwen-long", qwen(Capabilities{ContextWindow: 10000000, MaxOutputTokens: 8192}, false))
+	r.Register("alicloud", "qwen-vl-plus", qwen(Capabilities{ContextWindow: 131072, MaxOutputTokens: 8192, Vision: true}, false))
+	r.Register("alicloud", "qwen-vl-max", qwen(Capabilities{ContextWindow: 131072, MaxOutputTokens: 8192, Vision: true}, false))
+	r.Register("alicloud", "qwen-omni-turbo", qwen(Capabilities{ContextWindow: 32768, MaxOutputTokens: 2048, Vision: true}, false))
+	r.Register("alicloud", "qwen3", qwen(Capabilities{ContextWindow: 131072, MaxOutputTokens: 16384, Tools: true, JSONMode: true, Thinking: true}, true))
+	r.Register("alicloud", "qwq-plus", Constraints{
+		Capabilities:      Capabilities{ContextWindow: 131072, MaxOutputTokens: 8192, Tools: true, Thinking: true},
+		IgnoredParameters: samplingParameters,
+		Reasoning:         ReasoningAlways,
+	})
+
+	return r
+}
@@ -0,0 +1,209 @@
+package models
+
+import (
+	"fmt"
+
+	"github.com/yu1ec/go-anyllm/types"
+)
+
+// ValidationError 请求参数无效
+type ValidationError struct {
+	Provider  string
+	Model     string
+	Parameter string // 参数名称，例如 "temperature"、"messages[2].tool_call_id"
+	Message   string
+}
+
+// Error 实现error接口
+func (e *ValidationError) Error() string {
+	if e.Model == "" {
+		return fmt.Sprintf("models: invalid %s: %s", e.Parameter, e.Message)
+	}
+	return fmt.Sprintf("models: invalid %s for %s model %s: %s", e.Parameter, e.Provider, e.Model, e.Message)
+}
+
+// validRoles 消息允许的角色
+var validRoles = map[string]bool{
+	types.RoleSystem:    true,
+	types.RoleUser:      true,
+	types.RoleAssistant: true,
+	types.RoleTool:      true,
+	types.RoleDeveloper: true,
+	types.RoleFunction:  true,
+}
+
+// Validate 使用 DefaultRegistry 校验请求
+func Validate(provider string, req *types.ChatCompletionRequest) error {
+	return DefaultRegistry.Validate(provider, req)
+}
+
+// Validate 在发送请求前校验请求
+//
+// 所有模型都会检查消息、角色和参数的通用取值范围；已注册的模型还会按约束检查参数范围。
+// 精确匹配的模型还会检查不支持的参数、logprobs、推理设置，以及 Check 中的功能和输出上限；
+// 前缀匹配的模型（例如 gpt-5-chat-latest 匹配 gpt-5）能力和参数可能不同，只收紧参数范围。
+// 参数错误返回 *ValidationError，功能不支持返回 *UnsupportedFeatureError，输出超限返回 *OutputLimitError。
+func (r *Registry) Validate(provider string, req *types.ChatCompletionRequest) error {
+	if req == nil {
+		return fmt.Errorf("models: request is nil")
+	}
+	if req.Model == "" {
+		return &ValidationError{Parameter: "model", Message: "model is required"}
+	}
+	constraints, exact, known := r.lookup(provider, req.Model)
+	invalid := func(parameter, format string, args ...interface{}) error {
+		err := &ValidationError{Parameter: parameter, Message: fmt.Sprintf(format, args...)}
+		if known {
+			err.Provider, err.Model = provider, req.Model
+		}
+		return err
+	}
+
+	if len(req.Messages) == 0 {
+		return invalid("messages", "at least one message is required")
+	}
+	for i, message := range req.Messages {
+		if !validRoles[message.Role] {
+			return invalid(fmt.Sprintf("messages[%d].role", i), "unknown role %q", message.Role)
+		}
+		if message.Role == types.RoleTool && message.ToolCallID == "" {
+			return invalid(fmt.Sprintf("messages[%d].tool_call_id", i), "tool messages require tool_call_id")
+		}
+	}
+
+	ranges := []struct {
+		name   string
+		value  *float32
+		custom *Range
+		def    Range
+	}{
+		{ParamTemperature, req.Temperature, constraints.Temperature, DefaultTemperatureRange},
+		{ParamTopP, req.TopP, constraints.TopP, DefaultTopPRange},
+		{ParamPresencePenalty, req.PresencePenalty, constraints.PresencePenalty, DefaultPenaltyRange},
+		{ParamFrequencyPenalty, req.FrequencyPenalty, constraints.FrequencyPenalty, DefaultPenaltyRange},
+	}
+	for _, param := range ranges {
+		if param.value == nil {
+			continue
+		}
+		valid := param.def
+		if param.custom != nil {
+			valid = *param.custom
+		}
+		if !valid.Contains(float64(*param.value)) {
+			return invalid(param.name, "%g is outside %s", *param.value, valid)
+		}
+	}
+
+	if req.MaxTokens != nil && *req.MaxTokens < 1 {
+		return invalid("max_tokens", "must be at least 1, got %d", *req.MaxTokens)
+	}
+	if req.N != nil && *req.N < 1 {
+		return invalid(ParamN, "must be at least 1, got %d", *req.N)
+	}
+	if req.TopLogprobs != nil {
+		maxTopLogprobs := constraints.MaxTopLogprobs
+		if maxTopLogprobs == 0 {
+			maxTopLogprobs = DefaultMaxTopLogprobs
+		}
+		if !req.Logprobs {
+			return invalid(ParamTopLogprobs, "requires logprobs to be true")
+		}
+		if *req.TopLogprobs < 0 || *req.TopLogprobs > maxTopLogprobs {
+			return invalid(ParamTopLogprobs, "must be between 0 and %d, got %d", maxTopLogprobs, *req.TopLogprobs)
+		}
+	}
+	reasoning, err := req.ResolveReasoning()
+	if err != nil {
+		return err
+	}
+	if !exact {
+		return nil
+	}
+	for _, name := range constraints.UnsupportedParameters {
+		if parameterSet(req, name) {
+			return invalid(name, "parameter is not supported")
+		}
+	}
+	if req.Logprobs && !constraints.Logprobs {
+		return invalid(ParamLogprobs, "parameter is not supported")
+	}
+	if reasoning != nil {
+		if message := checkReasoning(*reasoning, constraints.Reasoning); message != "" {
+			return invalid(ParamReasoning, message)
+		}
+	}
+	return checkCapabilities(provider, req, constraints.Capabilities)
+}
+
+// checkReasoning 按模型控制推理的方式检查推理设置，返回错误说明
+// 开启推理是否被支持由 checkCapabilities 按 Capabilities.Thinking 检查
+func checkReasoning(reasoning types.Reasoning, control ReasoningControl) string {
+	switch control {
+	case ReasoningAlways:
+		if reasoning.IsDisabled() {
+			return "reasoning cannot be disabled"
+		}
+		if reasoning.Effort != "" || reasoning.BudgetTokens > 0 {
+			return "reasoning effort and budget are not supported"
+		}
+	case ReasoningToggle:
+		if reasoning.Effort != "" {
+			return "reasoning effort is not supported, use a reasoning budget instead"
+		}
+	case ReasoningEffort:
+		if reasoning.IsDisabled() {
+			return "reasoning cannot be disabled"
+		}
+		if reasoning.BudgetTokens > 0 {
+			return "reasoning budget is not supported, use reasoning effort instead"
+		}
+	}
+	return ""
+}
+
+// IgnoredParameters 使用 DefaultRegistry 返回请求中设置了、但模型会忽略的参数
+func IgnoredParameters(provider string, req *types.ChatCompletionRequest) []string {
+	return DefaultRegistry.IgnoredParameters(provider, req)
+}
+
+// IgnoredParameters 返回请求中设置了、但模型会忽略的参数，例如推理模型的temperature
+func (r *Registry) IgnoredParameters(provider string, req *types.ChatCompletionRequest) []string {
+	constraints, ok := r.Lookup(provider, req.Model)
+	if !ok {
+		return nil
+	}
+	var ignored []string
+	for _, name := range constraints.IgnoredParameters {
+		if parameterSet(req, name) {
+			ignored = append(ignored, name)
+		}
+	}
+	return ignored
+}
+
+// parameterSet 请求是否设置了指定参数
+func parameterSet(req *types.ChatCompletionRequest, name string) bool {
+	switch name {
+	case ParamTemperature:
+		return req.Temperature != nil
+	case ParamTopP:
+		return req.TopP != nil
+	case ParamPresencePenalty:
+		return req.PresencePenalty != nil
+	case ParamFrequencyPenalty:
+		return req.FrequencyPenalty != nil
+	case ParamLogprobs:
+		return req.Logprobs
+	case ParamTopLogprobs:
+		return req.TopLogprobs != nil
+	case ParamLogitBias:
+		return len(req.LogitBias) > 0
+	case ParamN:
+		return req.N != nil
+	case ParamStop:
+		return len(req.Stop) > 0
+	default:
+		return false
+	}
+}
@@ -0,0 +1,121 @@
+// Package pricing 提供按服务商和模型的价格表，根据 types.Usage 计算请求费用并按标签累计
+package pricing
+
+import (
+	"time"
+
+	"github.com/yu1ec/go-anyllm/types"
+)
+
+// 货币
+const (
+	CurrencyUSD = "USD"
+	CurrencyCNY = "CNY"
+)
+
+// Price 模型价格，单位为每百万token
+type Price struct {
+	Currency    string
+	Input       float64 // 输入（未命中缓存）
+	CachedInput float64 // 命中缓存的输入，0表示与Input相同
+	Output      float64 // 输出
+	Reasoning   float64 // 推理token，0表示与Output相同
+
+	// OffPeak 优惠时段，例如夜间折扣
+	OffPeak []OffPeak
+}
+
+// OffPeak 每天的优惠时段
+type OffPeak struct {
+	Start      time.Duration // UTC零点之后的开始时间
+	End        time.Duration // UTC零点之后的结束时间，小于Start时跨越零点
+	Multiplier float64       // 价格系数，例如0.5表示五折
+}
+
+// Contains 时间是否在优惠时段内
+func (o OffPeak) Contains(at time.Time) bool {
+	at = at.UTC()
+	offset := at.Sub(time.Date(at.Year(), at.Month(), at.Day(), 0, 0, 0, 0, time.UTC))
+	if o.Start <= o.End {
+		return offset >= o.Start && offset < o.End
+	}
+	return offset >= o.Start || offset < o.End
+}
+
+// Cost 一次请求的token数和费用
+type Cost struct {
+	Currency string // 没有价格时为空
+
+	InputTokens       int // 未命中缓存的输入token
+	CachedInputTokens int // 命中缓存的输入token
+	OutputTokens      int // 不含推理的输出token
+	ReasoningTokens   int
+
+	Input       float64
+	CachedInput float64
+	Output      float64
+	Reasoning   float64
+
+	OffPeak bool // 是否按优惠时段计价
+}
+
+// Total 返回总费用
+func (c Cost) Total() float64 {
+	return c.Input + c.CachedInput + c.Output + c.Reasoning
+}
+
+// Priced 是否有价格
+func (c Cost) Priced() bool {
+	return c.Currency != ""
+}
+
+// Tokens 把用量拆分为未命中缓存的输入、命中缓存的输入、不含推理的输出和推理token，不计算费用
+// 缓存命中数优先使用DeepSeek的 prompt_cache_hit_tokens，其次是OpenAI的 cached_tokens
+func Tokens(usage *types.Usage) Cost {
+	if usage == nil {
+		return Cost{}
+	}
+	cached := usage.PromptCacheHitTokens
+	if cached == 0 && usage.PromptTokensDetails != nil {
+		cached = usage.PromptTokensDetails.CachedTokens
+	}
+	reasoning := usage.ReasoningTokens()
+	return Cost{
+		InputTokens:       max(usage.PromptTokens-cached, 0),
+		CachedInputTokens: cached,
+		OutputTokens:      max(usage.CompletionTokens-reasoning, 0),
+		ReasoningTokens:   reasoning,
+	}
+}
+
+// Cost 按价格计算用量的费用，at 用于判断是否在优惠时段
+func (p Price) Cost(usage *types.Usage, at time.Time) Cost {
+	cost := Tokens(usage)
+	cost.Currency = p.Currency
+
+	multiplier := 1.0
+	for _, offPeak := range p.OffPeak {
+		if offPeak.Contains(at) {
+			multiplier = offPeak.Multiplier
+			cost.OffPeak = true
+			break
+		}
+	}
+
+	cachedInput := p.CachedInput
+	if cachedInput == 0 {
+		cachedInput = p.Input
+	}
+	reasoning := p.Reasoning
+	if reasoning == 0 {
+		reasoning = p.Output
+	}
+	amount := func(tokens int, price float64) float64 {
+		return float64(tokens) * price * multiplier / 1e6
+	}
+	cost.Input = amount(cost.InputTokens, p.Input)
+	cost.CachedInput = amount(cost.CachedInputTokens, cachedInput)
+	cost.Output = amount(cost.OutputTokens, p.Output)
+	cost.Reasoning = amount(cost.ReasoningTokens, reasoning)
+	return cost
+}
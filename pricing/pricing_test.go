@@ -0,0 +1,115 @@
+package pricing
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/yu1ec/go-anyllm/types"
+)
+
+// almostEqual 比较金额
+func almostEqual(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+func TestCost(t *testing.T) {
+	usage := &types.Usage{
+		PromptTokens:            1_000_000,
+		CompletionTokens:        2_000_000,
+		PromptCacheHitTokens:    400_000,
+		CompletionTokensDetails: &types.CompletionTokensDetails{ReasoningTokens: 500_000},
+	}
+
+	// 带夜间优惠时段的价格，北京时间00:30-08:30（UTC 16:30-00:30）
+	registry := NewRegistry()
+	registry.Register("deepseek", "deepseek-reasoner", Price{
+		Currency: CurrencyUSD, Input: 0.55, CachedInput: 0.14, Output: 2.19,
+		OffPeak: []OffPeak{{Start: 16*time.Hour + 30*time.Minute, End: 30 * time.Minute, Multiplier: 0.25}},
+	})
+
+	// 北京时间下午，标准价格
+	peak := time.Date(2025, 6, 1, 6, 0, 0, 0, time.UTC)
+	cost, ok := registry.Cost("deepseek", "deepseek-reasoner", usage, peak)
+	if !ok || cost.Currency != CurrencyUSD || cost.OffPeak {
+		t.Fatalf("期望按标准价格计算，得到 %+v", cost)
+	}
+	if cost.InputTokens != 600_000 || cost.CachedInputTokens != 400_000 || cost.OutputTokens != 1_500_000 || cost.ReasoningTokens != 500_000 {
+		t.Errorf("token拆分错误: %+v", cost)
+	}
+	want := 0.6*0.55 + 0.4*0.14 + 2*2.19
+	if !almostEqual(cost.Total(), want) {
+		t.Errorf("期望 %v，得到 %v", want, cost.Total())
+	}
+
+	// 跨越零点的优惠时段
+	for _, at := range []time.Time{
+		time.Date(2025, 6, 1, 17, 0, 0, 0, time.UTC),
+		time.Date(2025, 6, 1, 0, 15, 0, 0, time.UTC),
+	} {
+		offPeak, _ := registry.Cost("deepseek", "deepseek-reasoner", usage, at)
+		if !offPeak.OffPeak || !almostEqual(offPeak.Total(), want*0.25) {
+			t.Errorf("%v 期望按优惠价格计算，得到 %+v", at, offPeak)
+		}
+	}
+
+	// OpenAI的缓存命中在 prompt_tokens_details 中
+	usage = &types.Usage{PromptTokens: 1000, CompletionTokens: 100, PromptTokensDetails: &types.PromptTokensDetails{CachedTokens: 800}}
+	cost, ok = DefaultRegistry.ResponseCost("openai", &types.ChatCompletionResponse{Model: "gpt-4o-2024-08-06", Usage: usage})
+	if !ok || cost.CachedInputTokens != 800 || !almostEqual(cost.Total(), (200*2.5+800*1.25+100*10)/1e6) {
+		t.Errorf("带日期的模型应匹配gpt-4o的价格，得到 %+v", cost)
+	}
+
+	if cost, ok := DefaultRegistry.Cost("ollama", "llama3", usage, peak); ok || cost.Priced() || cost.InputTokens != 200 {
+		t.Errorf("没有价格的模型只拆分token，得到 %+v", cost)
+	}
+	if _, ok := DefaultRegistry.Lookup("deepseek", "deepseek-chat"); ok {
+		t.Error("DeepSeek不应有内置价格")
+	}
+}
+
+func TestRegisterOverride(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register("ollama", "llama3", Price{Currency: CurrencyUSD, Input: 1, Output: 2})
+	price, ok := registry.Lookup("ollama", "llama3:70b")
+	if !ok || price.Output != 2 {
+		t.Errorf("带标签的模型应匹配前缀，得到 %+v", price)
+	}
+	if _, ok := DefaultRegistry.Lookup("ollama", "llama3"); ok {
+		t.Error("独立的价格表不应影响 DefaultRegistry")
+	}
+}
+
+func TestTracker(t *testing.T) {
+	tracker := NewTracker()
+	tracker.Add("alice", Cost{Currency: CurrencyUSD, InputTokens: 10, Input: 0.5})
+	tracker.Add("alice", Cost{Currency: CurrencyCNY, OutputTokens: 5, Output: 2})
+	tracker.Add("bob", Cost{InputTokens: 7})
+
+	alice := tracker.Total("alice")
+	if alice.Requests != 2 || alice.InputTokens != 10 || alice.Cost[CurrencyUSD] != 0.5 || alice.Cost[CurrencyCNY] != 2 {
+		t.Errorf("alice 的累计结果错误: %+v", alice)
+	}
+	alice.Cost[CurrencyUSD] = 100
+	if tracker.Total("alice").Cost[CurrencyUSD] != 0.5 {
+		t.Error("Total 应返回副本")
+	}
+
+	sum := tracker.Sum()
+	if sum.Requests != 3 || sum.Unpriced != 1 || sum.InputTokens != 17 {
+		t.Errorf("合计错误: %+v", sum)
+	}
+	if tags := tracker.Tags(); len(tags) != 2 || tags[0] != "alice" {
+		t.Errorf("标签错误: %v", tags)
+	}
+
+	tracker.Reset()
+	if tracker.Sum().Requests != 0 {
+		t.Error("Reset 后应没有记录")
+	}
+
+	if tag, ok := TagFromContext(WithTag(context.Background(), "batch")); !ok || tag != "batch" {
+		t.Errorf("期望标签batch，得到 %q", tag)
+	}
+}
@@ -0,0 +1,122 @@
+package pricing
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/yu1ec/go-anyllm/types"
+)
+
+// Registry 价格表，按服务商和模型名称（或前缀）组织，可以并发使用
+type Registry struct {
+	mu     sync.RWMutex
+	prices map[string]map[string]Price
+}
+
+// NewRegistry 创建空的价格表
+func NewRegistry() *Registry {
+	return &Registry{prices: make(map[string]map[string]Price)}
+}
+
+// DefaultRegistry 包含内置价格的价格表
+// 内置价格是各服务商截至2025年8月公布的标准价格，只作为估算使用，不会随服务商调价更新。
+// DeepSeek在2025年9月调价后价格变动频繁，不提供内置价格，计费时按没有价格的模型处理。
+// 需要准确计费时必须用 Register 覆盖或补充，或通过 ClientConfig.Pricing 使用自己维护的价格表
+var DefaultRegistry = newBuiltinRegistry()
+
+// Register 在 DefaultRegistry 中注册或覆盖模型价格
+func Register(provider, model string, price Price) {
+	DefaultRegistry.Register(provider, model, price)
+}
+
+// Register 注册或覆盖模型价格
+// model 同时作为前缀使用：注册 "gpt-4o" 后 "gpt-4o-2024-08-06" 也会匹配
+func (r *Registry) Register(provider, model string, price Price) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.prices[provider] == nil {
+		r.prices[provider] = make(map[string]Price)
+	}
+	r.prices[provider][model] = price
+}
+
+// Lookup 查找模型价格
+// 先精确匹配，再匹配最长的前缀（前缀之后必须是 "-" 或 ":"），都不匹配时 ok 为false
+func (r *Registry) Lookup(provider, model string) (Price, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	table := r.prices[provider]
+	if price, ok := table[model]; ok {
+		return price, true
+	}
+
+	best := ""
+	for prefix := range table {
+		if len(prefix) > len(best) && (strings.HasPrefix(model, prefix+"-") || strings.HasPrefix(model, prefix+":")) {
+			best = prefix
+		}
+	}
+	if best == "" {
+		return Price{}, false
+	}
+	return table[best], true
+}
+
+// Cost 计算用量的费用，模型没有价格时只拆分token数，ok 为false
+func (r *Registry) Cost(provider, model string, usage *types.Usage, at time.Time) (cost Cost, ok bool) {
+	price, ok := r.Lookup(provider, model)
+	if !ok {
+		return Tokens(usage), false
+	}
+	return price.Cost(usage, at), true
+}
+
+// ResponseCost 计算响应的费用，使用响应中的模型名称和创建时间
+func (r *Registry) ResponseCost(provider string, resp *types.ChatCompletionResponse) (Cost, bool) {
+	at := time.Now()
+	if resp.Created > 0 {
+		at = time.Unix(resp.Created, 0)
+	}
+	return r.Cost(provider, resp.Model, resp.Usage, at)
+}
+
+// newBuiltinRegistry 创建包含内置价格的价格表，价格截至2025年8月
+func newBuiltinRegistry() *Registry {
+	r := NewRegistry()
+
+	// OpenAI
+	openai := func(input, cachedInput, output float64) Price {
+		return Price{Currency: CurrencyUSD, Input: input, CachedInput: cachedInput, Output: output}
+	}
+	r.Register("openai", "gpt-3.5-turbo", openai(0.50, 0, 1.50))
+	r.Register("openai", "gpt-4", openai(30, 0, 60))
+	r.Register("openai", "gpt-4-turbo", openai(10, 0, 30))
+	r.Register("openai", "gpt-4o", openai(2.50, 1.25, 10))
+	r.Register("openai", "gpt-4o-mini", openai(0.15, 0.075, 0.60))
+	r.Register("openai", "gpt-4.1", openai(2, 0.50, 8))
+	r.Register("openai", "gpt-4.1-mini", openai(0.40, 0.10, 1.60))
+	r.Register("openai", "gpt-4.1-nano", openai(0.10, 0.025, 0.40))
+	r.Register("openai", "gpt-5", openai(1.25, 0.125, 10))
+	r.Register("openai", "gpt-5-mini", openai(0.25, 0.025, 2))
+	r.Register("openai", "gpt-5-nano", openai(0.05, 0.005, 0.40))
+	r.Register("openai", "o1", openai(15, 7.50, 60))
+	r.Register("openai", "o1-mini", openai(1.10, 0.55, 4.40))
+	r.Register("openai", "o3", openai(2, 0.50, 8))
+	r.Register("openai", "o3-mini", openai(1.10, 0.55, 4.40))
+	r.Register("openai", "o4-mini", openai(1.10, 0.275, 4.40))
+
+	// 阿里云通义千问，隐式缓存命中按输入价格的20%计费，思考模式的输出价格更高
+	qwen := func(input, output, reasoning float64) Price {
+		return Price{Currency: CurrencyCNY, Input: input, CachedInput: input * 0.2, Output: output, Reasoning: reasoning}
+	}
+	r.Register("alicloud", "qwen-turbo", qwen(0.3, 0.6, 3))
+	r.Register("alicloud", "qwen-plus", qwen(0.8, 2, 8))
+	r.Register("alicloud", "qwen-max", qwen(2.4, 9.6, 0))
+	r.Register("alicloud", "qwen-long", qwen(0.5, 2, 0))
+	r.Register("alicloud", "qwen-vl-plus", qwen(1.5, 4.5, 0))
+	r.Register("alicloud", "qwen-vl-max", qwen(3, 9, 0))
+	r.Register("alicloud", "qwq-plus", qwen(1.6, 4, 0))
+
+	return r
+}
@@ -0,0 +1,138 @@
+package pricing
+
+import (
+	"context"
+	"sort"
+	"sync"
+)
+
+// Totals 累计的用量和费用
+type Totals struct {
+	Requests int
+	Unpriced int // 没有价格的请求数，只累计了token
+
+	InputTokens       int
+	CachedInputTokens int
+	OutputTokens      int
+	ReasoningTokens   int
+
+	Cost map[string]float64 // 按货币累计的费用
+}
+
+// add 累加一次请求
+func (t *Totals) add(cost Cost) {
+	t.Requests++
+	t.InputTokens += cost.InputTokens
+	t.CachedInputTokens += cost.CachedInputTokens
+	t.OutputTokens += cost.OutputTokens
+	t.ReasoningTokens += cost.ReasoningTokens
+	if !cost.Priced() {
+		t.Unpriced++
+		return
+	}
+	if t.Cost == nil {
+		t.Cost = make(map[string]float64)
+	}
+	t.Cost[cost.Currency] += cost.Total()
+}
+
+// clone 返回副本
+func (t Totals) clone() Totals {
+	if t.Cost != nil {
+		costs := make(map[string]float64, len(t.Cost))
+		for currency, amount := range t.Cost {
+			costs[currency] = amount
+		}
+		t.Cost = costs
+	}
+	return t
+}
+
+// Tracker 按标签累计费用，可以并发使用
+// 标签可以是客户端、用户或功能名称，空标签也是一个有效的标签
+type Tracker struct {
+	mu     sync.Mutex
+	totals map[string]*Totals
+}
+
+// NewTracker 创建费用累计器
+func NewTracker() *Tracker {
+	return &Tracker{totals: make(map[string]*Totals)}
+}
+
+// Add 把一次请求的费用累加到标签
+func (t *Tracker) Add(tag string, cost Cost) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	totals := t.totals[tag]
+	if totals == nil {
+		totals = &Totals{}
+		t.totals[tag] = totals
+	}
+	totals.add(cost)
+}
+
+// Total 返回标签的累计结果
+func (t *Tracker) Total(tag string) Totals {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if totals := t.totals[tag]; totals != nil {
+		return totals.clone()
+	}
+	return Totals{}
+}
+
+// Tags 返回有记录的标签，按字母排序
+func (t *Tracker) Tags() []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	tags := make([]string, 0, len(t.totals))
+	for tag := range t.totals {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+	return tags
+}
+
+// Sum 返回所有标签的合计
+func (t *Tracker) Sum() Totals {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	var sum Totals
+	for _, totals := range t.totals {
+		sum.Requests += totals.Requests
+		sum.Unpriced += totals.Unpriced
+		sum.InputTokens += totals.InputTokens
+		sum.CachedInputTokens += totals.CachedInputTokens
+		sum.OutputTokens += totals.OutputTokens
+		sum.ReasoningTokens += totals.ReasoningTokens
+		for currency, amount := range totals.Cost {
+			if sum.Cost == nil {
+				sum.Cost = make(map[string]float64)
+			}
+			sum.Cost[currency] += amount
+		}
+	}
+	return sum
+}
+
+// Reset 清空所有记录
+func (t *Tracker) Reset() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.totals = make(map[string]*Totals)
+}
+
+// tagKey context中费用标签的键
+type tagKey struct{}
+
+// WithTag 返回带费用标签的context，UnifiedClient 按该标签累计这次请求的费用
+func WithTag(ctx context.Context, tag string) context.Context {
+	return context.WithValue(ctx, tagKey{}, tag)
+}
+
+// TagFromContext 返回context中的费用标签
+func TagFromContext(ctx context.Context) (string, bool) {
+	tag, ok := ctx.Value(tagKey{}).(string)
+	return tag, ok
+}
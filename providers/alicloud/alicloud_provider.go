@@ -126,9 +126,14 @@ func (p *AliCloudProvider) SetupHeaders(headers map[string]string) {
 
 // CreateChatCompletion 实现Provider接口
 func (p *AliCloudProvider) CreateChatCompletion(ctx context.Context, req *types.ChatCompletionRequest) (*types.ChatCompletionResponse, error) {
+	// 音频输出只支持流式输出
+	if req.HasModality(types.ModalityAudio) {
+		return nil, fmt.Errorf("alicloud: audio output is only available with CreateChatCompletionStream")
+	}
+
 	// 检查是否开启了思考模式
 	// 根据阿里云文档，思考模式只支持流式输出，所以需要特殊处理
-	if req.EnableThinking != nil && *req.EnableThinking {
+	if req.IsThinkingEnabled() {
 		// 如果开启了思考模式，使用流式调用然后聚合结果
 		return p.handleThinkingModeNonStream(ctx, req)
 	}
@@ -272,7 +277,7 @@ func (p *AliCloudProvider) readStreamWithTimeout(ctx context.Context, stream io.
 				choice := chunk.Choices[0]
 
 				// 检测阶段切换：从思考到输出
-				deltaContent := getContentAsString(choice.Delta.Content)
+				deltaContent := choice.Delta.GetContentAsString()
 				if isThinkingPhase && deltaContent != "" {
 					isThinkingPhase = false
 					lastDataTime = now // 重置输出阶段计时
@@ -430,18 +435,44 @@ func (p *AliCloudProvider) CreateChatCompletionStream(ctx context.Context, req *
 	return p.doOpenAIRequest(ctx, req)
 }
 
+// ListModels 实现ModelLister接口
+func (p *AliCloudProvider) ListModels(ctx context.Context) ([]types.Model, error) {
+	respBody, err := p.send(ctx, http.MethodGet, "/models", nil)
+	if err != nil {
+		return nil, err
+	}
+	defer respBody.Close()
+
+	var list types.ModelList
+	if err := json.NewDecoder(respBody).Decode(&list); err != nil {
+		return nil, fmt.Errorf("alicloud: decode models: %w", err)
+	}
+	return list.Data, nil
+}
+
 // doOpenAIRequest 发送兼容OpenAI格式的HTTP请求
 func (p *AliCloudProvider) doOpenAIRequest(ctx context.Context, req *types.ChatCompletionRequest) (io.ReadCloser, error) {
-	url := fmt.Sprintf("%s/chat/completions", p.GetBaseURL())
+	req, err := prepareRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	return p.post(ctx, "/chat/completions", req)
+}
 
+// post 发送JSON请求，返回成功响应的响应体
+func (p *AliCloudProvider) post(ctx context.Context, path string, payload interface{}) (io.ReadCloser, error) {
 	// 序列化请求体
-	body, err := json.Marshal(req)
+	body, err := json.Marshal(payload)
 	if err != nil {
 		return nil, err
 	}
+	return p.send(ctx, http.MethodPost, path, bytes.NewReader(body))
+}
 
+// send 发送HTTP请求，返回成功响应的响应体
+func (p *AliCloudProvider) send(ctx context.Context, method, path string, body io.Reader) (io.ReadCloser, error) {
 	// 创建HTTP请求
-	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	httpReq, err := http.NewRequestWithContext(ctx, method, p.GetBaseURL()+path, body)
 	if err != nil {
 		return nil, err
 	}
@@ -559,9 +590,12 @@ type AliCloudOutput struct {
 
 // AliCloudUsage 阿里云使用统计
 type AliCloudUsage struct {
-	InputTokens  int `json:"input_tokens"`
-	OutputTokens int `json:"output_tokens"`
-	TotalTokens  int `json:"total_tokens"`
+	InputTokens         int `json:"input_tokens"`
+	OutputTokens        int `json:"output_tokens"`
+	TotalTokens         int `json:"total_tokens"`
+	OutputTokensDetails *struct {
+		ReasoningTokens int `json:"reasoning_tokens"`
+	} `json:"output_tokens_details,omitempty"`
 }
 
 // convertToAliCloudRequest 转换为阿里云请求格式
@@ -633,6 +667,11 @@ func (p *AliCloudProvider) convertToOpenAIResponse(resp *AliCloudResponse, model
 			TotalTokens:      resp.Usage.TotalTokens,
 		},
 	}
+	if resp.Usage.OutputTokensDetails != nil {
+		openaiResp.Usage.CompletionTokensDetails = &types.CompletionTokensDetails{
+			ReasoningTokens: resp.Usage.OutputTokensDetails.ReasoningTokens,
+		}
+	}
 
 	return openaiResp
 }
@@ -648,21 +687,3 @@ func (p *AliCloudProvider) convertFinishReason(reason string) string {
 		return reason
 	}
 }
-
-// getContentAsString 获取内容的字符串表示
-func getContentAsString(content interface{}) string {
-	switch c := content.(type) {
-	case string:
-		return c
-	case []types.MessageContent:
-		// 只返回文本内容，忽略图像
-		for _, part := range c {
-			if part.Type == types.MessageContentTypeText {
-				return part.Text
-			}
-		}
-		return ""
-	default:
-		return ""
-	}
-}
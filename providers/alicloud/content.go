@@ -0,0 +1,56 @@
+package alicloud
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/yu1ec/go-anyllm/types"
+)
+
+// prepareRequest 校验内容类型并把内容、推理控制和历史中的思考内容转换为DashScope兼容模式的格式，返回请求的副本
+func prepareRequest(req *types.ChatCompletionRequest) (*types.ChatCompletionRequest, error) {
+	messages, err := types.MapContentParts(req.Messages, convertContentPart)
+	if err != nil {
+		return nil, err
+	}
+	prepared := *req
+	// 思考模式的工具调用循环需要传回本轮的思考内容
+	prepared.Messages = types.ApplyReasoningHistory(messages, req.ReasoningHistory, types.ReasoningHistoryToolLoop)
+	if err := applyReasoning(&prepared); err != nil {
+		return nil, err
+	}
+	return &prepared, nil
+}
+
+// convertContentPart 兼容模式支持文本、图像、音频和视频内容
+// 音频可以是URL或data URL，纯base64数据会被补全为 data:;base64,<data>
+// 视频可以是视频文件URL（video_url），也可以是图像帧列表（video）
+func convertContentPart(part types.MessageContent) (types.MessageContent, error) {
+	switch part.Type {
+	case types.MessageContentTypeText, types.MessageContentTypeImageURL:
+		return part, nil
+	case types.MessageContentTypeInputAudio:
+		if part.InputAudio == nil || part.InputAudio.Data == "" {
+			return part, fmt.Errorf("alicloud: input_audio content has no data")
+		}
+		data := part.InputAudio.Data
+		if !strings.HasPrefix(data, "http://") && !strings.HasPrefix(data, "https://") && !strings.HasPrefix(data, "data:") {
+			audio := *part.InputAudio
+			audio.Data = "data:;base64," + data
+			part.InputAudio = &audio
+		}
+		return part, nil
+	case types.MessageContentTypeVideo:
+		if len(part.Video) == 0 {
+			return part, fmt.Errorf("alicloud: video content has no frames")
+		}
+		return part, nil
+	case types.MessageContentTypeVideoURL:
+		if part.VideoURL == nil || part.VideoURL.URL == "" {
+			return part, fmt.Errorf("alicloud: video_url content has no url")
+		}
+		return part, nil
+	default:
+		return part, &types.UnsupportedModalityError{Provider: "alicloud", Modality: part.Type}
+	}
+}
@@ -0,0 +1,87 @@
+package alicloud
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/yu1ec/go-anyllm/types"
+)
+
+func TestPrepareRequest(t *testing.T) {
+	req := &types.ChatCompletionRequest{
+		Model: "qwen-omni-turbo",
+		Messages: []types.ChatCompletionMessage{
+			types.NewMultiModalMessage(types.RoleUser, []types.MessageContent{
+				types.NewInputAudioContent("UklGRg==", "wav"),
+				types.NewInputAudioContent("https://example.com/a.mp3", "mp3"),
+				types.NewVideoFramesContent("https://example.com/1.jpg", "https://example.com/2.jpg"),
+				types.NewVideoURLContent("https://example.com/a.mp4"),
+			}),
+		},
+	}
+
+	prepared, err := prepareRequest(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parts := prepared.Messages[0].Content.([]types.MessageContent)
+	if parts[0].InputAudio.Data != "data:;base64,UklGRg==" {
+		t.Errorf("base64音频应补全为data URL，得到 %s", parts[0].InputAudio.Data)
+	}
+	if parts[1].InputAudio.Data != "https://example.com/a.mp3" {
+		t.Errorf("音频URL不应改变，得到 %s", parts[1].InputAudio.Data)
+	}
+	if req.Messages[0].Content.([]types.MessageContent)[0].InputAudio.Data != "UklGRg==" {
+		t.Error("原请求不应被修改")
+	}
+
+	req.Messages[0].Content = []types.MessageContent{{Type: types.MessageContentTypeFile, File: &types.FileContent{FileID: "file-1"}}}
+	var modalityErr *types.UnsupportedModalityError
+	if _, err := prepareRequest(req); !errors.As(err, &modalityErr) || modalityErr.Provider != "alicloud" {
+		t.Errorf("期望 UnsupportedModalityError，得到 %v", err)
+	}
+
+	req.Messages[0].Content = []types.MessageContent{types.NewVideoFramesContent()}
+	if _, err := prepareRequest(req); err == nil {
+		t.Error("没有帧的视频应返回错误")
+	}
+}
+
+func TestAudioOutputRequiresStream(t *testing.T) {
+	provider, err := NewAliCloudProvider(&AliCloudConfig{APIKey: "test"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := &types.ChatCompletionRequest{
+		Model:      "qwen-omni-turbo",
+		Messages:   []types.ChatCompletionMessage{types.NewTextMessage(types.RoleUser, "你好")},
+		Modalities: []string{types.ModalityText, types.ModalityAudio},
+		Audio:      &types.AudioOutputOptions{Voice: "Cherry", Format: "wav"},
+	}
+	if _, err := provider.CreateChatCompletion(context.Background(), req); err == nil {
+		t.Error("非流式调用请求音频输出时应返回错误")
+	}
+}
+
+func TestPrepareRequestReasoning(t *testing.T) {
+	req := (&types.ChatCompletionRequest{Model: "qwen-plus"}).WithReasoning(types.Reasoning{BudgetTokens: 2048})
+	prepared, err := prepareRequest(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !prepared.IsThinkingEnabled() || prepared.GetThinkingBudget() != 2048 || prepared.Reasoning != nil {
+		t.Errorf("期望转换为 enable_thinking 和 thinking_budget，得到 %+v", prepared)
+	}
+
+	req = (&types.ChatCompletionRequest{Model: "qwen-plus"}).WithReasoning(types.Reasoning{Enabled: types.ToPtr(false)})
+	if prepared, err := prepareRequest(req); err != nil || prepared.EnableThinking == nil || *prepared.EnableThinking {
+		t.Errorf("期望 enable_thinking 为false，得到 %v", err)
+	}
+
+	req = (&types.ChatCompletionRequest{Model: "qwen-plus"}).WithReasoning(types.Reasoning{Effort: types.ReasoningEffortLow})
+	var reasoningErr *types.ReasoningError
+	if _, err := prepareRequest(req); !errors.As(err, &reasoningErr) {
+		t.Errorf("期望 ReasoningError，得到 %v", err)
+	}
+}
@@ -0,0 +1,47 @@
+package alicloud
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/yu1ec/go-anyllm/providers"
+	"github.com/yu1ec/go-anyllm/types"
+)
+
+// embeddingBatchSizes DashScope text-embedding 模型单次请求最多的输入数量
+var embeddingBatchSizes = map[string]int{
+	"text-embedding-v1": 25,
+	"text-embedding-v2": 25,
+}
+
+// defaultEmbeddingBatchSize text-embedding-v3 及之后的模型单次最多10条
+const defaultEmbeddingBatchSize = 10
+
+// CreateEmbeddings 实现EmbeddingProvider接口
+// 兼容模式只支持float编码，请求base64时会改为float，返回的向量相同
+func (p *AliCloudProvider) CreateEmbeddings(ctx context.Context, req *types.EmbeddingRequest) (*types.EmbeddingResponse, error) {
+	batchSize, ok := embeddingBatchSizes[req.Model]
+	if !ok {
+		batchSize = defaultEmbeddingBatchSize
+	}
+
+	floatReq := *req
+	floatReq.EncodingFormat = types.EmbeddingEncodingFloat
+	return providers.BatchEmbeddings(ctx, &floatReq, batchSize, p.createEmbeddings)
+}
+
+// createEmbeddings 发送单次向量化请求
+func (p *AliCloudProvider) createEmbeddings(ctx context.Context, req *types.EmbeddingRequest) (*types.EmbeddingResponse, error) {
+	respBody, err := p.post(ctx, "/embeddings", req)
+	if err != nil {
+		return nil, err
+	}
+	defer respBody.Close()
+
+	var resp types.EmbeddingResponse
+	if err := json.NewDecoder(respBody).Decode(&resp); err != nil {
+		return nil, fmt.Errorf("alicloud: decode embeddings: %w", err)
+	}
+	return &resp, nil
+}
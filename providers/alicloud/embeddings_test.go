@@ -0,0 +1,52 @@
+package alicloud
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/yu1ec/go-anyllm/types"
+)
+
+func TestCreateEmbeddingsBatches(t *testing.T) {
+	var requests []types.EmbeddingRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req types.EmbeddingRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		requests = append(requests, req)
+		resp := types.EmbeddingResponse{Object: "list", Model: req.Model, Usage: &types.EmbeddingUsage{TotalTokens: len(req.Input)}}
+		for i := range req.Input {
+			resp.Data = append(resp.Data, types.Embedding{Object: "embedding", Index: i, Embedding: []float32{1}})
+		}
+		json.NewEncoder(w).Encode(resp)
+	}))
+	defer server.Close()
+
+	provider, err := NewAliCloudProvider(&AliCloudConfig{APIKey: "test", BaseURL: server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	input := make([]string, 23)
+	for i := range input {
+		input[i] = "text"
+	}
+	resp, err := provider.CreateEmbeddings(context.Background(), &types.EmbeddingRequest{
+		Model:          "text-embedding-v3",
+		Input:          input,
+		EncodingFormat: types.EmbeddingEncodingBase64,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(requests) != 3 || len(requests[2].Input) != 3 {
+		t.Fatalf("text-embedding-v3 应按10条分批，得到 %d 批", len(requests))
+	}
+	if requests[0].EncodingFormat != types.EmbeddingEncodingFloat {
+		t.Errorf("兼容模式应使用float编码，得到 %q", requests[0].EncodingFormat)
+	}
+	if len(resp.Data) != 23 || resp.Data[22].Index != 22 || resp.Usage.TotalTokens != 23 {
+		t.Errorf("合并结果错误: %d %+v", len(resp.Data), resp.Usage)
+	}
+}
@@ -0,0 +1,29 @@
+package alicloud
+
+import (
+	"github.com/yu1ec/go-anyllm/types"
+)
+
+// applyReasoning 把推理控制转换为 enable_thinking 和 thinking_budget
+// 通义千问可以开关思考并设置预算，不支持推理强度
+func applyReasoning(req *types.ChatCompletionRequest) error {
+	reasoning, err := req.ResolveReasoning()
+	if err != nil || reasoning == nil {
+		return err
+	}
+	if reasoning.Effort != "" {
+		return &types.ReasoningError{Provider: "alicloud", Model: req.Model, Reason: "reasoning effort is not supported, use a reasoning budget instead"}
+	}
+
+	req.Reasoning = nil
+	req.ReasoningEffort = ""
+	req.EnableThinking = reasoning.Enabled
+	if reasoning.Enabled == nil && reasoning.IsEnabled() {
+		req.EnableThinking = types.ToPtr(true)
+	}
+	req.ThinkingBudget = nil
+	if reasoning.BudgetTokens > 0 {
+		req.ThinkingBudget = types.ToPtr(reasoning.BudgetTokens)
+	}
+	return nil
+}
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/yu1ec/go-anyllm/internal"
@@ -35,6 +36,9 @@ type DeepSeekConfig struct {
 	BaseURL      string
 	Timeout      int
 	ExtraHeaders map[string]string
+
+	// ContentPolicy DeepSeek只接受纯文本，多模态内容按该策略转换，默认用占位符替换非文本内容
+	ContentPolicy types.FlattenPolicy
 }
 
 // GetAPIKey 实现ProviderConfig接口
@@ -90,6 +94,11 @@ func NewDeepSeekProvider(config providers.ProviderConfig) (*DeepSeekProvider, er
 	return provider, nil
 }
 
+// SetContentPolicy 设置多模态内容转换为纯文本的策略
+func (p *DeepSeekProvider) SetContentPolicy(policy types.FlattenPolicy) {
+	p.config.ContentPolicy = policy
+}
+
 // GetName 实现Provider接口
 func (p *DeepSeekProvider) GetName() string {
 	return "deepseek"
@@ -123,7 +132,10 @@ func (p *DeepSeekProvider) SetupHeaders(headers map[string]string) {
 // CreateChatCompletion 实现Provider接口
 func (p *DeepSeekProvider) CreateChatCompletion(ctx context.Context, req *types.ChatCompletionRequest) (*types.ChatCompletionResponse, error) {
 	// 转换为DeepSeek内部请求格式
-	deepseekReq := p.convertToDeepSeekRequest(req)
+	deepseekReq, err := p.convertToDeepSeekRequest(req)
+	if err != nil {
+		return nil, err
+	}
 	deepseekReq.Stream = false
 
 	// 发送请求
@@ -152,25 +164,45 @@ func (p *DeepSeekProvider) CreateChatCompletion(ctx context.Context, req *types.
 // CreateChatCompletionStream 实现Provider接口
 func (p *DeepSeekProvider) CreateChatCompletionStream(ctx context.Context, req *types.ChatCompletionRequest) (io.ReadCloser, error) {
 	// 转换为DeepSeek内部请求格式
-	deepseekReq := p.convertToDeepSeekRequest(req)
+	deepseekReq, err := p.convertToDeepSeekRequest(req)
+	if err != nil {
+		return nil, err
+	}
 	deepseekReq.Stream = true
 
 	// 发送请求
 	return p.doRequest(ctx, deepseekReq)
 }
 
+// ListModels 实现ModelLister接口
+func (p *DeepSeekProvider) ListModels(ctx context.Context) ([]types.Model, error) {
+	respBody, err := p.send(ctx, http.MethodGet, "/models", nil)
+	if err != nil {
+		return nil, err
+	}
+	defer respBody.Close()
+
+	var list types.ModelList
+	if err := json.NewDecoder(respBody).Decode(&list); err != nil {
+		return nil, fmt.Errorf("deepseek: decode models: %w", err)
+	}
+	return list.Data, nil
+}
+
 // doRequest 发送HTTP请求
 func (p *DeepSeekProvider) doRequest(ctx context.Context, req *request.ChatCompletionsRequest) (io.ReadCloser, error) {
-	url := fmt.Sprintf("%s/chat/completions", p.GetBaseURL())
-
 	// 序列化请求体
 	body, err := json.Marshal(req)
 	if err != nil {
 		return nil, err
 	}
+	return p.send(ctx, http.MethodPost, "/chat/completions", bytes.NewReader(body))
+}
 
+// send 发送HTTP请求，返回成功响应的响应体
+func (p *DeepSeekProvider) send(ctx context.Context, method, path string, body io.Reader) (io.ReadCloser, error) {
 	// 创建HTTP请求
-	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	httpReq, err := http.NewRequestWithContext(ctx, method, p.GetBaseURL()+path, body)
 	if err != nil {
 		return nil, err
 	}
@@ -196,8 +228,34 @@ func (p *DeepSeekProvider) doRequest(ctx context.Context, req *request.ChatCompl
 	return resp.Body, nil
 }
 
+// checkReasoning 检查推理控制是否与模型一致
+// DeepSeek由模型决定是否推理：deepseek-reasoner 总是推理，deepseek-chat 不推理，都不能调整强度或预算
+func (p *DeepSeekProvider) checkReasoning(req *types.ChatCompletionRequest) error {
+	reasoning, err := req.ResolveReasoning()
+	if err != nil || reasoning == nil {
+		return err
+	}
+
+	reasoner := strings.HasPrefix(req.Model, "deepseek-reasoner")
+	switch {
+	case reasoning.Effort != "":
+		return &types.ReasoningError{Provider: p.GetName(), Model: req.Model, Reason: "reasoning effort is not supported"}
+	case reasoning.BudgetTokens > 0:
+		return &types.ReasoningError{Provider: p.GetName(), Model: req.Model, Reason: "reasoning budget is not supported"}
+	case reasoner && reasoning.IsDisabled():
+		return &types.ReasoningError{Provider: p.GetName(), Model: req.Model, Reason: "reasoning cannot be disabled, use deepseek-chat instead"}
+	case !reasoner && reasoning.IsEnabled():
+		return &types.ReasoningError{Provider: p.GetName(), Model: req.Model, Reason: "reasoning is not supported, use deepseek-reasoner instead"}
+	}
+	return nil
+}
+
 // convertToDeepSeekRequest 转换为DeepSeek请求格式
-func (p *DeepSeekProvider) convertToDeepSeekRequest(req *types.ChatCompletionRequest) *request.ChatCompletionsRequest {
+func (p *DeepSeekProvider) convertToDeepSeekRequest(req *types.ChatCompletionRequest) (*request.ChatCompletionsRequest, error) {
+	if err := p.checkReasoning(req); err != nil {
+		return nil, err
+	}
+
 	deepseekReq := &request.ChatCompletionsRequest{
 		Model:            req.Model,
 		Stream:           req.Stream,
@@ -219,15 +277,20 @@ func (p *DeepSeekProvider) convertToDeepSeekRequest(req *types.ChatCompletionReq
 		deepseekReq.PresencePenalty = int(*req.PresencePenalty)
 	}
 
-	// 转换消息
-	for _, msg := range req.Messages {
-		// 获取消息内容的字符串表示
-		contentStr := getContentAsString(msg.Content)
+	// 转换消息，DeepSeek只接受纯文本内容，历史中有思考内容时会返回400
+	flattener := types.Flattener{Policy: p.config.ContentPolicy, Provider: p.GetName()}
+	messages := types.ApplyReasoningHistory(req.Messages, req.ReasoningHistory, types.ReasoningHistoryStrip)
+	for _, msg := range messages {
+		contentStr, err := flattener.Flatten(msg.Content)
+		if err != nil {
+			return nil, err
+		}
 
 		deepseekMsg := &request.Message{
-			Role:    msg.Role,
-			Content: contentStr,
-			Name:    msg.Name,
+			Role:             msg.Role,
+			Content:          contentStr,
+			Name:             msg.Name,
+			ReasoningContent: msg.ReasoningContent,
 		}
 		if msg.ToolCallID != "" {
 			deepseekMsg.ToolCallId = msg.ToolCallID
@@ -276,7 +339,7 @@ func (p *DeepSeekProvider) convertToDeepSeekRequest(req *types.ChatCompletionReq
 		deepseekReq.TopLogprobs = req.TopLogprobs
 	}
 
-	return deepseekReq
+	return deepseekReq, nil
 }
 
 // convertToOpenAIResponse 转换为OpenAI响应格式
@@ -308,8 +371,9 @@ func (p *DeepSeekProvider) convertToOpenAIResponse(resp *response.ChatCompletion
 			if len(choice.Message.ToolCalls) > 0 {
 				for _, tc := range choice.Message.ToolCalls {
 					openaiChoice.Message.ToolCalls = append(openaiChoice.Message.ToolCalls, types.ToolCall{
-						ID:   tc.Id,
-						Type: tc.Type,
+						Index: tc.Index,
+						ID:    tc.Id,
+						Type:  tc.Type,
 						Function: types.ResponseToolFunction{
 							Name:      tc.Function.Name,
 							Arguments: tc.Function.Arguments,
@@ -330,8 +394,9 @@ func (p *DeepSeekProvider) convertToOpenAIResponse(resp *response.ChatCompletion
 			if len(choice.Delta.ToolCalls) > 0 {
 				for _, tc := range choice.Delta.ToolCalls {
 					openaiChoice.Delta.ToolCalls = append(openaiChoice.Delta.ToolCalls, types.ToolCall{
-						ID:   tc.Id,
-						Type: tc.Type,
+						Index: tc.Index,
+						ID:    tc.Id,
+						Type:  tc.Type,
 						Function: types.ResponseToolFunction{
 							Name:      tc.Function.Name,
 							Arguments: tc.Function.Arguments,
@@ -363,21 +428,3 @@ func (p *DeepSeekProvider) convertToOpenAIResponse(resp *response.ChatCompletion
 
 	return openaiResp
 }
-
-// getContentAsString 获取内容的字符串表示
-func getContentAsString(content interface{}) string {
-	switch c := content.(type) {
-	case string:
-		return c
-	case []types.MessageContent:
-		// 只返回文本内容，忽略图像
-		for _, part := range c {
-			if part.Type == types.MessageContentTypeText {
-				return part.Text
-			}
-		}
-		return ""
-	default:
-		return ""
-	}
-}
@@ -0,0 +1,97 @@
+package deepseek
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/yu1ec/go-anyllm/types"
+)
+
+func TestConvertMultiModalContent(t *testing.T) {
+	provider, err := NewDeepSeekProvider(&DeepSeekConfig{APIKey: "test"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := &types.ChatCompletionRequest{
+		Model: "deepseek-chat",
+		Messages: []types.ChatCompletionMessage{
+			types.NewMultiModalMessage(types.RoleUser, []types.MessageContent{
+				types.NewTextContent("这张图里有什么？"),
+				types.NewImageContent("data:image/png;base64,iVBORw0KGgo="),
+				types.NewTextContent("请简要回答"),
+			}),
+		},
+	}
+
+	deepseekReq, err := provider.convertToDeepSeekRequest(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := deepseekReq.Messages[0].Content; got != "这张图里有什么？\n[image]\n请简要回答" {
+		t.Errorf("默认应使用占位符，得到 %q", got)
+	}
+
+	provider.SetContentPolicy(types.FlattenStrict)
+	var modalityErr *types.UnsupportedModalityError
+	if _, err := provider.convertToDeepSeekRequest(req); !errors.As(err, &modalityErr) || modalityErr.Provider != "deepseek" {
+		t.Errorf("严格模式应返回 UnsupportedModalityError，得到 %v", err)
+	}
+}
+
+func TestCheckReasoning(t *testing.T) {
+	provider, err := NewDeepSeekProvider(&DeepSeekConfig{APIKey: "test"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		model     string
+		reasoning types.Reasoning
+		valid     bool
+	}{
+		{"deepseek-reasoner", types.Reasoning{Enabled: types.ToPtr(true)}, true},
+		{"deepseek-chat", types.Reasoning{Enabled: types.ToPtr(false)}, true},
+		{"deepseek-chat", types.Reasoning{Enabled: types.ToPtr(true)}, false},
+		{"deepseek-reasoner", types.Reasoning{Enabled: types.ToPtr(false)}, false},
+		{"deepseek-reasoner", types.Reasoning{Effort: types.ReasoningEffortHigh}, false},
+		{"deepseek-reasoner", types.Reasoning{BudgetTokens: 1024}, false},
+	}
+	for _, tt := range tests {
+		req := (&types.ChatCompletionRequest{Model: tt.model}).WithReasoning(tt.reasoning)
+		_, err := provider.convertToDeepSeekRequest(req)
+		var reasoningErr *types.ReasoningError
+		if tt.valid && err != nil {
+			t.Errorf("%s %+v 期望通过，得到 %v", tt.model, tt.reasoning, err)
+		}
+		if !tt.valid && !errors.As(err, &reasoningErr) {
+			t.Errorf("%s %+v 期望 ReasoningError，得到 %v", tt.model, tt.reasoning, err)
+		}
+	}
+}
+
+func TestReasoningHistory(t *testing.T) {
+	provider, err := NewDeepSeekProvider(&DeepSeekConfig{APIKey: "test"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := &types.ChatCompletionRequest{
+		Model: "deepseek-reasoner",
+		Messages: []types.ChatCompletionMessage{
+			types.NewTextMessage(types.RoleUser, "1+1=?"),
+			{Role: types.RoleAssistant, Content: "2", ReasoningContent: "1+1=2"},
+			types.NewTextMessage(types.RoleUser, "再加1呢？"),
+		},
+	}
+
+	deepseekReq, err := provider.convertToDeepSeekRequest(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if deepseekReq.Messages[1].ReasoningContent != "" {
+		t.Error("默认应移除历史中的思考内容")
+	}
+
+	req.ReasoningHistory = types.ReasoningHistoryKeep
+	if deepseekReq, err = provider.convertToDeepSeekRequest(req); err != nil || deepseekReq.Messages[1].ReasoningContent != "1+1=2" {
+		t.Errorf("指定保留时应发送思考内容，得到 %v", err)
+	}
+}
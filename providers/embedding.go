@@ -0,0 +1,105 @@
+package providers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/yu1ec/go-anyllm/tokenizer"
+	"github.com/yu1ec/go-anyllm/types"
+)
+
+// EmbeddingProvider 支持向量化的服务商
+type EmbeddingProvider interface {
+	// CreateEmbeddings 创建向量，超出服务商单次请求限制的输入会被自动分批
+	CreateEmbeddings(ctx context.Context, req *types.EmbeddingRequest) (*types.EmbeddingResponse, error)
+}
+
+// EmbeddingBatchLimits 向量化请求单次的限制，0表示不限制
+type EmbeddingBatchLimits struct {
+	MaxInputs int // 每批最多的输入数量
+	MaxTokens int // 每批输入合计的最大token数
+
+	// CountTokens 计算输入的token数，为nil时使用 tokenizer.DefaultEstimator
+	CountTokens func(text string) int
+}
+
+// BatchEmbeddings 按 maxInputs 把输入分批调用 create，并合并结果
+// maxInputs 小于等于0时不分批。合并后的 Index 对应原始输入的位置，Usage 为各批的合计
+func BatchEmbeddings(ctx context.Context, req *types.EmbeddingRequest, maxInputs int, create func(ctx context.Context, req *types.EmbeddingRequest) (*types.EmbeddingResponse, error)) (*types.EmbeddingResponse, error) {
+	return BatchEmbeddingsWithLimits(ctx, req, EmbeddingBatchLimits{MaxInputs: maxInputs}, create)
+}
+
+// BatchEmbeddingsWithLimits 按输入数量和token数把输入分批调用 create，并合并结果
+// 单个输入超过token限制时单独成批，由服务商决定是否接受
+func BatchEmbeddingsWithLimits(ctx context.Context, req *types.EmbeddingRequest, limits EmbeddingBatchLimits, create func(ctx context.Context, req *types.EmbeddingRequest) (*types.EmbeddingResponse, error)) (*types.EmbeddingResponse, error) {
+	if len(req.Input) == 0 {
+		return nil, fmt.Errorf("providers: embedding request has no input")
+	}
+
+	batches := splitEmbeddingInputs(req, limits)
+	if len(batches) == 1 {
+		resp, err := create(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		if len(resp.Data) != len(req.Input) {
+			return nil, fmt.Errorf("providers: embedding request returned %d vectors for %d inputs", len(resp.Data), len(req.Input))
+		}
+		return resp, nil
+	}
+
+	merged := &types.EmbeddingResponse{Object: "list", Data: make([]types.Embedding, 0, len(req.Input))}
+	start := 0
+	for _, end := range batches {
+		batch := *req
+		batch.Input = req.Input[start:end]
+		resp, err := create(ctx, &batch)
+		if err != nil {
+			return nil, fmt.Errorf("providers: embedding batch starting at input %d: %w", start, err)
+		}
+		if len(resp.Data) != len(batch.Input) {
+			return nil, fmt.Errorf("providers: embedding batch starting at input %d returned %d vectors for %d inputs", start, len(resp.Data), len(batch.Input))
+		}
+
+		merged.Model = resp.Model
+		for _, item := range resp.Data {
+			item.Index += start
+			merged.Data = append(merged.Data, item)
+		}
+		if resp.Usage != nil {
+			if merged.Usage == nil {
+				merged.Usage = &types.EmbeddingUsage{}
+			}
+			merged.Usage.PromptTokens += resp.Usage.PromptTokens
+			merged.Usage.TotalTokens += resp.Usage.TotalTokens
+		}
+		start = end
+	}
+	return merged, nil
+}
+
+// splitEmbeddingInputs 返回每批输入的结束位置（不包含）
+func splitEmbeddingInputs(req *types.EmbeddingRequest, limits EmbeddingBatchLimits) []int {
+	count := limits.CountTokens
+	if count == nil && limits.MaxTokens > 0 {
+		count = tokenizer.DefaultEstimator.Count
+	}
+
+	var ends []int
+	inputs, tokens := 0, 0
+	for i, input := range req.Input {
+		inputTokens := 0
+		if limits.MaxTokens > 0 {
+			inputTokens = count(input)
+		}
+		fullInputs := limits.MaxInputs > 0 && inputs >= limits.MaxInputs
+		fullTokens := limits.MaxTokens > 0 && inputs > 0 && tokens+inputTokens > limits.MaxTokens
+		if fullInputs || fullTokens {
+			ends = append(ends, i)
+			inputs, tokens = 0, 0
+		}
+		inputs++
+		tokens += inputTokens
+	}
+	return append(ends, len(req.Input))
+}
@@ -0,0 +1,89 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/yu1ec/go-anyllm/types"
+)
+
+func TestBatchEmbeddings(t *testing.T) {
+	var batches [][]string
+	create := func(ctx context.Context, req *types.EmbeddingRequest) (*types.EmbeddingResponse, error) {
+		batches = append(batches, req.Input)
+		resp := &types.EmbeddingResponse{Model: req.Model, Usage: &types.EmbeddingUsage{PromptTokens: len(req.Input), TotalTokens: len(req.Input)}}
+		for i := range req.Input {
+			resp.Data = append(resp.Data, types.Embedding{Index: i, Embedding: []float32{float32(len(batches))}})
+		}
+		return resp, nil
+	}
+
+	req := &types.EmbeddingRequest{Model: "m", Input: []string{"a", "b", "c", "d", "e"}}
+	resp, err := BatchEmbeddings(context.Background(), req, 2, create)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(batches) != 3 || len(batches[2]) != 1 {
+		t.Fatalf("期望分为3批，得到 %v", batches)
+	}
+	if len(resp.Data) != 5 || resp.Data[4].Index != 4 || resp.Data[4].Embedding[0] != 3 {
+		t.Errorf("合并结果错误: %+v", resp.Data)
+	}
+	if resp.Usage.TotalTokens != 5 || resp.Model != "m" {
+		t.Errorf("用量合计错误: %+v", resp.Usage)
+	}
+
+	batches = nil
+	if _, err := BatchEmbeddings(context.Background(), req, 0, create); err != nil || len(batches) != 1 {
+		t.Errorf("不限制数量时不应分批: %v %v", batches, err)
+	}
+
+	failing := func(ctx context.Context, req *types.EmbeddingRequest) (*types.EmbeddingResponse, error) {
+		return nil, errors.New("boom")
+	}
+	if _, err := BatchEmbeddings(context.Background(), req, 2, failing); err == nil {
+		t.Error("批次失败时应返回错误")
+	}
+	if _, err := BatchEmbeddings(context.Background(), &types.EmbeddingRequest{Model: "m"}, 2, create); err == nil {
+		t.Error("没有输入时应返回错误")
+	}
+}
+
+func TestBatchEmbeddingsByTokens(t *testing.T) {
+	var batches [][]string
+	create := func(ctx context.Context, req *types.EmbeddingRequest) (*types.EmbeddingResponse, error) {
+		batches = append(batches, req.Input)
+		resp := &types.EmbeddingResponse{Model: req.Model}
+		for i := range req.Input {
+			resp.Data = append(resp.Data, types.Embedding{Index: i})
+		}
+		return resp, nil
+	}
+
+	// 每个字符算1个token，每批最多5个token
+	limits := EmbeddingBatchLimits{MaxInputs: 10, MaxTokens: 5, CountTokens: func(text string) int { return len(text) }}
+	req := &types.EmbeddingRequest{Model: "m", Input: []string{"aa", "bb", "cc", "dddddddd", "e"}}
+	resp, err := BatchEmbeddingsWithLimits(context.Background(), req, limits, create)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][]string{{"aa", "bb"}, {"cc"}, {"dddddddd"}, {"e"}}
+	if !reflect.DeepEqual(batches, want) {
+		t.Errorf("期望按token分批 %v，得到 %v", want, batches)
+	}
+	if len(resp.Data) != 5 || resp.Data[4].Index != 4 {
+		t.Errorf("合并结果错误: %+v", resp.Data)
+	}
+}
+
+func TestBatchEmbeddingsSingleBatchValidatesCount(t *testing.T) {
+	short := func(ctx context.Context, req *types.EmbeddingRequest) (*types.EmbeddingResponse, error) {
+		return &types.EmbeddingResponse{Data: []types.Embedding{{Index: 0}}}, nil
+	}
+	req := &types.EmbeddingRequest{Model: "m", Input: []string{"a", "b"}}
+	if _, err := BatchEmbeddings(context.Background(), req, 0, short); err == nil {
+		t.Error("返回的向量数量与输入不一致时应返回错误")
+	}
+}
@@ -24,6 +24,9 @@ func (f *DefaultProviderFactory) CreateProvider(providerType ProviderType, confi
 	case ProviderAliCloud:
 		// 动态创建阿里云服务商
 		return createAliCloudProvider(config)
+	case ProviderOllama:
+		// 动态创建Ollama服务商
+		return createOllamaProvider(config)
 	default:
 		return nil, fmt.Errorf("unsupported provider type: %s", providerType)
 	}
@@ -35,6 +38,7 @@ func (f *DefaultProviderFactory) SupportedProviders() []ProviderType {
 		ProviderDeepSeek,
 		ProviderOpenAI,
 		ProviderAliCloud,
+		ProviderOllama,
 		ProviderBaidu,
 		ProviderTencent,
 	}
@@ -94,6 +98,7 @@ var (
 	createDeepSeekProvider func(config ProviderConfig) (Provider, error)
 	createOpenAIProvider   func(config ProviderConfig) (Provider, error)
 	createAliCloudProvider func(config ProviderConfig) (Provider, error)
+	createOllamaProvider   func(config ProviderConfig) (Provider, error)
 )
 
 // RegisterDeepSeekProvider 注册DeepSeek服务商创建函数
@@ -110,3 +115,8 @@ func RegisterOpenAIProvider(creator func(config ProviderConfig) (Provider, error
 func RegisterAliCloudProvider(creator func(config ProviderConfig) (Provider, error)) {
 	createAliCloudProvider = creator
 }
+
+// RegisterOllamaProvider 注册Ollama服务商创建函数
+func RegisterOllamaProvider(creator func(config ProviderConfig) (Provider, error)) {
+	createOllamaProvider = creator
+}
@@ -0,0 +1,290 @@
+package ollama
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/yu1ec/go-anyllm/providers"
+	"github.com/yu1ec/go-anyllm/types"
+)
+
+func init() {
+	// 注册Ollama服务商创建函数
+	providers.RegisterOllamaProvider(func(config providers.ProviderConfig) (providers.Provider, error) {
+		return NewOllamaProvider(config)
+	})
+}
+
+// OllamaProvider Ollama服务商实现
+// 聊天使用OpenAI兼容接口（/v1/chat/completions），向量化使用原生接口（/api/embed）
+type OllamaProvider struct {
+	config     *OllamaConfig
+	httpClient *http.Client
+}
+
+// OllamaConfig Ollama配置
+type OllamaConfig struct {
+	APIKey       string // 本地服务不需要，通过反向代理鉴权时使用
+	BaseURL      string // 服务地址，不包含 /v1
+	Timeout      int
+	ExtraHeaders map[string]string
+}
+
+// GetAPIKey 实现ProviderConfig接口
+func (c *OllamaConfig) GetAPIKey() string {
+	return c.APIKey
+}
+
+// GetBaseURL 实现ProviderConfig接口
+func (c *OllamaConfig) GetBaseURL() string {
+	if c.BaseURL == "" {
+		return "http://localhost:11434"
+	}
+	return c.BaseURL
+}
+
+// GetTimeout 实现ProviderConfig接口
+func (c *OllamaConfig) GetTimeout() int {
+	if c.Timeout == 0 {
+		return 300
+	}
+	return c.Timeout
+}
+
+// GetExtraHeaders 实现ProviderConfig接口
+func (c *OllamaConfig) GetExtraHeaders() map[string]string {
+	return c.ExtraHeaders
+}
+
+// NewOllamaProvider 创建Ollama服务商
+func NewOllamaProvider(config providers.ProviderConfig) (*OllamaProvider, error) {
+	ollamaConfig, ok := config.(*OllamaConfig)
+	if !ok {
+		// 尝试从通用配置创建
+		ollamaConfig = &OllamaConfig{
+			APIKey:       config.GetAPIKey(),
+			BaseURL:      config.GetBaseURL(),
+			Timeout:      config.GetTimeout(),
+			ExtraHeaders: config.GetExtraHeaders(),
+		}
+	}
+
+	provider := &OllamaProvider{
+		config: ollamaConfig,
+		httpClient: &http.Client{
+			Timeout: time.Duration(ollamaConfig.GetTimeout()) * time.Second,
+		},
+	}
+
+	if err := provider.ValidateConfig(); err != nil {
+		return nil, err
+	}
+
+	return provider, nil
+}
+
+// GetName 实现Provider接口
+func (p *OllamaProvider) GetName() string {
+	return "ollama"
+}
+
+// GetBaseURL 实现Provider接口
+func (p *OllamaProvider) GetBaseURL() string {
+	return p.config.GetBaseURL()
+}
+
+// ValidateConfig 实现Provider接口，Ollama不要求API Key
+func (p *OllamaProvider) ValidateConfig() error {
+	return nil
+}
+
+// SetupHeaders 实现Provider接口
+func (p *OllamaProvider) SetupHeaders(headers map[string]string) {
+	if apiKey := p.config.GetAPIKey(); apiKey != "" {
+		headers["Authorization"] = "Bearer " + apiKey
+	}
+	headers["Content-Type"] = "application/json"
+	headers["Accept"] = "application/json"
+
+	// 添加额外的头部
+	for k, v := range p.config.GetExtraHeaders() {
+		headers[k] = v
+	}
+}
+
+// CreateChatCompletion 实现Provider接口
+func (p *OllamaProvider) CreateChatCompletion(ctx context.Context, req *types.ChatCompletionRequest) (*types.ChatCompletionResponse, error) {
+	req, err := prepareRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	req.Stream = false
+
+	respBody, err := p.post(ctx, "/v1/chat/completions", req)
+	if err != nil {
+		return nil, err
+	}
+	defer respBody.Close()
+
+	var resp types.ChatCompletionResponse
+	if err := json.NewDecoder(respBody).Decode(&resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// CreateChatCompletionStream 实现Provider接口
+func (p *OllamaProvider) CreateChatCompletionStream(ctx context.Context, req *types.ChatCompletionRequest) (io.ReadCloser, error) {
+	req, err := prepareRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	req.Stream = true
+	return p.post(ctx, "/v1/chat/completions", req)
+}
+
+// prepareRequest 把推理控制转换为 reasoning_effort，并移除历史中的思考内容，返回请求的副本
+// 兼容接口用 "none" 关闭思考，不支持思考预算
+func prepareRequest(req *types.ChatCompletionRequest) (*types.ChatCompletionRequest, error) {
+	reasoning, err := req.ResolveReasoning()
+	if err != nil {
+		return nil, err
+	}
+	prepared := *req
+	prepared.Messages = types.ApplyReasoningHistory(req.Messages, req.ReasoningHistory, types.ReasoningHistoryStrip)
+	if reasoning == nil {
+		return &prepared, nil
+	}
+	if reasoning.BudgetTokens > 0 {
+		return nil, &types.ReasoningError{Provider: "ollama", Model: req.Model, Reason: "reasoning budget is not supported, use reasoning effort instead"}
+	}
+
+	prepared.Reasoning = nil
+	prepared.EnableThinking = nil
+	prepared.ThinkingBudget = nil
+	prepared.ReasoningEffort = reasoning.Effort
+	if reasoning.IsDisabled() {
+		prepared.ReasoningEffort = "none"
+	}
+	return &prepared, nil
+}
+
+// ListModels 实现ModelLister接口
+func (p *OllamaProvider) ListModels(ctx context.Context) ([]types.Model, error) {
+	respBody, err := p.send(ctx, http.MethodGet, "/v1/models", nil)
+	if err != nil {
+		return nil, err
+	}
+	defer respBody.Close()
+
+	var list types.ModelList
+	if err := json.NewDecoder(respBody).Decode(&list); err != nil {
+		return nil, fmt.Errorf("ollama: decode models: %w", err)
+	}
+	return list.Data, nil
+}
+
+// embedRequest /api/embed 请求
+type embedRequest struct {
+	Model      string   `json:"model"`
+	Input      []string `json:"input"`
+	Dimensions *int     `json:"dimensions,omitempty"`
+}
+
+// embedResponse /api/embed 响应
+type embedResponse struct {
+	Model           string      `json:"model"`
+	Embeddings      [][]float32 `json:"embeddings"`
+	PromptEvalCount int         `json:"prompt_eval_count"`
+}
+
+// CreateEmbeddings 实现EmbeddingProvider接口
+// Ollama没有单次请求的数量限制，也只返回浮点数向量，EncodingFormat 会被忽略
+func (p *OllamaProvider) CreateEmbeddings(ctx context.Context, req *types.EmbeddingRequest) (*types.EmbeddingResponse, error) {
+	return providers.BatchEmbeddings(ctx, req, 0, p.createEmbeddings)
+}
+
+// createEmbeddings 发送单次向量化请求并转换为OpenAI格式
+func (p *OllamaProvider) createEmbeddings(ctx context.Context, req *types.EmbeddingRequest) (*types.EmbeddingResponse, error) {
+	respBody, err := p.post(ctx, "/api/embed", &embedRequest{
+		Model:      req.Model,
+		Input:      req.Input,
+		Dimensions: req.Dimensions,
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer respBody.Close()
+
+	var embedResp embedResponse
+	if err := json.NewDecoder(respBody).Decode(&embedResp); err != nil {
+		return nil, fmt.Errorf("ollama: decode embeddings: %w", err)
+	}
+
+	resp := &types.EmbeddingResponse{
+		Object: "list",
+		Model:  embedResp.Model,
+		Data:   make([]types.Embedding, len(embedResp.Embeddings)),
+		Usage:  &types.EmbeddingUsage{PromptTokens: embedResp.PromptEvalCount, TotalTokens: embedResp.PromptEvalCount},
+	}
+	for i, vector := range embedResp.Embeddings {
+		resp.Data[i] = types.Embedding{Object: "embedding", Index: i, Embedding: vector}
+	}
+	return resp, nil
+}
+
+// post 发送JSON请求，返回成功响应的响应体
+func (p *OllamaProvider) post(ctx context.Context, path string, payload interface{}) (io.ReadCloser, error) {
+	// 序列化请求体
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	return p.send(ctx, http.MethodPost, path, bytes.NewReader(body))
+}
+
+// send 发送HTTP请求，返回成功响应的响应体
+func (p *OllamaProvider) send(ctx context.Context, method, path string, body io.Reader) (io.ReadCloser, error) {
+	// 创建HTTP请求
+	httpReq, err := http.NewRequestWithContext(ctx, method, p.GetBaseURL()+path, body)
+	if err != nil {
+		return nil, err
+	}
+
+	// 设置请求头
+	headers := make(map[string]string)
+	p.SetupHeaders(headers)
+	for k, v := range headers {
+		httpReq.Header.Set(k, v)
+	}
+
+	// 发送请求
+	resp, err := p.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+
+		// 原生接口的错误为 {"error": "..."}，兼容接口为 {"error": {"message": "..."}}
+		errorBody, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		var errorResp struct {
+			Error interface{} `json:"error"`
+		}
+		if json.Unmarshal(errorBody, &errorResp) == nil && errorResp.Error != nil {
+			if errorMap, ok := errorResp.Error.(map[string]interface{}); ok {
+				return nil, fmt.Errorf("ollama: HTTP %d - %v", resp.StatusCode, errorMap["message"])
+			}
+			return nil, fmt.Errorf("ollama: HTTP %d - %v", resp.StatusCode, errorResp.Error)
+		}
+		return nil, fmt.Errorf("ollama: HTTP %d", resp.StatusCode)
+	}
+
+	return resp.Body, nil
+}
@@ -0,0 +1,83 @@
+package ollama
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/yu1ec/go-anyllm/types"
+)
+
+func TestCreateEmbeddings(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/embed" {
+			http.NotFound(w, r)
+			return
+		}
+		var req embedRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		if req.Model == "missing" {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error":"model \"missing\" not found"}`))
+			return
+		}
+		resp := embedResponse{Model: req.Model, PromptEvalCount: 7}
+		for i := range req.Input {
+			resp.Embeddings = append(resp.Embeddings, []float32{float32(i), 0.5})
+		}
+		json.NewEncoder(w).Encode(resp)
+	}))
+	defer server.Close()
+
+	provider, err := NewOllamaProvider(&OllamaConfig{BaseURL: server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := provider.CreateEmbeddings(context.Background(), &types.EmbeddingRequest{Model: "nomic-embed-text", Input: []string{"你好", "世界"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Data) != 2 || resp.Data[1].Index != 1 || resp.Data[1].Embedding[0] != 1 || resp.Usage.PromptTokens != 7 {
+		t.Errorf("响应转换错误: %+v", resp)
+	}
+
+	_, err = provider.CreateEmbeddings(context.Background(), &types.EmbeddingRequest{Model: "missing", Input: []string{"x"}})
+	if err == nil || err.Error() != `ollama: HTTP 404 - model "missing" not found` {
+		t.Errorf("错误信息不正确: %v", err)
+	}
+}
+
+func TestReasoningEffort(t *testing.T) {
+	var body map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&body)
+		w.Write([]byte(`{"choices":[{"index":0,"message":{"role":"assistant","content":"4","reasoning":"2+2=4"}}]}`))
+	}))
+	defer server.Close()
+
+	provider, err := NewOllamaProvider(&OllamaConfig{BaseURL: server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := (&types.ChatCompletionRequest{
+		Model:    "qwen3",
+		Messages: []types.ChatCompletionMessage{types.NewTextMessage(types.RoleUser, "2+2=?")},
+	}).WithReasoning(types.Reasoning{Enabled: types.ToPtr(false)})
+	resp, err := provider.CreateChatCompletion(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if body["reasoning_effort"] != "none" {
+		t.Errorf("关闭推理时 reasoning_effort 应为none，得到 %v", body["reasoning_effort"])
+	}
+	if resp.Choices[0].Message.ReasoningContent != "2+2=4" {
+		t.Errorf("reasoning 应转换为 ReasoningContent，得到 %q", resp.Choices[0].Message.ReasoningContent)
+	}
+
+	req.Reasoning = &types.Reasoning{BudgetTokens: 100}
+	if _, err := provider.CreateChatCompletion(context.Background(), req); err == nil {
+		t.Error("思考预算应返回错误")
+	}
+}
@@ -0,0 +1,66 @@
+package openai
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/yu1ec/go-anyllm/types"
+)
+
+// audioFormats data URL中的MIME类型对应的OpenAI音频格式
+var audioFormats = map[string]string{
+	"audio/wav":   "wav",
+	"audio/x-wav": "wav",
+	"audio/wave":  "wav",
+	"audio/mpeg":  "mp3",
+	"audio/mp3":   "mp3",
+}
+
+// prepareRequest 校验内容类型并把内容、推理控制和历史中的思考内容转换为OpenAI的格式，返回请求的副本
+func prepareRequest(req *types.ChatCompletionRequest) (*types.ChatCompletionRequest, error) {
+	if req.HasModality(types.ModalityAudio) && req.Audio == nil {
+		return nil, fmt.Errorf("openai: audio output requires the audio options (voice and format)")
+	}
+
+	messages, err := types.MapContentParts(req.Messages, convertContentPart)
+	if err != nil {
+		return nil, err
+	}
+	prepared := *req
+	// OpenAI不接受历史消息中的思考内容
+	prepared.Messages = types.ApplyReasoningHistory(messages, req.ReasoningHistory, types.ReasoningHistoryStrip)
+	if err := applyReasoning(&prepared); err != nil {
+		return nil, err
+	}
+	return &prepared, nil
+}
+
+// convertContentPart OpenAI支持文本、图像、音频和文件内容
+// 音频只接受base64数据，data URL会被拆分为数据和格式
+func convertContentPart(part types.MessageContent) (types.MessageContent, error) {
+	switch part.Type {
+	case types.MessageContentTypeText, types.MessageContentTypeImageURL, types.MessageContentTypeFile:
+		return part, nil
+	case types.MessageContentTypeInputAudio:
+		if part.InputAudio == nil || part.InputAudio.Data == "" {
+			return part, fmt.Errorf("openai: input_audio content has no data")
+		}
+		audio := *part.InputAudio
+		if strings.HasPrefix(audio.Data, "http://") || strings.HasPrefix(audio.Data, "https://") {
+			return part, fmt.Errorf("openai: input_audio must be base64 encoded data, URLs are not supported")
+		}
+		if mimeType, data, ok := types.SplitDataURL(audio.Data); ok {
+			audio.Data = data
+			if audio.Format == "" {
+				audio.Format = audioFormats[mimeType]
+			}
+		}
+		if audio.Format == "" {
+			return part, fmt.Errorf("openai: input_audio requires a format (wav or mp3)")
+		}
+		part.InputAudio = &audio
+		return part, nil
+	default:
+		return part, &types.UnsupportedModalityError{Provider: "openai", Modality: part.Type}
+	}
+}
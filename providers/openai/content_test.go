@@ -0,0 +1,84 @@
+package openai
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/yu1ec/go-anyllm/types"
+)
+
+func TestPrepareRequestAudio(t *testing.T) {
+	req := &types.ChatCompletionRequest{
+		Model: "gpt-4o-audio-preview",
+		Messages: []types.ChatCompletionMessage{
+			types.NewMultiModalMessage(types.RoleUser, []types.MessageContent{
+				types.NewTextContent("转写这段音频"),
+				types.NewInputAudioContent("data:audio/mpeg;base64,SUQz", ""),
+			}),
+		},
+		Modalities: []string{types.ModalityText, types.ModalityAudio},
+		Audio:      &types.AudioOutputOptions{Voice: "alloy", Format: "wav"},
+	}
+
+	prepared, err := prepareRequest(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	audio := prepared.Messages[0].Content.([]types.MessageContent)[1].InputAudio
+	if audio.Data != "SUQz" || audio.Format != "mp3" {
+		t.Errorf("data URL应转换为base64数据和格式，得到 %+v", audio)
+	}
+	if req.Messages[0].Content.([]types.MessageContent)[1].InputAudio.Data != "data:audio/mpeg;base64,SUQz" {
+		t.Error("原请求不应被修改")
+	}
+
+	req.Audio = nil
+	if _, err := prepareRequest(req); err == nil {
+		t.Error("请求音频输出但没有音频参数时应返回错误")
+	}
+}
+
+func TestPrepareRequestUnsupported(t *testing.T) {
+	tests := []types.MessageContent{
+		types.NewVideoURLContent("https://example.com/a.mp4"),
+		types.NewVideoFramesContent("https://example.com/1.jpg"),
+	}
+	for _, part := range tests {
+		req := &types.ChatCompletionRequest{
+			Messages: []types.ChatCompletionMessage{types.NewMultiModalMessage(types.RoleUser, []types.MessageContent{part})},
+		}
+		var modalityErr *types.UnsupportedModalityError
+		if _, err := prepareRequest(req); !errors.As(err, &modalityErr) || modalityErr.Modality != part.Type {
+			t.Errorf("%s: 期望 UnsupportedModalityError，得到 %v", part.Type, err)
+		}
+	}
+
+	req := &types.ChatCompletionRequest{
+		Messages: []types.ChatCompletionMessage{types.NewMultiModalMessage(types.RoleUser, []types.MessageContent{
+			types.NewInputAudioContent("https://example.com/a.wav", "wav"),
+		})},
+	}
+	if _, err := prepareRequest(req); err == nil {
+		t.Error("音频URL应返回错误")
+	}
+}
+
+func TestPrepareRequestReasoning(t *testing.T) {
+	req := (&types.ChatCompletionRequest{Model: "o3-mini"}).WithReasoning(types.Reasoning{Effort: types.ReasoningEffortHigh})
+	prepared, err := prepareRequest(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if prepared.ReasoningEffort != types.ReasoningEffortHigh || prepared.Reasoning != nil {
+		t.Errorf("期望转换为 reasoning_effort，得到 %+v", prepared)
+	}
+	if req.Reasoning == nil {
+		t.Error("原请求不应被修改")
+	}
+
+	req = (&types.ChatCompletionRequest{Model: "o3-mini"}).WithReasoning(types.Reasoning{BudgetTokens: 1024})
+	var reasoningErr *types.ReasoningError
+	if _, err := prepareRequest(req); !errors.As(err, &reasoningErr) {
+		t.Errorf("期望 ReasoningError，得到 %v", err)
+	}
+}
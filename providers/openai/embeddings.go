@@ -0,0 +1,42 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/yu1ec/go-anyllm/providers"
+	"github.com/yu1ec/go-anyllm/tokenizer"
+	"github.com/yu1ec/go-anyllm/types"
+)
+
+// OpenAI /embeddings 单次请求的限制
+const (
+	maxEmbeddingInputs = 2048    // 最多的输入数量
+	maxEmbeddingTokens = 300_000 // 所有输入合计的最大token数
+)
+
+// CreateEmbeddings 实现EmbeddingProvider接口
+// token数使用 tokenizer.For 为模型注册的分词器计算，没有注册时为估算值
+func (p *OpenAIProvider) CreateEmbeddings(ctx context.Context, req *types.EmbeddingRequest) (*types.EmbeddingResponse, error) {
+	return providers.BatchEmbeddingsWithLimits(ctx, req, providers.EmbeddingBatchLimits{
+		MaxInputs:   maxEmbeddingInputs,
+		MaxTokens:   maxEmbeddingTokens,
+		CountTokens: tokenizer.For(p.GetName(), req.Model).Count,
+	}, p.createEmbeddings)
+}
+
+// createEmbeddings 发送单次向量化请求
+func (p *OpenAIProvider) createEmbeddings(ctx context.Context, req *types.EmbeddingRequest) (*types.EmbeddingResponse, error) {
+	respBody, err := p.post(ctx, "/embeddings", req)
+	if err != nil {
+		return nil, err
+	}
+	defer respBody.Close()
+
+	var resp types.EmbeddingResponse
+	if err := json.NewDecoder(respBody).Decode(&resp); err != nil {
+		return nil, fmt.Errorf("openai: decode embeddings: %w", err)
+	}
+	return &resp, nil
+}
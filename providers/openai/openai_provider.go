@@ -159,18 +159,44 @@ func (p *OpenAIProvider) CreateChatCompletionStream(ctx context.Context, req *ty
 	return p.doRequest(ctx, req)
 }
 
+// ListModels 实现ModelLister接口
+func (p *OpenAIProvider) ListModels(ctx context.Context) ([]types.Model, error) {
+	respBody, err := p.send(ctx, http.MethodGet, "/models", nil)
+	if err != nil {
+		return nil, err
+	}
+	defer respBody.Close()
+
+	var list types.ModelList
+	if err := json.NewDecoder(respBody).Decode(&list); err != nil {
+		return nil, fmt.Errorf("openai: decode models: %w", err)
+	}
+	return list.Data, nil
+}
+
 // doRequest 发送HTTP请求
 func (p *OpenAIProvider) doRequest(ctx context.Context, req *types.ChatCompletionRequest) (io.ReadCloser, error) {
-	url := fmt.Sprintf("%s/chat/completions", p.GetBaseURL())
+	req, err := prepareRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	return p.post(ctx, "/chat/completions", req)
+}
 
+// post 发送JSON请求，返回成功响应的响应体
+func (p *OpenAIProvider) post(ctx context.Context, path string, payload interface{}) (io.ReadCloser, error) {
 	// 序列化请求体
-	body, err := json.Marshal(req)
+	body, err := json.Marshal(payload)
 	if err != nil {
 		return nil, err
 	}
+	return p.send(ctx, http.MethodPost, path, bytes.NewReader(body))
+}
 
+// send 发送HTTP请求，返回成功响应的响应体
+func (p *OpenAIProvider) send(ctx context.Context, method, path string, body io.Reader) (io.ReadCloser, error) {
 	// 创建HTTP请求
-	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	httpReq, err := http.NewRequestWithContext(ctx, method, p.GetBaseURL()+path, body)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,61 @@
+package openai
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/yu1ec/go-anyllm/types"
+)
+
+func TestListModelsAndEmbeddings(t *testing.T) {
+	vector := make([]byte, 8)
+	binary.LittleEndian.PutUint32(vector, math.Float32bits(0.25))
+	binary.LittleEndian.PutUint32(vector[4:], math.Float32bits(-2))
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer test" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/models":
+			w.Write([]byte(`{"object":"list","data":[{"id":"gpt-4o","object":"model","created":1715367049,"owned_by":"system"}]}`))
+		case r.Method == http.MethodPost && r.URL.Path == "/embeddings":
+			w.Write([]byte(`{"object":"list","model":"text-embedding-3-small","data":[{"object":"embedding","index":0,"embedding":"` +
+				base64.StdEncoding.EncodeToString(vector) + `"}],"usage":{"prompt_tokens":3,"total_tokens":3}}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	provider, err := NewOpenAIProvider(&OpenAIConfig{APIKey: "test", BaseURL: server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	list, err := provider.ListModels(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 1 || list[0].ID != "gpt-4o" || list[0].OwnedBy != "system" {
+		t.Errorf("模型列表错误: %+v", list)
+	}
+
+	resp, err := provider.CreateEmbeddings(context.Background(), &types.EmbeddingRequest{
+		Model:          "text-embedding-3-small",
+		Input:          []string{"hello"},
+		EncodingFormat: types.EmbeddingEncodingBase64,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := resp.Data[0].Embedding; len(got) != 2 || got[0] != 0.25 || got[1] != -2 {
+		t.Errorf("base64向量解码错误: %v", got)
+	}
+}
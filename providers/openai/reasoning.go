@@ -0,0 +1,23 @@
+package openai
+
+import (
+	"github.com/yu1ec/go-anyllm/types"
+)
+
+// applyReasoning 把推理控制转换为 reasoning_effort
+// OpenAI推理模型只能调整强度，不能设置预算；关闭推理时不发送参数，由模型能力检查负责报错
+func applyReasoning(req *types.ChatCompletionRequest) error {
+	reasoning, err := req.ResolveReasoning()
+	if err != nil || reasoning == nil {
+		return err
+	}
+	if reasoning.BudgetTokens > 0 {
+		return &types.ReasoningError{Provider: "openai", Model: req.Model, Reason: "reasoning budget is not supported, use reasoning effort instead"}
+	}
+
+	req.Reasoning = nil
+	req.EnableThinking = nil
+	req.ThinkingBudget = nil
+	req.ReasoningEffort = reasoning.Effort
+	return nil
+}
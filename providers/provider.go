@@ -28,6 +28,13 @@ type Provider interface {
 	SetupHeaders(headers map[string]string)
 }
 
+// ModelLister 支持列出模型的服务商
+// 单独定义是为了不改变 Provider 的方法集，已有的自定义服务商无需修改
+type ModelLister interface {
+	// ListModels 列出服务商提供的模型
+	ListModels(ctx context.Context) ([]types.Model, error)
+}
+
 // ProviderConfig 服务商配置接口
 type ProviderConfig interface {
 	GetAPIKey() string
@@ -43,6 +50,7 @@ const (
 	ProviderDeepSeek ProviderType = "deepseek"
 	ProviderOpenAI   ProviderType = "openai"
 	ProviderAliCloud ProviderType = "alicloud"
+	ProviderOllama   ProviderType = "ollama"
 	ProviderBaidu    ProviderType = "baidu"
 	ProviderTencent  ProviderType = "tencent"
 )
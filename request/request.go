@@ -36,8 +36,9 @@ type Message struct {
 	Content string `json:"content"`
 	Name    string `json:"name,omitempty"`
 	// Prefix  bool   `json:"prefix"` // TODO: VN -- applicable for assistant role; support prefix while enabling beta support
-	// ReasoningContent string `json:"reasoning_content"` // TODO: VN -- applicable for assistant role; support prefix while enabling beta support
 	ToolCallId string `json:"tool_call_id"`
+	// ReasoningContent 思考内容，DeepSeek默认不接受，只在推理历史策略要求保留时发送
+	ReasoningContent string `json:"reasoning_content,omitempty"`
 }
 
 type ResponseFormat struct {
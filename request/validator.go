@@ -3,13 +3,23 @@ package request
 import (
 	"errors"
 	"fmt"
-)
+	"sort"
+	"strings"
 
-var (
-	modelChat     = "deepseek-chat"
-	modelReasoner = "deepseek-reasoner"
+	"github.com/yu1ec/go-anyllm/models"
 )
 
+// legacyModels 旧版客户端支持的模型及其约束
+// 固定不变，不受 models.DefaultRegistry 中注册或覆盖的约束影响，也不做前缀匹配；
+// max_tokens 上限与之前的版本一致，都是8192
+var legacyModels = map[string]models.Constraints{
+	"deepseek-chat":     {Capabilities: models.Capabilities{MaxOutputTokens: 8192}, TopP: &legacyTopPRange},
+	"deepseek-reasoner": {Capabilities: models.Capabilities{MaxOutputTokens: 8192}, TopP: &legacyTopPRange},
+}
+
+// legacyTopPRange 旧版客户端的 top_p 不包含0
+var legacyTopPRange = models.Range{Min: 0, Max: 1, ExclusiveMin: true}
+
 var roles map[string]struct{}
 var rolesStr string
 var modelStr string
@@ -24,7 +34,13 @@ func init() {
 	roles[RoleAssistant] = struct{}{}
 	roles[RoleTool] = struct{}{}
 
-	modelStr = fmt.Sprintf(`%s, %s`, modelChat, modelReasoner)
+	names := make([]string, 0, len(legacyModels))
+	for name := range legacyModels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	modelStr = strings.Join(names, ", ")
+
 	responseFormatStr = fmt.Sprintf(`%s, %s`, ResponseFormatText, ResponseFormatJsonObject)
 }
 
@@ -99,34 +115,42 @@ func validateModel(req *ChatCompletionsRequest) error {
 	if req.Model == "" {
 		return errors.New("err: model required in request")
 	}
-	if !(req.Model == modelChat || req.Model == modelReasoner) {
+	if _, ok := legacyModels[req.Model]; !ok {
 		return fmt.Errorf("err: invalid model %q; model should be one of [%s]", req.Model, modelStr)
 	}
 	return nil
 }
 
 func validateMultipleFields(req *ChatCompletionsRequest) error {
-	if !(req.FrequencyPenalty >= -2 && req.FrequencyPenalty <= 2) {
-		return fmt.Errorf("err: frequency_penalty is invalid; it should be number between -2 and 2")
+	constraints := legacyModels[req.Model]
+	rangeOf := func(custom *models.Range, def models.Range) models.Range {
+		if custom != nil {
+			return *custom
+		}
+		return def
+	}
+
+	if r := rangeOf(constraints.FrequencyPenalty, models.DefaultPenaltyRange); !r.Contains(float64(req.FrequencyPenalty)) {
+		return fmt.Errorf("err: frequency_penalty is invalid; the valid range of frequency_penalty is %s", r)
 	}
 
-	if !(req.MaxTokens == 0 || (req.MaxTokens >= 1 && req.MaxTokens <= 8192)) {
-		return fmt.Errorf("err: max_tokens is invalid; it should be number between 1 and 8192 or 0")
+	if maxTokens := constraints.MaxOutputTokens; maxTokens > 0 && !(req.MaxTokens == 0 || (req.MaxTokens >= 1 && req.MaxTokens <= maxTokens)) {
+		return fmt.Errorf("err: max_tokens is invalid; it should be number between 1 and %d or 0", maxTokens)
 	}
 
-	if !(req.PresencePenalty >= -2 && req.PresencePenalty <= 2) {
-		return fmt.Errorf("err: presence_penalty is invalid; it should be number between -2 and 2")
+	if r := rangeOf(constraints.PresencePenalty, models.DefaultPenaltyRange); !r.Contains(float64(req.PresencePenalty)) {
+		return fmt.Errorf("err: presence_penalty is invalid; the valid range of presence_penalty is %s", r)
 	}
 
 	if req.Temperature != nil {
-		if !(*req.Temperature >= 0 && *req.Temperature <= 2) {
-			return fmt.Errorf("err: temperature is invalid; the valid range of temperature is [0, 2.0]")
+		if r := rangeOf(constraints.Temperature, models.DefaultTemperatureRange); !r.Contains(float64(*req.Temperature)) {
+			return fmt.Errorf("err: temperature is invalid; the valid range of temperature is %s", r)
 		}
 	}
 
 	if req.TopP != nil {
-		if !(*req.TopP > 0 && *req.TopP <= 1) {
-			return fmt.Errorf("err: top_p is invalid; the valid range of top_p is (0, 1.0]")
+		if r := rangeOf(constraints.TopP, models.DefaultTopPRange); !r.Contains(float64(*req.TopP)) {
+			return fmt.Errorf("err: top_p is invalid; the valid range of top_p is %s", r)
 		}
 	}
 
@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/yu1ec/go-anyllm/models"
 )
 
 func TestValidateMessages(t *testing.T) {
@@ -120,18 +121,39 @@ func TestValidateModel(t *testing.T) {
 
 	t.Run("no err for valid model", func(t *testing.T) {
 		req := &ChatCompletionsRequest{
-			Model: modelChat,
+			Model: "deepseek-chat",
 		}
 		err := validateModel(req)
 		assert.NoError(t, err)
 
 		req = &ChatCompletionsRequest{
-			Model: modelReasoner,
+			Model: "deepseek-reasoner",
 		}
 		err = validateModel(req)
 		assert.NoError(t, err)
 	})
 
+	t.Run("not affected by model registry", func(t *testing.T) {
+		// 模型注册表按前缀匹配，旧版校验只接受固定的模型名称
+		_, ok := models.DefaultRegistry.Lookup("deepseek", "deepseek-chat-v2")
+		assert.True(t, ok)
+
+		req := &ChatCompletionsRequest{
+			Model: "deepseek-chat-v2",
+		}
+		err := validateModel(req)
+		assert.NotNil(t, err)
+	})
+
+	t.Run("keeps max_tokens limit", func(t *testing.T) {
+		req := &ChatCompletionsRequest{
+			Model:     "deepseek-reasoner",
+			MaxTokens: 8193,
+		}
+		err := validateMultipleFields(req)
+		assert.Error(t, err)
+	})
+
 }
 
 func TestValidateResponseFormat(t *testing.T) {
@@ -1,5 +1,7 @@
 package response
 
+import "encoding/json"
+
 // ChatCompletionsResponse is response payload for `POST /chat/completions` API.
 type ChatCompletionsResponse struct {
 	Id                string    `json:"id"`
@@ -26,7 +28,24 @@ type Message struct {
 	ToolCalls        []*ToolCall `json:"tool_calls"`
 }
 
+// UnmarshalJSON 解析消息，部分服务商（例如Ollama）以 reasoning 字段返回思考内容，统一转换为 ReasoningContent
+func (m *Message) UnmarshalJSON(data []byte) error {
+	type alias Message
+	aux := struct {
+		*alias
+		Reasoning string `json:"reasoning"`
+	}{alias: (*alias)(m)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	if m.ReasoningContent == "" {
+		m.ReasoningContent = aux.Reasoning
+	}
+	return nil
+}
+
 type ToolCall struct {
+	Index    *int         `json:"index,omitempty"` // index of the tool call in a streaming delta
 	Id       string       `json:"id"`
 	Type     string       `json:"type"`
 	Function ToolFunction `json:"function"`
@@ -43,6 +62,22 @@ type Delta struct {
 	ToolCalls        []*ToolCall `json:"tool_calls,omitempty"`
 }
 
+// UnmarshalJSON 解析流式增量，reasoning 字段与 Message 一样转换为 ReasoningContent
+func (d *Delta) UnmarshalJSON(data []byte) error {
+	type alias Delta
+	aux := struct {
+		*alias
+		Reasoning string `json:"reasoning"`
+	}{alias: (*alias)(d)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	if d.ReasoningContent == "" {
+		d.ReasoningContent = aux.Reasoning
+	}
+	return nil
+}
+
 type Usage struct {
 	CompletionTokens        int                     `json:"completion_tokens"`
 	PromptTokens            int                     `json:"prompt_tokens"`
@@ -1,6 +1,7 @@
 package response
 
 import (
+	"encoding/json"
 	"io"
 	"strings"
 	"testing"
@@ -137,3 +138,29 @@ data: [DONE]
 		assert.False(t, streamReader.Next())
 	})
 }
+
+func TestStreamReaderReasoningAlias(t *testing.T) {
+	streamData := `data: {"id":"test-1","model":"qwen3","choices":[{"index":0,"delta":{"role":"assistant","content":"","reasoning":"先想一想"},"finish_reason":null}]}
+
+data: {"id":"test-2","model":"deepseek-reasoner","choices":[{"index":0,"delta":{"content":"","reasoning_content":"再想一想"},"finish_reason":null}]}
+
+data: {"id":"test-3","model":"qwen3","choices":[{"index":0,"delta":{"content":"答案"},"finish_reason":"stop"}]}
+
+data: [DONE]
+`
+	reader := NewStreamReader(io.NopCloser(strings.NewReader(streamData)))
+
+	var reasoning, content []string
+	for reader.Next() {
+		delta := reader.Current().Choices[0].Delta
+		reasoning = append(reasoning, delta.ReasoningContent)
+		content = append(content, delta.Content)
+	}
+	assert.NoError(t, reader.Error())
+	assert.Equal(t, []string{"先想一想", "再想一想", ""}, reasoning)
+	assert.Equal(t, []string{"", "", "答案"}, content)
+
+	var message Message
+	assert.NoError(t, json.Unmarshal([]byte(`{"role":"assistant","content":"4","reasoning":"2+2=4"}`), &message))
+	assert.Equal(t, "2+2=4", message.ReasoningContent)
+}
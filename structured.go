@@ -0,0 +1,307 @@
+package deepseek
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/yu1ec/go-anyllm/models"
+	"github.com/yu1ec/go-anyllm/tools"
+	"github.com/yu1ec/go-anyllm/types"
+)
+
+// StructuredMode 结构化输出的实现方式
+type StructuredMode int
+
+const (
+	// StructuredModeAuto 模型支持json_schema时使用原生模式，否则使用提示词模式
+	StructuredModeAuto StructuredMode = iota
+	// StructuredModeNative 使用 response_format 的 json_schema
+	StructuredModeNative
+	// StructuredModePrompt 把Schema写入系统提示词，模型支持JSON模式时同时使用json_object
+	StructuredModePrompt
+)
+
+// DefaultStructuredRetries 结果不符合Schema时默认重新请求的次数
+const DefaultStructuredRetries = 2
+
+// jsonSchemaProviders 原生支持 response_format json_schema 的服务商，用于不在模型注册表中的模型
+var jsonSchemaProviders = map[string]bool{
+	"openai": true,
+}
+
+// ErrTruncatedOutput 输出因达到max_tokens被截断，无法得到完整的JSON
+var ErrTruncatedOutput = errors.New("deepseek: structured output was truncated by max_tokens")
+
+// StructuredOutputError 模型的输出在重试后仍然无法解析或不符合Schema
+type StructuredOutputError struct {
+	Content  string // 最后一次输出的内容
+	Attempts int    // 请求次数
+	Err      error  // 最后一次的解析或校验错误
+}
+
+// Error 实现error接口
+func (e *StructuredOutputError) Error() string {
+	return fmt.Sprintf("deepseek: invalid structured output after %d attempt(s): %v", e.Attempts, e.Err)
+}
+
+// Unwrap 返回解析或校验错误
+func (e *StructuredOutputError) Unwrap() error {
+	return e.Err
+}
+
+// structuredConfig 结构化输出配置
+type structuredConfig struct {
+	name        string
+	description string
+	mode        StructuredMode
+	retries     int
+}
+
+// StructuredOption 结构化输出选项
+type StructuredOption func(*structuredConfig)
+
+// WithSchemaName 设置Schema名称，默认使用类型名
+func WithSchemaName(name string) StructuredOption {
+	return func(c *structuredConfig) {
+		c.name = name
+	}
+}
+
+// WithSchemaDescription 设置Schema说明，帮助模型理解输出的用途
+func WithSchemaDescription(description string) StructuredOption {
+	return func(c *structuredConfig) {
+		c.description = description
+	}
+}
+
+// WithStructuredMode 指定结构化输出的实现方式，默认 StructuredModeAuto
+func WithStructuredMode(mode StructuredMode) StructuredOption {
+	return func(c *structuredConfig) {
+		c.mode = mode
+	}
+}
+
+// WithStructuredRetries 设置结果不符合Schema时重新请求的次数，默认 DefaultStructuredRetries
+func WithStructuredRetries(n int) StructuredOption {
+	return func(c *structuredConfig) {
+		c.retries = n
+	}
+}
+
+// CreateStructured 请求模型按T的JSON Schema输出，并解码为T
+//
+// T 必须是结构体，Schema的生成规则与 tools.SchemaFromType 相同。原生支持json_schema的服务商
+// 直接使用 response_format；其他服务商（例如DeepSeek）把Schema写入系统提示词，
+// 能力表中支持JSON模式的模型同时使用json_object模式。
+// 输出无法解析或不符合Schema时，会把错误告诉模型并重新请求。
+// 返回的响应是最后一次请求的响应，Usage 为所有请求的合计。
+func CreateStructured[T any](ctx context.Context, client UnifiedClient, req *types.ChatCompletionRequest, opts ...StructuredOption) (T, *types.ChatCompletionResponse, error) {
+	var result T
+	config := structuredConfig{retries: DefaultStructuredRetries}
+	for _, opt := range opts {
+		opt(&config)
+	}
+
+	schema, err := tools.SchemaFromType[T]()
+	if err != nil {
+		return result, nil, err
+	}
+	if config.name == "" {
+		config.name = schemaName(reflect.TypeOf((*T)(nil)).Elem())
+	}
+	caps, known := models.Lookup(client.GetProviderName(), req.Model)
+	mode := config.mode
+	if mode == StructuredModeAuto {
+		mode = StructuredModePrompt
+		if caps.JSONSchema || (!known && jsonSchemaProviders[client.GetProviderName()]) {
+			mode = StructuredModeNative
+		}
+	}
+
+	structuredReq := *req
+	structuredReq.Stream = false
+	structuredReq.Messages = append([]types.ChatCompletionMessage(nil), req.Messages...)
+	if mode == StructuredModeNative {
+		format := &types.JSONSchemaFormat{Name: config.name, Description: config.description, Schema: schema}
+		if strict, ok := tools.StrictSchema(schema); ok {
+			format.Schema = strict
+			format.Strict = true
+		}
+		structuredReq.ResponseFormat = &types.ResponseFormat{Type: types.ResponseFormatJSONSchema, JSONSchema: format}
+	} else {
+		prompt, err := schemaPrompt(config, schema)
+		if err != nil {
+			return result, nil, err
+		}
+		if known && caps.JSONMode {
+			structuredReq.ResponseFormat = &types.ResponseFormat{Type: types.ResponseFormatJSONObject}
+		} else {
+			// 不支持或不确定是否支持JSON模式时不设置 response_format，只靠系统提示词和重新请求
+			structuredReq.ResponseFormat = nil
+		}
+		structuredReq.Messages = insertSystemMessage(structuredReq.Messages, prompt)
+	}
+
+	var usage *types.Usage
+	for attempt := 1; ; attempt++ {
+		resp, err := client.CreateChatCompletion(ctx, &structuredReq)
+		if err != nil {
+			return result, resp, err
+		}
+		usage = addUsage(usage, resp.Usage)
+		resp.Usage = usage
+
+		if len(resp.Choices) == 0 {
+			return result, resp, fmt.Errorf("deepseek: structured output response has no choices")
+		}
+		choice := resp.Choices[0]
+		if choice.FinishReason == types.FinishReasonLength {
+			return result, resp, ErrTruncatedOutput
+		}
+
+		var content string
+		if choice.Message != nil {
+			content = choice.Message.GetContentAsString()
+		}
+		if result, err = decodeStructured[T](content, schema); err == nil {
+			return result, resp, nil
+		}
+		if attempt > config.retries {
+			return result, resp, &StructuredOutputError{Content: content, Attempts: attempt, Err: err}
+		}
+
+		structuredReq.Messages = append(structuredReq.Messages,
+			types.NewTextMessage(types.RoleAssistant, content),
+			types.NewTextMessage(types.RoleUser, fmt.Sprintf(
+				"Your previous reply is not valid: %v\nReply again with only a JSON object that matches the schema.", err)),
+		)
+	}
+}
+
+// decodeStructured 解析模型输出的JSON，校验Schema后解码为T
+func decodeStructured[T any](content string, schema *tools.FunctionSchema) (T, error) {
+	var result T
+	repaired, _, err := tools.RepairJSON(content)
+	if err != nil {
+		return result, fmt.Errorf("reply is not JSON: %w", err)
+	}
+
+	var value interface{}
+	if err := json.Unmarshal([]byte(repaired), &value); err != nil {
+		return result, fmt.Errorf("reply is not JSON: %w", err)
+	}
+	// strict模式下非必需字段以null返回，按缺省处理
+	if err := tools.ValidateAgainstSchema(schema, dropNulls(value)); err != nil {
+		return result, err
+	}
+	if err := json.Unmarshal([]byte(repaired), &result); err != nil {
+		return result, err
+	}
+	return result, nil
+}
+
+// dropNulls 递归移除对象中值为null的字段
+func dropNulls(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			if item == nil {
+				delete(v, key)
+				continue
+			}
+			v[key] = dropNulls(item)
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = dropNulls(item)
+		}
+	}
+	return value
+}
+
+// schemaPrompt 生成提示词模式下的系统提示词
+func schemaPrompt(config structuredConfig, schema *tools.FunctionSchema) (string, error) {
+	data, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("deepseek: marshal schema: %w", err)
+	}
+
+	var prompt strings.Builder
+	prompt.WriteString("Reply with a single JSON object that matches the following JSON Schema")
+	if config.description != "" {
+		prompt.WriteString(" (")
+		prompt.WriteString(config.description)
+		prompt.WriteString(")")
+	}
+	prompt.WriteString(". Do not add any text outside the JSON object.\n\n")
+	prompt.WriteString("Schema \"")
+	prompt.WriteString(config.name)
+	prompt.WriteString("\":\n")
+	prompt.Write(data)
+	return prompt.String(), nil
+}
+
+// insertSystemMessage 在已有的系统消息之后插入一条系统消息
+func insertSystemMessage(messages []types.ChatCompletionMessage, prompt string) []types.ChatCompletionMessage {
+	i := 0
+	for i < len(messages) && messages[i].Role == types.RoleSystem {
+		i++
+	}
+	result := make([]types.ChatCompletionMessage, 0, len(messages)+1)
+	result = append(result, messages[:i]...)
+	result = append(result, types.NewTextMessage(types.RoleSystem, prompt))
+	return append(result, messages[i:]...)
+}
+
+// invalidSchemaNameChars Schema名称中不允许的字符
+var invalidSchemaNameChars = regexp.MustCompile(`[^a-zA-Z0-9_-]+`)
+
+// schemaName 根据类型名生成Schema名称
+func schemaName(t reflect.Type) string {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	name := invalidSchemaNameChars.ReplaceAllString(t.Name(), "_")
+	if name == "" {
+		return "response"
+	}
+	return name
+}
+
+// addUsage 累加token用量，返回新的合计，不修改参数
+func addUsage(total, usage *types.Usage) *types.Usage {
+	if usage == nil {
+		return total
+	}
+	if total == nil {
+		total = &types.Usage{}
+	}
+	sum := *total
+	sum.PromptTokens += usage.PromptTokens
+	sum.CompletionTokens += usage.CompletionTokens
+	sum.TotalTokens += usage.TotalTokens
+	sum.PromptCacheHitTokens += usage.PromptCacheHitTokens
+	sum.PromptCacheMissTokens += usage.PromptCacheMissTokens
+	if usage.PromptTokensDetails != nil {
+		details := types.PromptTokensDetails{}
+		if sum.PromptTokensDetails != nil {
+			details = *sum.PromptTokensDetails
+		}
+		details.CachedTokens += usage.PromptTokensDetails.CachedTokens
+		sum.PromptTokensDetails = &details
+	}
+	if usage.CompletionTokensDetails != nil {
+		details := types.CompletionTokensDetails{}
+		if sum.CompletionTokensDetails != nil {
+			details = *sum.CompletionTokensDetails
+		}
+		details.ReasoningTokens += usage.CompletionTokensDetails.ReasoningTokens
+		sum.CompletionTokensDetails = &details
+	}
+	return &sum
+}
@@ -0,0 +1,175 @@
+package deepseek
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/yu1ec/go-anyllm/models"
+	"github.com/yu1ec/go-anyllm/providers"
+	"github.com/yu1ec/go-anyllm/response"
+	"github.com/yu1ec/go-anyllm/types"
+)
+
+// scriptedClient 按顺序返回预设回复的客户端
+type scriptedClient struct {
+	provider string
+	replies  []string
+	requests []types.ChatCompletionRequest
+}
+
+func (c *scriptedClient) CreateChatCompletion(ctx context.Context, req *types.ChatCompletionRequest) (*types.ChatCompletionResponse, error) {
+	c.requests = append(c.requests, *req)
+	if len(c.replies) == 0 {
+		return nil, errors.New("no more replies")
+	}
+	reply := c.replies[0]
+	c.replies = c.replies[1:]
+	message := types.NewTextMessage(types.RoleAssistant, reply)
+	return &types.ChatCompletionResponse{
+		Choices: []types.ChatCompletionChoice{{Message: &message, FinishReason: types.FinishReasonStop}},
+		Usage:   &types.Usage{PromptTokens: 10, CompletionTokens: 5, TotalTokens: 15},
+	}, nil
+}
+
+func (c *scriptedClient) CreateChatCompletionStream(ctx context.Context, req *types.ChatCompletionRequest) (response.StreamReader, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (c *scriptedClient) GetProvider() providers.Provider { return nil }
+
+func (c *scriptedClient) GetProviderName() string { return c.provider }
+
+type weatherReport struct {
+	City        string   `json:"city" description:"城市"`
+	Temperature float64  `json:"temperature"`
+	Condition   string   `json:"condition" enum:"sunny,cloudy,rainy"`
+	Tags        []string `json:"tags,omitempty"`
+}
+
+func TestCreateStructuredNative(t *testing.T) {
+	client := &scriptedClient{provider: "openai", replies: []string{`{"city":"北京","temperature":21.5,"condition":"sunny","tags":null}`}}
+	req := &types.ChatCompletionRequest{
+		Model:    "gpt-4o",
+		Messages: []types.ChatCompletionMessage{types.NewTextMessage(types.RoleUser, "北京天气")},
+	}
+
+	report, resp, err := CreateStructured[weatherReport](context.Background(), client, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.City != "北京" || report.Temperature != 21.5 || resp == nil {
+		t.Errorf("解码结果错误: %+v", report)
+	}
+
+	format := client.requests[0].ResponseFormat
+	if format == nil || format.Type != types.ResponseFormatJSONSchema || format.JSONSchema.Name != "weatherReport" || !format.JSONSchema.Strict {
+		t.Fatalf("期望strict json_schema，得到 %+v", format)
+	}
+	schema := format.JSONSchema.Schema.(map[string]interface{})
+	if schema["additionalProperties"] != false || len(schema["required"].([]string)) != 4 {
+		t.Errorf("strict Schema错误: %v", schema)
+	}
+	if req.ResponseFormat != nil || len(client.requests[0].Messages) != 1 {
+		t.Error("原请求不应被修改，原生模式不应添加提示词")
+	}
+}
+
+func TestCreateStructuredPromptFallback(t *testing.T) {
+	client := &scriptedClient{provider: "deepseek", replies: []string{
+		`{"city":"上海","temperature":"warm","condition":"sunny"}`,
+		"```json\n{\"city\":\"上海\",\"temperature\":26,\"condition\":\"cloudy\"}\n```",
+	}}
+	req := &types.ChatCompletionRequest{
+		Model: "deepseek-chat",
+		Messages: []types.ChatCompletionMessage{
+			types.NewTextMessage(types.RoleSystem, "你是天气助手"),
+			types.NewTextMessage(types.RoleUser, "上海天气"),
+		},
+	}
+
+	report, resp, err := CreateStructured[weatherReport](context.Background(), client, req, WithSchemaName("weather"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Temperature != 26 || report.Condition != "cloudy" {
+		t.Errorf("解码结果错误: %+v", report)
+	}
+	if resp.Usage.TotalTokens != 30 {
+		t.Errorf("期望累计30个token，得到 %d", resp.Usage.TotalTokens)
+	}
+
+	first := client.requests[0]
+	if first.ResponseFormat.Type != types.ResponseFormatJSONObject || len(first.Messages) != 3 {
+		t.Fatalf("期望json_object并插入系统提示词，得到 %+v", first.ResponseFormat)
+	}
+	if prompt := first.Messages[1].GetContentAsString(); first.Messages[1].Role != types.RoleSystem || !strings.Contains(prompt, `"weather"`) || !strings.Contains(prompt, `"temperature"`) {
+		t.Errorf("系统提示词错误: %s", prompt)
+	}
+
+	retry := client.requests[1].Messages
+	if len(retry) != 5 || !strings.Contains(retry[4].GetContentAsString(), "$.temperature") {
+		t.Errorf("重新请求时应说明校验错误: %v", retry[len(retry)-1].Content)
+	}
+}
+
+func TestCreateStructuredPromptWithoutJSONMode(t *testing.T) {
+	for _, tt := range []struct{ provider, model string }{{"openai", "gpt-4"}, {"alicloud", "qwen-long"}, {"ollama", "llama3"}} {
+		client := &scriptedClient{provider: tt.provider, replies: []string{`{"city":"上海","temperature":26,"condition":"cloudy"}`}}
+		req := &types.ChatCompletionRequest{Model: tt.model, Messages: []types.ChatCompletionMessage{types.NewTextMessage(types.RoleUser, "上海天气")}}
+
+		if _, _, err := CreateStructured[weatherReport](context.Background(), client, req); err != nil {
+			t.Fatalf("%s: %v", tt.model, err)
+		}
+		sent := client.requests[0]
+		if sent.ResponseFormat != nil || sent.Messages[0].Role != types.RoleSystem {
+			t.Errorf("%s: 不支持JSON模式时应只使用系统提示词，得到 %+v", tt.model, sent.ResponseFormat)
+		}
+		if err := models.Validate(tt.provider, &sent); err != nil {
+			t.Errorf("%s: 请求应通过校验，得到 %v", tt.model, err)
+		}
+	}
+}
+
+func TestCreateStructuredGivesUp(t *testing.T) {
+	client := &scriptedClient{provider: "deepseek", replies: []string{"not json", `{"city":1}`}}
+	req := &types.ChatCompletionRequest{Messages: []types.ChatCompletionMessage{types.NewTextMessage(types.RoleUser, "天气")}}
+
+	_, _, err := CreateStructured[weatherReport](context.Background(), client, req, WithStructuredRetries(1))
+	var outputErr *StructuredOutputError
+	if !errors.As(err, &outputErr) || outputErr.Attempts != 2 || outputErr.Content != `{"city":1}` {
+		t.Fatalf("期望 StructuredOutputError，得到 %v", err)
+	}
+
+	if _, _, err := CreateStructured[string](context.Background(), client, req); err == nil {
+		t.Error("非结构体类型应返回错误")
+	}
+}
+
+func TestAddUsageDetails(t *testing.T) {
+	first := &types.Usage{
+		PromptTokens: 10, CompletionTokens: 5, TotalTokens: 15,
+		PromptTokensDetails:     &types.PromptTokensDetails{CachedTokens: 4},
+		CompletionTokensDetails: &types.CompletionTokensDetails{ReasoningTokens: 2},
+	}
+	second := &types.Usage{
+		PromptTokens: 20, CompletionTokens: 8, TotalTokens: 28,
+		PromptTokensDetails:     &types.PromptTokensDetails{CachedTokens: 6},
+		CompletionTokensDetails: &types.CompletionTokensDetails{ReasoningTokens: 3},
+	}
+
+	total := addUsage(addUsage(nil, first), second)
+	if total.PromptTokens != 30 || total.TotalTokens != 43 {
+		t.Errorf("token合计错误: %+v", total)
+	}
+	if total.PromptTokensDetails == nil || total.PromptTokensDetails.CachedTokens != 10 {
+		t.Errorf("期望缓存token合计为10，得到 %+v", total.PromptTokensDetails)
+	}
+	if total.ReasoningTokens() != 5 {
+		t.Errorf("期望推理token合计为5，得到 %d", total.ReasoningTokens())
+	}
+	if first.PromptTokensDetails.CachedTokens != 4 || first.CompletionTokensDetails.ReasoningTokens != 2 {
+		t.Error("累加不应修改参数")
+	}
+}
@@ -0,0 +1,178 @@
+package tokenizer
+
+import (
+	"bufio"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// BPE 字节级BPE分词器，可以加载tiktoken格式（cl100k_base、o200k_base、通义千问的qwen.tiktoken）
+// 和HuggingFace tokenizer.json格式（DeepSeek、Qwen2）的词表
+type BPE struct {
+	encoder map[string]int // 字节序列 -> token ID
+	ranks   map[string]int // 字节序列 -> 合并优先级，tiktoken词表中与token ID相同
+	decoder map[int]string
+	special map[string]int
+	split   Splitter
+}
+
+// bpeConfig BPE分词器配置
+type bpeConfig struct {
+	special map[string]int
+	split   Splitter
+}
+
+// BPEOption BPE分词器选项
+type BPEOption func(*bpeConfig)
+
+// WithSpecialTokens 设置特殊token，例如 "<|im_start|>"，文本中出现时编码为对应的ID
+func WithSpecialTokens(special map[string]int) BPEOption {
+	return func(c *bpeConfig) {
+		c.special = special
+	}
+}
+
+// WithSplitter 设置预分词函数，默认 SplitCL100K
+func WithSplitter(split Splitter) BPEOption {
+	return func(c *bpeConfig) {
+		c.split = split
+	}
+}
+
+// NewBPE 使用tiktoken形式的词表（字节序列 -> token ID，ID同时作为合并优先级）创建分词器
+// 词表必须包含全部256个单字节token
+func NewBPE(ranks map[string]int, opts ...BPEOption) (*BPE, error) {
+	return newBPE(ranks, ranks, opts...)
+}
+
+// newBPE 创建分词器，encoder 和 ranks 可以不同（HuggingFace词表按merges的顺序合并）
+func newBPE(encoder, ranks map[string]int, opts ...BPEOption) (*BPE, error) {
+	config := bpeConfig{split: SplitCL100K}
+	for _, opt := range opts {
+		opt(&config)
+	}
+	for b := 0; b < 256; b++ {
+		if _, ok := encoder[string([]byte{byte(b)})]; !ok {
+			return nil, fmt.Errorf("tokenizer: vocabulary does not cover byte 0x%02x", b)
+		}
+	}
+
+	decoder := make(map[int]string, len(encoder)+len(config.special))
+	for token, id := range encoder {
+		decoder[id] = token
+	}
+	for token, id := range config.special {
+		decoder[id] = token
+	}
+	return &BPE{encoder: encoder, ranks: ranks, decoder: decoder, special: config.special, split: config.split}, nil
+}
+
+// LoadTiktoken 从文件加载tiktoken格式的词表
+func LoadTiktoken(path string, opts ...BPEOption) (*BPE, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return ParseTiktoken(file, opts...)
+}
+
+// ParseTiktoken 解析tiktoken格式的词表，每行为 "<base64编码的字节序列> <token ID>"
+func ParseTiktoken(r io.Reader, opts ...BPEOption) (*BPE, error) {
+	ranks := make(map[string]int)
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		encoded, rank, ok := strings.Cut(text, " ")
+		if !ok {
+			return nil, fmt.Errorf("tokenizer: line %d: expected \"<token> <rank>\"", line)
+		}
+		token, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("tokenizer: line %d: %w", line, err)
+		}
+		id, err := strconv.Atoi(rank)
+		if err != nil {
+			return nil, fmt.Errorf("tokenizer: line %d: %w", line, err)
+		}
+		ranks[string(token)] = id
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return NewBPE(ranks, opts...)
+}
+
+// Count 实现Tokenizer接口
+func (b *BPE) Count(text string) int {
+	return len(b.Encode(text))
+}
+
+// Encode 把文本编码为token ID
+func (b *BPE) Encode(text string) []int {
+	var ids []int
+	for text != "" {
+		// 找到最早出现的特殊token
+		start, end, specialID := len(text), len(text), -1
+		for token, id := range b.special {
+			if i := strings.Index(text, token); i >= 0 && (i < start || (i == start && i+len(token) > end)) {
+				start, end, specialID = i, i+len(token), id
+			}
+		}
+		for _, piece := range b.split(text[:start]) {
+			ids = b.encodePiece(piece, ids)
+		}
+		if specialID >= 0 {
+			ids = append(ids, specialID)
+		}
+		text = text[end:]
+	}
+	return ids
+}
+
+// encodePiece 对预分词得到的片段做BPE合并，把结果追加到ids
+func (b *BPE) encodePiece(piece string, ids []int) []int {
+	if id, ok := b.encoder[piece]; ok {
+		return append(ids, id)
+	}
+
+	// bounds[i] 为第i个部分的起点，每次合并优先级最高的相邻两部分
+	bounds := make([]int, len(piece)+1)
+	for i := range bounds {
+		bounds[i] = i
+	}
+	for len(bounds) > 2 {
+		best, bestRank := -1, math.MaxInt
+		for i := 0; i+2 < len(bounds); i++ {
+			if rank, ok := b.ranks[piece[bounds[i]:bounds[i+2]]]; ok && rank < bestRank {
+				best, bestRank = i, rank
+			}
+		}
+		if best < 0 {
+			break
+		}
+		bounds = append(bounds[:best+1], bounds[best+2:]...)
+	}
+	for i := 0; i+1 < len(bounds); i++ {
+		ids = append(ids, b.encoder[piece[bounds[i]:bounds[i+1]]])
+	}
+	return ids
+}
+
+// Decode 把token ID解码为文本，忽略未知的ID
+func (b *BPE) Decode(ids []int) string {
+	var text strings.Builder
+	for _, id := range ids {
+		text.WriteString(b.decoder[id])
+	}
+	return text.String()
+}
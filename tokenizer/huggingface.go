@@ -0,0 +1,112 @@
+package tokenizer
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// hfTokenizer tokenizer.json 中用到的字段
+type hfTokenizer struct {
+	AddedTokens []struct {
+		ID      int    `json:"id"`
+		Content string `json:"content"`
+	} `json:"added_tokens"`
+	Model struct {
+		Type   string            `json:"type"`
+		Vocab  map[string]int    `json:"vocab"`
+		Merges []json.RawMessage `json:"merges"` // "a b" 或 ["a", "b"]
+	} `json:"model"`
+}
+
+// LoadHuggingFace 从文件加载HuggingFace tokenizer.json 格式的字节级BPE词表，例如DeepSeek和Qwen2的词表
+func LoadHuggingFace(path string, opts ...BPEOption) (*BPE, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return ParseHuggingFace(file, opts...)
+}
+
+// ParseHuggingFace 解析HuggingFace tokenizer.json 格式的字节级BPE词表
+// added_tokens 作为特殊token，预分词默认使用 SplitCL100K
+func ParseHuggingFace(r io.Reader, opts ...BPEOption) (*BPE, error) {
+	var hf hfTokenizer
+	if err := json.NewDecoder(r).Decode(&hf); err != nil {
+		return nil, fmt.Errorf("tokenizer: decode tokenizer.json: %w", err)
+	}
+	if hf.Model.Type != "BPE" {
+		return nil, fmt.Errorf("tokenizer: unsupported model type %q, only BPE is supported", hf.Model.Type)
+	}
+
+	unicodeToByte := byteLevelDecoder()
+	decode := func(token string) (string, error) {
+		raw := make([]byte, 0, len(token))
+		for _, r := range token {
+			b, ok := unicodeToByte[r]
+			if !ok {
+				return "", fmt.Errorf("tokenizer: token %q is not byte-level encoded", token)
+			}
+			raw = append(raw, b)
+		}
+		return string(raw), nil
+	}
+
+	special := make(map[string]int, len(hf.AddedTokens))
+	for _, token := range hf.AddedTokens {
+		special[token.Content] = token.ID
+	}
+	encoder := make(map[string]int, len(hf.Model.Vocab))
+	for token, id := range hf.Model.Vocab {
+		if _, ok := special[token]; ok {
+			continue
+		}
+		raw, err := decode(token)
+		if err != nil {
+			return nil, err
+		}
+		encoder[raw] = id
+	}
+
+	ranks := make(map[string]int, len(hf.Model.Merges))
+	for i, merge := range hf.Model.Merges {
+		var pair []string
+		var text string
+		if err := json.Unmarshal(merge, &text); err == nil {
+			pair = strings.SplitN(text, " ", 2)
+		} else if err := json.Unmarshal(merge, &pair); err != nil {
+			return nil, fmt.Errorf("tokenizer: merge %d: %w", i, err)
+		}
+		if len(pair) != 2 {
+			return nil, fmt.Errorf("tokenizer: merge %d: expected a pair of tokens", i)
+		}
+		merged, err := decode(pair[0] + pair[1])
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := ranks[merged]; !ok {
+			ranks[merged] = i
+		}
+	}
+
+	return newBPE(encoder, ranks, append([]BPEOption{WithSpecialTokens(special)}, opts...)...)
+}
+
+// byteLevelDecoder 返回GPT-2字节级编码中字符到字节的映射
+// 可打印字符映射为自身，其余字节依次映射为U+0100之后的字符
+func byteLevelDecoder() map[rune]byte {
+	decoder := make(map[rune]byte, 256)
+	next := rune(256)
+	for b := 0; b < 256; b++ {
+		if (b >= '!' && b <= '~') || (b >= 0xA1 && b <= 0xAC) || (b >= 0xAE && b <= 0xFF) {
+			decoder[rune(b)] = byte(b)
+		} else {
+			decoder[next] = byte(b)
+			next++
+		}
+	}
+	return decoder
+}
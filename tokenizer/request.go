@@ -0,0 +1,117 @@
+package tokenizer
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"image"
+	_ "image/gif"  // 注册GIF解码器，用于读取图像尺寸
+	_ "image/jpeg" // 注册JPEG解码器
+	_ "image/png"  // 注册PNG解码器
+	"math"
+
+	"github.com/yu1ec/go-anyllm/types"
+)
+
+// 计算请求时使用的固定开销，参考OpenAI的计算方式
+const (
+	messageOverheadTokens = 3   // 每条消息的角色和分隔符
+	nameOverheadTokens    = 1   // 消息带name时
+	replyPrimingTokens    = 3   // 回复的起始标记
+	toolOverheadTokens    = 8   // 每个工具定义的包装
+	lowDetailImageTokens  = 85  // detail为low的图像，也是高清图像的基础开销
+	imageTileTokens       = 170 // 高清图像每个512像素的图块
+	defaultImageTokens    = 765 // 无法读取尺寸的高清图像，相当于1024x1024
+)
+
+// CountRequest 计算请求的提示词token数，包括消息开销、工具定义和图像
+// 音频、视频和文件内容不计入；思考内容按原样计入，服务商移除历史中的思考内容时结果偏大
+func CountRequest(tokenizer Tokenizer, req *types.ChatCompletionRequest) int {
+	tokens := replyPrimingTokens
+	for _, message := range req.Messages {
+		tokens += CountMessage(tokenizer, message)
+	}
+	for _, tool := range req.Tools {
+		tokens += toolOverheadTokens
+		if definition, err := json.Marshal(tool.Function); err == nil {
+			tokens += tokenizer.Count(string(definition))
+		}
+	}
+	return tokens
+}
+
+// CountMessage 计算单条消息的token数，包括文本、图像、工具调用和思考内容
+func CountMessage(tokenizer Tokenizer, message types.ChatCompletionMessage) int {
+	tokens := messageOverheadTokens + tokenizer.Count(message.Role)
+	if message.Name != "" {
+		tokens += nameOverheadTokens + tokenizer.Count(message.Name)
+	}
+
+	for _, part := range message.GetContent().Parts() {
+		switch part.Type {
+		case types.MessageContentTypeText:
+			tokens += tokenizer.Count(part.Text)
+		case types.MessageContentTypeImageURL:
+			if part.ImageURL != nil {
+				tokens += ImageTokens(*part.ImageURL)
+			}
+		}
+	}
+
+	for _, toolCall := range message.ToolCalls {
+		tokens += tokenizer.Count(toolCall.Function.Name)
+		switch arguments := toolCall.Function.Arguments.(type) {
+		case string:
+			tokens += tokenizer.Count(arguments)
+		case nil:
+		default:
+			if data, err := json.Marshal(arguments); err == nil {
+				tokens += tokenizer.Count(string(data))
+			}
+		}
+	}
+	if message.ToolCallID != "" {
+		tokens += tokenizer.Count(message.ToolCallID)
+	}
+	return tokens + tokenizer.Count(message.ReasoningContent)
+}
+
+// ImageTokens 按OpenAI的规则计算图像的token数
+// 高清图像先缩放到2048x2048以内、短边不超过768，再按512像素的图块计算；
+// 只有data URL能读取尺寸，普通URL按1024x1024计算
+func ImageTokens(img types.ImageURL) int {
+	if img.Detail == types.ImageDetailLow {
+		return lowDetailImageTokens
+	}
+	width, height, ok := imageSize(img.URL)
+	if !ok {
+		return defaultImageTokens
+	}
+
+	w, h := float64(width), float64(height)
+	if longest := math.Max(w, h); longest > 2048 {
+		w, h = w*2048/longest, h*2048/longest
+	}
+	if shortest := math.Min(w, h); shortest > 768 {
+		w, h = w*768/shortest, h*768/shortest
+	}
+	tiles := int(math.Ceil(w/512) * math.Ceil(h/512))
+	return lowDetailImageTokens + imageTileTokens*tiles
+}
+
+// imageSize 读取data URL中图像的尺寸
+func imageSize(url string) (width, height int, ok bool) {
+	_, data, ok := types.SplitDataURL(url)
+	if !ok {
+		return 0, 0, false
+	}
+	raw, err := base64.StdEncoding.DecodeString(data)
+	if err != nil {
+		return 0, 0, false
+	}
+	config, _, err := image.DecodeConfig(bytes.NewReader(raw))
+	if err != nil {
+		return 0, 0, false
+	}
+	return config.Width, config.Height, true
+}
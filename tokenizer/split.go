@@ -0,0 +1,111 @@
+package tokenizer
+
+import (
+	"unicode"
+)
+
+// Splitter 预分词函数，把文本切分为分别进行BPE编码的片段
+type Splitter func(text string) []string
+
+// contractions 英文缩写的后缀
+var contractions = []string{"s", "t", "re", "ve", "m", "ll", "d"}
+
+// SplitCL100K 按cl100k_base的规则预分词：英文缩写、单词（可以带一个前导符号或空格）、
+// 最多3位的数字、标点（可以带一个前导空格和后续换行）以及空白
+// o200k、DeepSeek和通义千问的规则与之相近，作为它们的默认预分词使用时结果是近似的
+func SplitCL100K(text string) []string {
+	runes := []rune(text)
+	var pieces []string
+	for i := 0; i < len(runes); {
+		j := matchCL100K(runes, i)
+		pieces = append(pieces, string(runes[i:j]))
+		i = j
+	}
+	return pieces
+}
+
+// matchCL100K 返回从i开始的片段的结束位置
+func matchCL100K(r []rune, i int) int {
+	n := len(r)
+	c := r[i]
+
+	// 英文缩写，例如 's、'll
+	if c == '\'' {
+		for _, suffix := range contractions {
+			if hasFoldPrefix(r[i+1:], suffix) {
+				return i + 1 + len(suffix)
+			}
+		}
+	}
+
+	// 单词，可以带一个非换行的前导字符
+	if unicode.IsLetter(c) {
+		return scanLetters(r, i)
+	}
+	if c != '\r' && c != '\n' && !unicode.IsNumber(c) && i+1 < n && unicode.IsLetter(r[i+1]) {
+		return scanLetters(r, i+1)
+	}
+
+	// 最多3位数字
+	if unicode.IsNumber(c) {
+		j := i
+		for j < n && j-i < 3 && unicode.IsNumber(r[j]) {
+			j++
+		}
+		return j
+	}
+
+	// 标点，可以带一个前导空格和后续换行
+	j := i
+	if c == ' ' && i+1 < n && isPunct(r[i+1]) {
+		j++
+	}
+	if isPunct(r[j]) {
+		for j < n && isPunct(r[j]) {
+			j++
+		}
+		for j < n && (r[j] == '\r' || r[j] == '\n') {
+			j++
+		}
+		return j
+	}
+
+	// 空白：包含换行时到最后一个换行为止；后面还有文字时留下最后一个空白字符给下一个片段
+	for j < n && unicode.IsSpace(r[j]) {
+		j++
+	}
+	for k := j - 1; k >= i; k-- {
+		if r[k] == '\r' || r[k] == '\n' {
+			return k + 1
+		}
+	}
+	if j < n && j-i > 1 {
+		return j - 1
+	}
+	return j
+}
+
+// scanLetters 返回从i开始的连续字母的结束位置
+func scanLetters(r []rune, i int) int {
+	for i < len(r) && unicode.IsLetter(r[i]) {
+		i++
+	}
+	return i
+}
+
+// isPunct 是否是空白、字母和数字以外的字符
+func isPunct(c rune) bool {
+	return !unicode.IsSpace(c) && !unicode.IsLetter(c) && !unicode.IsNumber(c)
+}
+
+// hasFoldPrefix 不区分大小写地检查前缀
+func hasFoldPrefix(r []rune, prefix string) bool {
+	i := 0
+	for _, p := range prefix {
+		if i >= len(r) || unicode.ToLower(r[i]) != p {
+			return false
+		}
+		i++
+	}
+	return true
+}
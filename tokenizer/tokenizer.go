@@ -0,0 +1,92 @@
+// Package tokenizer 提供发送请求前计算token数的分词器，包括可以加载词表文件的BPE分词器和不需要词表的估算器
+package tokenizer
+
+import (
+	"math"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Tokenizer 计算文本的token数
+type Tokenizer interface {
+	Count(text string) int
+}
+
+// Estimator 不依赖词表的token估算器，适合中日韩文字较多的文本
+// 中日韩字符按每个字符计算，其他字符按UTF-8字节数计算
+type Estimator struct {
+	CJKTokensPerRune float64 // 每个中日韩字符的token数，0表示1
+	BytesPerToken    float64 // 其他字符每个token的字节数，0表示4
+}
+
+// Count 实现Tokenizer接口
+func (e Estimator) Count(text string) int {
+	cjkTokens := e.CJKTokensPerRune
+	if cjkTokens == 0 {
+		cjkTokens = 1
+	}
+	bytesPerToken := e.BytesPerToken
+	if bytesPerToken == 0 {
+		bytesPerToken = 4
+	}
+
+	cjk := 0
+	other := 0
+	for _, r := range text {
+		if isCJK(r) {
+			cjk++
+		} else {
+			other += utf8.RuneLen(r)
+		}
+	}
+	return int(math.Ceil(float64(cjk)*cjkTokens + float64(other)/bytesPerToken))
+}
+
+// isCJK 是否是中日韩字符
+func isCJK(r rune) bool {
+	return unicode.Is(unicode.Han, r) || unicode.Is(unicode.Hiragana, r) || unicode.Is(unicode.Katakana, r) || unicode.Is(unicode.Hangul, r)
+}
+
+// DefaultEstimator 没有为模型注册分词器时使用的估算器
+var DefaultEstimator Tokenizer = Estimator{}
+
+// registry 按服务商和模型名称注册的分词器
+var registry = struct {
+	sync.RWMutex
+	tokenizers map[string]map[string]Tokenizer
+}{tokenizers: make(map[string]map[string]Tokenizer)}
+
+// Register 为模型注册分词器，例如用 LoadTiktoken 加载的 cl100k_base
+// model 同时作为前缀使用：注册 "gpt-4" 后 "gpt-4-turbo" 和 "gpt-4:latest" 也会匹配
+func Register(provider, model string, tokenizer Tokenizer) {
+	registry.Lock()
+	defer registry.Unlock()
+	if registry.tokenizers[provider] == nil {
+		registry.tokenizers[provider] = make(map[string]Tokenizer)
+	}
+	registry.tokenizers[provider][model] = tokenizer
+}
+
+// For 返回模型的分词器
+// 先精确匹配，再匹配最长的前缀（前缀之后必须是 "-" 或 ":"），都不匹配时返回 DefaultEstimator
+func For(provider, model string) Tokenizer {
+	registry.RLock()
+	defer registry.RUnlock()
+	table := registry.tokenizers[provider]
+	if tokenizer, ok := table[model]; ok {
+		return tokenizer
+	}
+
+	best := ""
+	for prefix := range table {
+		if len(prefix) > len(best) && (strings.HasPrefix(model, prefix+"-") || strings.HasPrefix(model, prefix+":")) {
+			best = prefix
+		}
+	}
+	if best == "" {
+		return DefaultEstimator
+	}
+	return table[best]
+}
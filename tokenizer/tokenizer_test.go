@@ -0,0 +1,150 @@
+package tokenizer
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/png"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/yu1ec/go-anyllm/types"
+)
+
+func TestSplitCL100K(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"Hello world", []string{"Hello", " world"}},
+		{"I'm here's", []string{"I", "'m", " here", "'s"}},
+		{"12345 apples", []string{"123", "45", " apples"}},
+		{"a, b!\n\nc", []string{"a", ",", " b", "!\n\n", "c"}},
+		{"x   y", []string{"x", "  ", " y"}},
+		{"你好，世界", []string{"你好", "，世界"}},
+	}
+	for _, tt := range tests {
+		if got := SplitCL100K(tt.text); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%q: 期望 %q，得到 %q", tt.text, tt.want, got)
+		}
+	}
+}
+
+// tiktokenVocab 生成包含全部单字节token和给定合并结果的tiktoken词表
+func tiktokenVocab(merged ...string) string {
+	var vocab strings.Builder
+	for b := 0; b < 256; b++ {
+		fmt.Fprintf(&vocab, "%s %d\n", base64.StdEncoding.EncodeToString([]byte{byte(b)}), b)
+	}
+	for i, token := range merged {
+		fmt.Fprintf(&vocab, "%s %d\n", base64.StdEncoding.EncodeToString([]byte(token)), 256+i)
+	}
+	return vocab.String()
+}
+
+func TestParseTiktoken(t *testing.T) {
+	bpe, err := ParseTiktoken(strings.NewReader(tiktokenVocab("he", "ll", "hell", " w")), WithSpecialTokens(map[string]int{"<|end|>": 1000}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ids := bpe.Encode("hello world<|end|>")
+	want := []int{258, 'o', 259, 'o', 'r', 'l', 'd', 1000}
+	if !reflect.DeepEqual(ids, want) {
+		t.Errorf("期望 %v，得到 %v", want, ids)
+	}
+	if got := bpe.Decode(ids); got != "hello world<|end|>" {
+		t.Errorf("解码结果错误: %q", got)
+	}
+	if bpe.Count("你好") != 6 {
+		t.Errorf("没有合并规则的中文应按字节计算，得到 %d", bpe.Count("你好"))
+	}
+
+	if _, err := ParseTiktoken(strings.NewReader("aGk= 0\n")); err == nil {
+		t.Error("词表缺少单字节token时应返回错误")
+	}
+}
+
+func TestParseHuggingFace(t *testing.T) {
+	vocab := make(map[string]int)
+	for r, b := range byteLevelDecoder() {
+		vocab[string(r)] = int(b)
+	}
+	vocab["he"] = 300
+	vocab["Ġh"] = 301
+	data, _ := json.Marshal(map[string]interface{}{
+		"added_tokens": []map[string]interface{}{{"id": 400, "content": "<｜end▁of▁sentence｜>"}},
+		"model": map[string]interface{}{
+			"type":   "BPE",
+			"vocab":  vocab,
+			"merges": []interface{}{"h e", []string{"Ġ", "h"}},
+		},
+	})
+
+	bpe, err := ParseHuggingFace(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ids := bpe.Encode(" he<｜end▁of▁sentence｜>"); !reflect.DeepEqual(ids, []int{' ', 300, 400}) {
+		t.Errorf("期望按merges顺序合并，得到 %v", ids)
+	}
+}
+
+func TestEstimator(t *testing.T) {
+	if got := (Estimator{}).Count("你好世界 hello"); got != 6 {
+		t.Errorf("期望6，得到 %d", got)
+	}
+	if got := (Estimator{CJKTokensPerRune: 0.6}).Count("你好世界"); got != 3 {
+		t.Errorf("期望3，得到 %d", got)
+	}
+}
+
+func TestRegister(t *testing.T) {
+	custom := Estimator{BytesPerToken: 1}
+	Register("ollama", "llama3", custom)
+	if For("ollama", "llama3:8b") != Tokenizer(custom) {
+		t.Error("带标签的模型应匹配前缀")
+	}
+	if For("ollama", "llama30") != DefaultEstimator {
+		t.Error("未注册的模型应使用 DefaultEstimator")
+	}
+}
+
+func TestCountRequest(t *testing.T) {
+	counter := Estimator{BytesPerToken: 1}
+	req := &types.ChatCompletionRequest{
+		Messages: []types.ChatCompletionMessage{
+			types.NewTextMessage(types.RoleSystem, "be brief"),
+			types.NewMultiModalMessage(types.RoleUser, []types.MessageContent{
+				types.NewTextContent("hi"),
+				types.NewImageContent("https://example.com/a.png", types.ImageDetailLow),
+			}),
+		},
+	}
+	// 3 + (3+6+8) + (3+4+2+85)
+	if got := CountRequest(counter, req); got != 114 {
+		t.Errorf("期望114，得到 %d", got)
+	}
+
+	req.Tools = []types.Tool{{Type: types.ToolTypeFunction, Function: types.RequestToolFunction{Name: "f"}}}
+	if got := CountRequest(counter, req); got <= 114+toolOverheadTokens {
+		t.Errorf("工具定义应计入token数，得到 %d", got)
+	}
+}
+
+func TestImageTokens(t *testing.T) {
+	var buf bytes.Buffer
+	png.Encode(&buf, image.NewGray(image.Rect(0, 0, 2048, 4096)))
+	url := "data:image/png;base64," + base64.StdEncoding.EncodeToString(buf.Bytes())
+
+	// 2048x4096 -> 1024x2048 -> 768x1536，2x3个图块
+	if got := ImageTokens(types.ImageURL{URL: url}); got != 85+170*6 {
+		t.Errorf("期望1105，得到 %d", got)
+	}
+	if got := ImageTokens(types.ImageURL{URL: "https://example.com/a.png"}); got != 765 {
+		t.Errorf("期望765，得到 %d", got)
+	}
+}
@@ -0,0 +1,349 @@
+package tools
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/yu1ec/go-anyllm/types"
+)
+
+// ErrUnrepairableJSON 无法修复的JSON
+var ErrUnrepairableJSON = errors.New("tools: unable to repair JSON")
+
+// RepairJSON 尽力修复模型生成的不规范JSON
+//
+// 支持修复以下常见问题：
+//   - markdown代码块包裹（```json ... ```）
+//   - 对象或数组末尾多余的逗号
+//   - 单引号字符串
+//   - 未加引号的键名
+//   - Python风格的 True / False / None
+//   - 字符串中未转义的换行符
+//   - 被截断的字符串、对象和数组（自动补全）
+//
+// 返回修复后的JSON以及是否进行了修复；输入本身有效时原样返回。
+// 修复后仍无效时返回 ErrUnrepairableJSON。
+func RepairJSON(input string) (string, bool, error) {
+	if json.Valid([]byte(input)) {
+		return input, false, nil
+	}
+
+	s := strings.TrimSpace(stripCodeFence(input))
+	if s == "" {
+		return input, false, ErrUnrepairableJSON
+	}
+	if json.Valid([]byte(s)) {
+		return s, true, nil
+	}
+
+	repaired := (&jsonRepairer{input: s}).repair()
+	if !json.Valid([]byte(repaired)) {
+		return input, false, ErrUnrepairableJSON
+	}
+	return repaired, true, nil
+}
+
+// stripCodeFence 去除包裹整个JSON的markdown代码块
+//
+// 只有代码块之外没有JSON内容时才去除，JSON字符串中的 ``` 保持原样。
+func stripCodeFence(s string) string {
+	start := strings.Index(s, "```")
+	if start < 0 || strings.ContainsAny(s[:start], "{[") {
+		return s
+	}
+
+	body := s[start+3:]
+	// 跳过语言标识（如 ```json）
+	if newline := strings.IndexByte(body, '\n'); newline >= 0 {
+		lang := strings.TrimSpace(body[:newline])
+		if !strings.ContainsAny(lang, "{[") {
+			body = body[newline+1:]
+		}
+	}
+	// 结束标记取最后一个之后没有JSON内容的 ```，没有时视为被截断
+	if end := strings.LastIndex(body, "```"); end >= 0 && !strings.ContainsAny(body[end+3:], "}]") {
+		body = body[:end]
+	}
+	return body
+}
+
+// jsonRepairer JSON修复器
+type jsonRepairer struct {
+	input string
+	pos   int
+	out   strings.Builder
+	stack []byte // 未闭合的容器：'{' 或 '['
+}
+
+// repair 逐字符扫描并修复
+func (r *jsonRepairer) repair() string {
+	for r.pos < len(r.input) {
+		c := r.input[r.pos]
+		switch {
+		case c == '"' || c == '\'':
+			isKey := r.expectingKey()
+			if !r.readString(c) {
+				// 字符串被截断
+				if isKey {
+					r.out.WriteString(":null")
+				}
+				return r.closeAll()
+			}
+		case c == '{' || c == '[':
+			r.stack = append(r.stack, c)
+			r.out.WriteByte(c)
+			r.pos++
+		case c == '}' || c == ']':
+			r.trimTrailingComma()
+			if len(r.stack) > 0 && r.stack[len(r.stack)-1] == matchingOpen(c) {
+				r.stack = r.stack[:len(r.stack)-1]
+				r.out.WriteByte(c)
+			}
+			r.pos++
+		case c == '-' || (c >= '0' && c <= '9'):
+			if !r.readNumber() {
+				// 数字被截断且没有有效数字，丢弃不完整的成员
+				r.dropIncompleteMember()
+				return r.closeAll()
+			}
+		case isIdentStart(c):
+			r.readIdentifier()
+		default:
+			r.out.WriteByte(c)
+			r.pos++
+		}
+	}
+	return r.closeAll()
+}
+
+// expectingKey 检查当前位置是否应为对象的键
+func (r *jsonRepairer) expectingKey() bool {
+	if len(r.stack) == 0 || r.stack[len(r.stack)-1] != '{' {
+		return false
+	}
+	last := lastNonSpace(r.out.String())
+	return last == '{' || last == ','
+}
+
+// readString 读取字符串并统一转换为双引号形式，返回字符串是否正常结束
+func (r *jsonRepairer) readString(quote byte) bool {
+	r.out.WriteByte('"')
+	r.pos++
+	for r.pos < len(r.input) {
+		c := r.input[r.pos]
+		switch {
+		case c == '\\':
+			if r.pos+1 >= len(r.input) {
+				// 截断在转义符处，丢弃
+				r.pos++
+				r.out.WriteByte('"')
+				return false
+			}
+			next := r.input[r.pos+1]
+			if next == '\'' {
+				// JSON中单引号无需转义
+				r.out.WriteByte('\'')
+			} else {
+				r.out.WriteByte(c)
+				r.out.WriteByte(next)
+			}
+			r.pos += 2
+			continue
+		case c == quote:
+			r.out.WriteByte('"')
+			r.pos++
+			return true
+		case c == '"':
+			// 单引号字符串中的双引号需要转义
+			r.out.WriteString(`\"`)
+		case c == '\n':
+			r.out.WriteString(`\n`)
+		case c == '\r':
+			r.out.WriteString(`\r`)
+		case c == '\t':
+			r.out.WriteString(`\t`)
+		default:
+			r.out.WriteByte(c)
+		}
+		r.pos++
+	}
+	r.out.WriteByte('"')
+	return false
+}
+
+// readNumber 读取数字，截断在没有数字的位置（如末尾的 "-"）时返回false
+func (r *jsonRepairer) readNumber() bool {
+	start := r.pos
+	for r.pos < len(r.input) && strings.IndexByte("+-0123456789.eE", r.input[r.pos]) >= 0 {
+		r.pos++
+	}
+	num := r.input[start:r.pos]
+	// 截断的数字（如 "1." 或 "1e"）去掉不完整的部分
+	num = strings.TrimRight(num, "+-.eE")
+	if num == "" {
+		if r.pos >= len(r.input) {
+			return false
+		}
+		num = "0"
+	}
+	r.out.WriteString(num)
+	return true
+}
+
+// dropIncompleteMember 移除输出末尾没有值的对象键（"key":）
+func (r *jsonRepairer) dropIncompleteMember() {
+	out := strings.TrimRight(r.out.String(), " \t\r\n")
+	if !strings.HasSuffix(out, ":") || len(r.stack) == 0 || r.stack[len(r.stack)-1] != '{' {
+		return
+	}
+	out = strings.TrimRight(out[:len(out)-1], " \t\r\n")
+	if !strings.HasSuffix(out, `"`) {
+		return
+	}
+	// 向前查找键的起始引号，跳过转义的引号
+	for i := len(out) - 2; i >= 0; i-- {
+		if out[i] != '"' {
+			continue
+		}
+		backslashes := 0
+		for j := i - 1; j >= 0 && out[j] == '\\'; j-- {
+			backslashes++
+		}
+		if backslashes%2 == 0 {
+			r.out.Reset()
+			r.out.WriteString(out[:i])
+			return
+		}
+	}
+}
+
+// readIdentifier 读取未加引号的标识符
+func (r *jsonRepairer) readIdentifier() {
+	isKey := r.expectingKey()
+	start := r.pos
+	for r.pos < len(r.input) && isIdentPart(r.input[r.pos]) {
+		r.pos++
+	}
+	ident := r.input[start:r.pos]
+
+	if isKey {
+		r.out.WriteString(fmt.Sprintf("%q", ident))
+		return
+	}
+
+	switch ident {
+	case "true", "True":
+		r.out.WriteString("true")
+	case "false", "False":
+		r.out.WriteString("false")
+	case "null", "None", "undefined":
+		r.out.WriteString("null")
+	default:
+		if r.pos >= len(r.input) && isLiteralPrefix(ident) {
+			// 截断的字面量，如 "tr"、"fals"、"nu"
+			r.out.WriteString(completeLiteral(ident))
+			return
+		}
+		r.out.WriteString(fmt.Sprintf("%q", ident))
+	}
+}
+
+// trimTrailingComma 移除输出末尾多余的逗号
+func (r *jsonRepairer) trimTrailingComma() {
+	out := strings.TrimRight(r.out.String(), " \t\r\n")
+	if strings.HasSuffix(out, ",") {
+		out = out[:len(out)-1]
+		r.out.Reset()
+		r.out.WriteString(out)
+	}
+}
+
+// closeAll 补全被截断的结构
+func (r *jsonRepairer) closeAll() string {
+	out := strings.TrimRight(r.out.String(), " \t\r\n")
+	out = strings.TrimSuffix(out, ",")
+	if strings.HasSuffix(out, ":") {
+		out += "null"
+	}
+
+	var b strings.Builder
+	b.WriteString(out)
+	for i := len(r.stack) - 1; i >= 0; i-- {
+		if r.stack[i] == '{' {
+			b.WriteByte('}')
+		} else {
+			b.WriteByte(']')
+		}
+	}
+	return b.String()
+}
+
+func matchingOpen(c byte) byte {
+	if c == '}' {
+		return '{'
+	}
+	return '['
+}
+
+func isIdentStart(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || c == '_' || c == '$' || c >= 0x80
+}
+
+func isIdentPart(c byte) bool {
+	return isIdentStart(c) || (c >= '0' && c <= '9') || c == '-'
+}
+
+func lastNonSpace(s string) byte {
+	for i := len(s) - 1; i >= 0; i-- {
+		switch s[i] {
+		case ' ', '\t', '\r', '\n':
+			continue
+		}
+		return s[i]
+	}
+	return 0
+}
+
+var jsonLiterals = []string{"true", "false", "null"}
+
+func isLiteralPrefix(s string) bool {
+	return completeLiteral(s) != ""
+}
+
+func completeLiteral(s string) string {
+	for _, lit := range jsonLiterals {
+		if strings.HasPrefix(lit, s) {
+			return lit
+		}
+	}
+	return ""
+}
+
+// ParseToolCallArgumentsLenient 解析工具调用参数，解析失败时尝试修复JSON
+//
+// 返回值 repaired 表示参数是否经过修复。
+func ParseToolCallArgumentsLenient[T any](toolCall types.ToolCall) (T, bool, error) {
+	var result T
+
+	var raw string
+	switch params := toolCall.Function.Arguments.(type) {
+	case string:
+		raw = params
+	case []byte:
+		raw = string(params)
+	default:
+		value, err := ParseToolCallArguments[T](toolCall)
+		return value, false, err
+	}
+
+	repaired, changed, err := RepairJSON(raw)
+	if err != nil {
+		return result, false, fmt.Errorf("failed to parse tool call arguments: %w", err)
+	}
+	if err := json.Unmarshal([]byte(repaired), &result); err != nil {
+		return result, changed, fmt.Errorf("failed to parse tool call arguments: %w", err)
+	}
+	return result, changed, nil
+}
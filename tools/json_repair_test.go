@@ -0,0 +1,121 @@
+package tools
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/yu1ec/go-anyllm/response"
+	"github.com/yu1ec/go-anyllm/types"
+)
+
+func TestRepairJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    string
+		changed bool
+	}{
+		{"有效JSON", `{"a": 1}`, `{"a": 1}`, false},
+		{"末尾逗号", `{"a": 1, "b": [1, 2,],}`, `{"a":1,"b":[1,2]}`, true},
+		{"单引号", `{'a': 'it\'s "ok"'}`, `{"a":"it's \"ok\""}`, true},
+		{"未加引号的键", `{location: "北京", max_results: 3}`, `{"location":"北京","max_results":3}`, true},
+		{"Python字面量", `{"a": True, "b": None, "c": False}`, `{"a":true,"b":null,"c":false}`, true},
+		{"代码块", "```json\n{\"a\": 1}\n```", `{"a":1}`, true},
+		{"代码块前有说明文字", "参数如下：\n```\n{\"a\": [1, 2]}\n```", `{"a":[1,2]}`, true},
+		{"截断的对象", `{"a": {"b": [1, 2`, `{"a":{"b":[1,2]}}`, true},
+		{"截断的字符串", `{"content": "hello wor`, `{"content":"hello wor"}`, true},
+		{"截断的键", `{"a": 1, "lo`, `{"a":1,"lo":null}`, true},
+		{"截断在冒号后", `{"a": 1, "b":`, `{"a":1,"b":null}`, true},
+		{"截断的字面量", `{"a": tr`, `{"a":true}`, true},
+		{"截断的数字", `{"a": 1.`, `{"a":1}`, true},
+		{"截断的负号", `{"a": 1, "b": -`, `{"a":1}`, true},
+		{"数组中截断的负号", `[1, -`, `[1]`, true},
+		{"字符串中的代码块", "{\"code\": \"```go\nx\n```\", }", `{"code":"` + "```go\\nx\\n```" + `"}`, true},
+		{"代码块中的字符串包含代码块", "```json\n{\"code\": \"```go\nx\n```\"}\n```", `{"code":"` + "```go\\nx\\n```" + `"}`, true},
+		{"字符串中的换行", "{\"a\": \"line1\nline2\"}", `{"a":"line1\nline2"}`, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, changed, err := RepairJSON(tt.input)
+			if err != nil {
+				t.Fatalf("修复失败: %v", err)
+			}
+			if changed != tt.changed {
+				t.Errorf("changed = %v，期望 %v", changed, tt.changed)
+			}
+
+			var gotValue, wantValue interface{}
+			if err := json.Unmarshal([]byte(got), &gotValue); err != nil {
+				t.Fatalf("修复结果无效: %s", got)
+			}
+			if err := json.Unmarshal([]byte(tt.want), &wantValue); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(gotValue, wantValue) {
+				t.Errorf("修复结果 %s，期望 %s", got, tt.want)
+			}
+		})
+	}
+
+	if _, _, err := RepairJSON(""); err == nil {
+		t.Error("空字符串应该无法修复")
+	}
+}
+
+func TestParseToolCallArgumentsLenient(t *testing.T) {
+	type params struct {
+		Location string `json:"location"`
+	}
+
+	toolCall := types.ToolCall{
+		Function: types.ResponseToolFunction{Name: "get_weather", Arguments: `{location: '北京',}`},
+	}
+	got, repaired, err := ParseToolCallArgumentsLenient[params](toolCall)
+	if err != nil {
+		t.Fatalf("解析失败: %v", err)
+	}
+	if !repaired || got.Location != "北京" {
+		t.Errorf("期望修复后解析出北京，得到 %+v (repaired=%v)", got, repaired)
+	}
+
+	toolCall.Function.Arguments = `{"location": "上海"}`
+	got, repaired, err = ParseToolCallArgumentsLenient[params](toolCall)
+	if err != nil || repaired || got.Location != "上海" {
+		t.Errorf("有效JSON不应被修复，得到 %+v (repaired=%v, err=%v)", got, repaired, err)
+	}
+}
+
+func TestFinalizeStreamRepairsTruncatedArguments(t *testing.T) {
+	deltas := []*response.ToolCall{
+		{Id: "call_1", Type: "function", Function: response.ToolFunction{Name: "write_file", Arguments: `{"path": "a.txt", `}},
+		{Function: response.ToolFunction{Arguments: `"content": "hello`}},
+	}
+
+	// 默认不修复
+	acc := NewStreamingToolCallAccumulator()
+	acc.ProcessDelta(deltas)
+	if completed := acc.FinalizeStream(); len(completed) != 0 {
+		t.Fatalf("未开启修复时不应完成，得到 %+v", completed)
+	}
+
+	acc = NewStreamingToolCallAccumulator()
+	acc.SetRepairOnFinalize(true)
+	acc.ProcessDelta(deltas)
+	completed := acc.FinalizeStream()
+	if len(completed) != 1 {
+		t.Fatalf("期望修复后完成1个工具调用，得到 %d", len(completed))
+	}
+	if !acc.WasRepaired("call_1") {
+		t.Error("期望标记为已修复")
+	}
+
+	var args map[string]string
+	if err := json.Unmarshal([]byte(completed[0].Function.Arguments.(string)), &args); err != nil {
+		t.Fatalf("修复后的参数无效: %v", completed[0].Function.Arguments)
+	}
+	if args["content"] != "hello" || args["path"] != "a.txt" {
+		t.Errorf("修复后的参数错误: %+v", args)
+	}
+}
@@ -0,0 +1,190 @@
+package tools
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// PartialJSONParser 增量JSON解析器
+//
+// 逐字节跟踪嵌套层级和字符串状态，每次写入的开销只与片段长度有关，
+// 可在参数流式生成过程中以O(1)判断JSON是否结束，并按需返回尽力解析的部分结果。
+type PartialJSONParser struct {
+	buffer strings.Builder
+
+	stack       []byte // 未闭合的容器：'{' 或 '['
+	inString    bool
+	escape      bool
+	stringIsKey bool // 当前字符串是否为对象的键
+	lastToken   byte // 字符串外最后一个非空白字符（字符串结束记为'"'）
+
+	started  bool // 是否已经出现第一个非空白字符
+	complete bool // 顶层对象或数组是否已经闭合
+}
+
+// NewPartialJSONParser 创建增量JSON解析器
+func NewPartialJSONParser() *PartialJSONParser {
+	return &PartialJSONParser{}
+}
+
+// Write 追加JSON片段，返回顶层结构是否已闭合
+func (p *PartialJSONParser) Write(fragment string) bool {
+	p.buffer.WriteString(fragment)
+
+	for i := 0; i < len(fragment); i++ {
+		c := fragment[i]
+
+		if p.inString {
+			switch {
+			case p.escape:
+				p.escape = false
+			case c == '\\':
+				p.escape = true
+			case c == '"':
+				p.inString = false
+				p.lastToken = '"'
+			}
+			continue
+		}
+
+		switch c {
+		case ' ', '\t', '\r', '\n':
+			continue
+		case '"':
+			p.inString = true
+			p.stringIsKey = p.expectingKey()
+		case '{', '[':
+			p.stack = append(p.stack, c)
+		case '}', ']':
+			if len(p.stack) > 0 {
+				p.stack = p.stack[:len(p.stack)-1]
+				if len(p.stack) == 0 {
+					p.complete = true
+				}
+			}
+		}
+
+		p.started = true
+		if c != '"' {
+			p.lastToken = c
+		}
+	}
+
+	return p.complete
+}
+
+// expectingKey 检查下一个字符串是否为对象的键
+func (p *PartialJSONParser) expectingKey() bool {
+	if len(p.stack) == 0 || p.stack[len(p.stack)-1] != '{' {
+		return false
+	}
+	return p.lastToken == '{' || p.lastToken == ','
+}
+
+// IsComplete 检查顶层对象或数组是否已闭合
+func (p *PartialJSONParser) IsComplete() bool {
+	return p.complete
+}
+
+// Depth 返回当前未闭合的嵌套层级
+func (p *PartialJSONParser) Depth() int {
+	return len(p.stack)
+}
+
+// InString 检查当前是否处于字符串内部
+func (p *PartialJSONParser) InString() bool {
+	return p.inString
+}
+
+// String 返回已累积的原始JSON文本
+func (p *PartialJSONParser) String() string {
+	return p.buffer.String()
+}
+
+// Len 返回已累积的字节数
+func (p *PartialJSONParser) Len() int {
+	return p.buffer.Len()
+}
+
+// Reset 重置解析器状态
+func (p *PartialJSONParser) Reset() {
+	*p = PartialJSONParser{}
+}
+
+// Partial 返回尽力解析的部分结果
+//
+// 未闭合的字符串、对象和数组会被自动补全，例如 `{"path": "a.txt", "content": "hel`
+// 会被解析为 {"path": "a.txt", "content": "hel"}。尚未出现任何内容时返回 nil, false。
+func (p *PartialJSONParser) Partial() (interface{}, bool) {
+	if !p.started {
+		return nil, false
+	}
+
+	raw := p.buffer.String()
+	var value interface{}
+	if p.complete {
+		if err := json.Unmarshal([]byte(raw), &value); err == nil {
+			return value, true
+		}
+	}
+
+	if err := json.Unmarshal([]byte(p.closedJSON(raw)), &value); err == nil {
+		return value, true
+	}
+
+	// 截断在数字、字面量或转义序列中间等情况交给修复器处理
+	repaired, _, err := RepairJSON(raw)
+	if err != nil {
+		return nil, false
+	}
+	if err := json.Unmarshal([]byte(repaired), &value); err != nil {
+		return nil, false
+	}
+	return value, true
+}
+
+// PartialObject 返回尽力解析的部分对象，顶层不是对象时返回 nil, false
+func (p *PartialJSONParser) PartialObject() (map[string]interface{}, bool) {
+	value, ok := p.Partial()
+	if !ok {
+		return nil, false
+	}
+	obj, ok := value.(map[string]interface{})
+	return obj, ok
+}
+
+// closedJSON 根据当前状态补全未闭合的结构
+func (p *PartialJSONParser) closedJSON(raw string) string {
+	var b strings.Builder
+	b.Grow(len(raw) + len(p.stack) + 8)
+
+	if p.inString {
+		if p.escape {
+			raw = raw[:len(raw)-1]
+		}
+		b.WriteString(raw)
+		b.WriteByte('"')
+		if p.stringIsKey {
+			b.WriteString(":null")
+		}
+	} else {
+		raw = strings.TrimRight(raw, " \t\r\n")
+		raw = strings.TrimSuffix(raw, ",")
+		b.WriteString(raw)
+		if strings.HasSuffix(raw, ":") {
+			b.WriteString("null")
+		} else if p.lastToken == '"' && p.stringIsKey {
+			// 键已结束但还没有冒号
+			b.WriteString(":null")
+		}
+	}
+
+	for i := len(p.stack) - 1; i >= 0; i-- {
+		if p.stack[i] == '{' {
+			b.WriteByte('}')
+		} else {
+			b.WriteByte(']')
+		}
+	}
+	return b.String()
+}
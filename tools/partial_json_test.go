@@ -0,0 +1,106 @@
+package tools
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/yu1ec/go-anyllm/response"
+)
+
+func TestPartialJSONParserCompletion(t *testing.T) {
+	fragments := []string{`{"path": "a.txt", `, `"content": "包含 } 和 \" 的`, `文本", "opts": {"mode": [1, `, `2]}`, `}`}
+
+	parser := NewPartialJSONParser()
+	for i, fragment := range fragments {
+		complete := parser.Write(fragment)
+		if complete != (i == len(fragments)-1) {
+			t.Errorf("第%d个片段后 complete = %v", i, complete)
+		}
+	}
+	if parser.Depth() != 0 || parser.InString() {
+		t.Errorf("解析结束后状态错误: depth=%d inString=%v", parser.Depth(), parser.InString())
+	}
+
+	obj, ok := parser.PartialObject()
+	if !ok || obj["content"] != `包含 } 和 " 的文本` {
+		t.Errorf("完整解析结果错误: %+v", obj)
+	}
+}
+
+func TestPartialJSONParserPartial(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  map[string]interface{}
+	}{
+		{"字符串值中间", `{"path": "a.txt", "content": "hel`, map[string]interface{}{"path": "a.txt", "content": "hel"}},
+		{"键中间", `{"path": "a.txt", "con`, map[string]interface{}{"path": "a.txt", "con": nil}},
+		{"键结束", `{"path": "a.txt", "content"`, map[string]interface{}{"path": "a.txt", "content": nil}},
+		{"冒号后", `{"path": "a.txt", "content": `, map[string]interface{}{"path": "a.txt", "content": nil}},
+		{"逗号后", `{"path": "a.txt",`, map[string]interface{}{"path": "a.txt"}},
+		{"嵌套数组", `{"items": [{"id": 1}, {"id"`, map[string]interface{}{"items": []interface{}{map[string]interface{}{"id": float64(1)}, map[string]interface{}{"id": nil}}}},
+		{"转义符处截断", `{"content": "a\`, map[string]interface{}{"content": "a"}},
+		{"数字中间", `{"n": 12`, map[string]interface{}{"n": float64(12)}},
+		{"字面量中间", `{"ok": tr`, map[string]interface{}{"ok": true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parser := NewPartialJSONParser()
+			// 逐字节写入，验证增量状态跟踪
+			for _, c := range []byte(tt.input) {
+				parser.Write(string(c))
+			}
+			got, ok := parser.PartialObject()
+			if !ok {
+				t.Fatalf("无法解析部分结果: %s", tt.input)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("部分结果 %+v，期望 %+v", got, tt.want)
+			}
+		})
+	}
+
+	if _, ok := NewPartialJSONParser().Partial(); ok {
+		t.Error("空解析器不应返回结果")
+	}
+}
+
+func TestAccumulatorPartialArguments(t *testing.T) {
+	acc := NewStreamingToolCallAccumulator()
+	acc.ProcessDelta([]*response.ToolCall{{
+		Id:       "call_1",
+		Type:     "function",
+		Function: response.ToolFunction{Name: "write_file", Arguments: `{"path": "main.go", "content": "package ma`},
+	}})
+
+	partial, ok := acc.GetPartialArguments("call_1")
+	if !ok || partial["content"] != "package ma" {
+		t.Errorf("部分参数错误: %+v", partial)
+	}
+	if acc.GetCompletedCount() != 0 {
+		t.Error("参数未结束时不应完成")
+	}
+
+	acc.ProcessDelta([]*response.ToolCall{{Function: response.ToolFunction{Arguments: `in"}`}}})
+	if acc.GetCompletedCount() != 1 {
+		t.Error("参数结束后应完成")
+	}
+
+	if _, ok := acc.GetPartialArguments("missing"); ok {
+		t.Error("不存在的工具调用应返回false")
+	}
+}
+
+func BenchmarkAccumulatorLargeArguments(b *testing.B) {
+	fragment := strings.Repeat("x", 16)
+	for i := 0; i < b.N; i++ {
+		acc := NewStreamingToolCallAccumulator()
+		acc.ProcessDelta([]*response.ToolCall{{Id: "call_1", Function: response.ToolFunction{Name: "write_file", Arguments: `{"content": "`}}})
+		for j := 0; j < 2000; j++ {
+			acc.ProcessDelta([]*response.ToolCall{{Function: response.ToolFunction{Arguments: fragment}}})
+		}
+		acc.ProcessDelta([]*response.ToolCall{{Function: response.ToolFunction{Arguments: `"}`}}})
+	}
+}
@@ -0,0 +1,129 @@
+package tools
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/yu1ec/go-anyllm/response"
+)
+
+// TestAccumulatorInterleavedIndexes 模拟OpenAI/Qwen的并行工具调用：按index交错发送，ID只在第一个片段中
+func TestAccumulatorInterleavedIndexes(t *testing.T) {
+	chunks := []string{
+		`{"choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"id":"call_a","type":"function","function":{"name":"get_weather","arguments":""}}]}}]}`,
+		`{"choices":[{"index":0,"delta":{"tool_calls":[{"index":1,"id":"call_b","type":"function","function":{"name":"calculator","arguments":""}}]}}]}`,
+		`{"choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"function":{"arguments":"{\"location\":"}}]}}]}`,
+		`{"choices":[{"index":0,"delta":{"tool_calls":[{"index":1,"function":{"arguments":"{\"expression\":"}}]}}]}`,
+		`{"choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"function":{"arguments":" \"北京\"}"}}]}}]}`,
+		`{"choices":[{"index":0,"delta":{"tool_calls":[{"index":1,"function":{"arguments":" \"1+2\"}"}}]}}]}`,
+	}
+
+	acc := NewStreamingToolCallAccumulator()
+	for _, chunk := range chunks {
+		var resp response.ChatCompletionsResponse
+		if err := json.Unmarshal([]byte(chunk), &resp); err != nil {
+			t.Fatal(err)
+		}
+		for _, choice := range resp.Choices {
+			acc.ProcessChoiceDelta(choice.Index, choice.Delta.ToolCalls)
+		}
+	}
+
+	completed := acc.FinalizeStream()
+	if len(completed) != 2 {
+		t.Fatalf("期望2个工具调用，得到 %d", len(completed))
+	}
+
+	want := []struct {
+		id        string
+		arguments string
+	}{
+		{"call_a", `{"location": "北京"}`},
+		{"call_b", `{"expression": "1+2"}`},
+	}
+	for i, w := range want {
+		if completed[i].ID != w.id || completed[i].Function.Arguments != w.arguments {
+			t.Errorf("第%d个工具调用错误: %+v", i, completed[i])
+		}
+		if completed[i].Index == nil || *completed[i].Index != i {
+			t.Errorf("第%d个工具调用index错误: %v", i, completed[i].Index)
+		}
+	}
+}
+
+func TestAccumulatorIndexOrdering(t *testing.T) {
+	idx := func(i int) *int { return &i }
+
+	acc := NewStreamingToolCallAccumulator()
+	// 以逆序到达，结果应按index排序
+	for i := 4; i >= 0; i-- {
+		acc.ProcessDelta([]*response.ToolCall{{
+			Index:    idx(i),
+			Id:       "call_" + string(rune('a'+i)),
+			Function: response.ToolFunction{Name: "f", Arguments: `{}`},
+		}})
+	}
+	// 另一个choice的工具调用排在后面
+	acc.ProcessChoiceDelta(1, []*response.ToolCall{{
+		Index:    idx(0),
+		Id:       "call_choice1",
+		Function: response.ToolFunction{Name: "f", Arguments: `{}`},
+	}})
+
+	for round := 0; round < 10; round++ {
+		completed := acc.GetCompletedToolCalls()
+		if len(completed) != 6 {
+			t.Fatalf("期望6个工具调用，得到 %d", len(completed))
+		}
+		for i := 0; i < 5; i++ {
+			if completed[i].ID != "call_"+string(rune('a'+i)) {
+				t.Fatalf("第%d轮顺序错误: %v", round, completed)
+			}
+		}
+		if completed[5].ID != "call_choice1" {
+			t.Fatalf("不同choice的工具调用顺序错误: %v", completed)
+		}
+	}
+}
+
+func TestAccumulatorIndexWithoutID(t *testing.T) {
+	idx := func(i int) *int { return &i }
+
+	acc := NewStreamingToolCallAccumulator()
+	acc.ProcessDelta([]*response.ToolCall{{Index: idx(0), Function: response.ToolFunction{Name: "f", Arguments: `{"a":`}}})
+	acc.ProcessDelta([]*response.ToolCall{{Index: idx(0), Function: response.ToolFunction{Arguments: `1}`}}})
+
+	completed := acc.GetCompletedToolCalls()
+	if len(completed) != 1 || completed[0].ID != "call_0_0" || completed[0].Function.Arguments != `{"a":1}` {
+		t.Errorf("没有ID时应生成占位ID并正确累积，得到 %+v", completed)
+	}
+
+	// 同一index出现新ID时视为新的工具调用
+	acc.ClearCompleted()
+	acc.ProcessDelta([]*response.ToolCall{{Index: idx(0), Id: "call_x", Function: response.ToolFunction{Name: "f", Arguments: `{}`}}})
+	acc.ProcessDelta([]*response.ToolCall{{Index: idx(0), Id: "call_y", Function: response.ToolFunction{Name: "g", Arguments: `{`}}})
+	acc.ProcessDelta([]*response.ToolCall{{Index: idx(0), Function: response.ToolFunction{Arguments: `}`}}})
+
+	completed = acc.GetCompletedToolCalls()
+	if len(completed) != 2 || completed[0].ID != "call_x" || completed[1].ID != "call_y" {
+		t.Errorf("复用index的工具调用处理错误: %+v", completed)
+	}
+}
+
+func TestAccumulatorIDAfterPlaceholder(t *testing.T) {
+	idx := func(i int) *int { return &i }
+
+	acc := NewStreamingToolCallAccumulator()
+	acc.ProcessDelta([]*response.ToolCall{{Index: idx(0), Function: response.ToolFunction{Name: "f", Arguments: `{"a":`}}})
+	// 真实ID在后续片段中才出现
+	acc.ProcessDelta([]*response.ToolCall{{Index: idx(0), Id: "call_real", Function: response.ToolFunction{Arguments: `1`}}})
+	acc.ProcessDelta([]*response.ToolCall{{Index: idx(0), Function: response.ToolFunction{Arguments: `}`}}})
+
+	completed := acc.GetCompletedToolCalls()
+	if len(completed) != 1 {
+		t.Fatalf("期望1个工具调用，得到 %+v", completed)
+	}
+	if completed[0].ID != "call_real" || completed[0].Function.Name != "f" || completed[0].Function.Arguments != `{"a":1}` {
+		t.Errorf("占位ID应改为真实ID并继续累积，得到 %+v", completed[0])
+	}
+}
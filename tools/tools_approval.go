@@ -0,0 +1,207 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/yu1ec/go-anyllm/types"
+)
+
+// ToolPolicy 工具执行策略
+type ToolPolicy int
+
+const (
+	// PolicyAllow 直接执行
+	PolicyAllow ToolPolicy = iota
+	// PolicyDeny 禁止执行
+	PolicyDeny
+	// PolicyRequireApproval 执行前需要审批
+	PolicyRequireApproval
+)
+
+// String 返回策略名称
+func (p ToolPolicy) String() string {
+	switch p {
+	case PolicyAllow:
+		return "allow"
+	case PolicyDeny:
+		return "deny"
+	case PolicyRequireApproval:
+		return "require_approval"
+	default:
+		return fmt.Sprintf("ToolPolicy(%d)", int(p))
+	}
+}
+
+// ApprovalRequest 审批请求
+type ApprovalRequest struct {
+	ToolCall  types.ToolCall
+	Tool      *types.Tool            // 已注册的工具定义，没有定义时为nil
+	Arguments map[string]interface{} // 解析后的参数
+}
+
+// ApprovalDecision 审批结果
+type ApprovalDecision struct {
+	Approved  bool
+	Reason    string                 // 拒绝原因，会作为工具消息返回给模型
+	Arguments map[string]interface{} // 批准时非nil则替换原参数执行
+}
+
+// Approve 批准执行
+func Approve() ApprovalDecision {
+	return ApprovalDecision{Approved: true}
+}
+
+// ApproveWithArguments 使用修改后的参数批准执行
+func ApproveWithArguments(arguments map[string]interface{}) ApprovalDecision {
+	return ApprovalDecision{Approved: true, Arguments: arguments}
+}
+
+// Reject 拒绝执行
+func Reject(reason string) ApprovalDecision {
+	return ApprovalDecision{Reason: reason}
+}
+
+// ApprovalFunc 审批回调，返回error时工具调用失败且不会执行
+type ApprovalFunc func(ctx context.Context, request ApprovalRequest) (ApprovalDecision, error)
+
+// ToolRejectedError 工具调用被策略禁止或被审批拒绝
+type ToolRejectedError struct {
+	Tool   string
+	Reason string
+	Denied bool // true表示被策略禁止，false表示被审批拒绝
+}
+
+// Error 实现error接口
+func (e *ToolRejectedError) Error() string {
+	if e.Denied {
+		return fmt.Sprintf("tool %s is not allowed", e.Tool)
+	}
+	if e.Reason == "" {
+		return fmt.Sprintf("tool %s was rejected", e.Tool)
+	}
+	return fmt.Sprintf("tool %s was rejected: %s", e.Tool, e.Reason)
+}
+
+// SetToolPolicy 设置指定工具的执行策略
+func (fr *FunctionRegistry) SetToolPolicy(functionName string, policy ToolPolicy) {
+	fr.mutex.Lock()
+	defer fr.mutex.Unlock()
+
+	fr.policies[functionName] = policy
+}
+
+// SetDefaultPolicy 设置未单独配置策略的工具的执行策略（默认PolicyAllow）
+func (fr *FunctionRegistry) SetDefaultPolicy(policy ToolPolicy) {
+	fr.mutex.Lock()
+	defer fr.mutex.Unlock()
+
+	fr.defaultPolicy = policy
+}
+
+// RequireApproval 将指定工具设置为执行前需要审批
+func (fr *FunctionRegistry) RequireApproval(functionNames ...string) {
+	fr.mutex.Lock()
+	defer fr.mutex.Unlock()
+
+	for _, name := range functionNames {
+		fr.policies[name] = PolicyRequireApproval
+	}
+}
+
+// SetApprovalHandler 设置审批回调
+//
+// 需要审批的工具在没有设置回调时一律拒绝执行。
+func (fr *FunctionRegistry) SetApprovalHandler(approver ApprovalFunc) {
+	fr.mutex.Lock()
+	defer fr.mutex.Unlock()
+
+	fr.approver = approver
+}
+
+// GetToolPolicy 获取工具实际生效的执行策略
+func (fr *FunctionRegistry) GetToolPolicy(functionName string) ToolPolicy {
+	fr.mutex.RLock()
+	defer fr.mutex.RUnlock()
+
+	if policy, exists := fr.policies[functionName]; exists {
+		return policy
+	}
+	return fr.defaultPolicy
+}
+
+// authorize 按策略检查工具调用，需要审批时调用审批回调
+//
+// 返回实际执行的工具调用（参数可能被审批修改），不允许执行时返回error。
+func (fr *FunctionRegistry) authorize(ctx context.Context, toolCall types.ToolCall) (types.ToolCall, error) {
+	name := toolCall.Function.Name
+	switch fr.GetToolPolicy(name) {
+	case PolicyAllow:
+		return toolCall, nil
+	case PolicyDeny:
+		return toolCall, &ToolRejectedError{Tool: name, Denied: true}
+	}
+
+	fr.mutex.RLock()
+	approver := fr.approver
+	tool, hasTool := fr.tools[name]
+	fr.mutex.RUnlock()
+
+	if approver == nil {
+		return toolCall, &ToolRejectedError{Tool: name, Reason: "no approval handler configured"}
+	}
+
+	request := ApprovalRequest{ToolCall: toolCall}
+	if hasTool {
+		request.Tool = &tool
+	}
+	if !isEmptyArguments(toolCall.Function.Arguments) {
+		arguments, err := ParseToolCallArguments[map[string]interface{}](toolCall)
+		if err != nil {
+			return toolCall, err
+		}
+		request.Arguments = arguments
+	}
+	if request.Arguments == nil {
+		request.Arguments = map[string]interface{}{}
+	}
+
+	decision, err := approver(ctx, request)
+	if err != nil {
+		return toolCall, fmt.Errorf("approval failed: %w", err)
+	}
+	if !decision.Approved {
+		return toolCall, &ToolRejectedError{Tool: name, Reason: decision.Reason}
+	}
+
+	if decision.Arguments != nil {
+		data, err := json.Marshal(decision.Arguments)
+		if err != nil {
+			return toolCall, fmt.Errorf("failed to marshal approved arguments: %w", err)
+		}
+		toolCall.Function.Arguments = string(data)
+
+		// 修改后的参数同样需要符合Schema
+		if err := fr.ValidateToolCall(toolCall); err != nil {
+			return toolCall, err
+		}
+	}
+	return toolCall, nil
+}
+
+// rejectionFailure 构建被拒绝的工具调用结果
+func rejectionFailure(toolCallID string, err error) *ToolCallResult {
+	if validationErr, ok := err.(*SchemaValidationError); ok {
+		return validationFailure(toolCallID, validationErr)
+	}
+
+	result := &ToolCallResult{
+		ToolCallID: toolCallID,
+		Error:      err.Error(),
+	}
+	if _, ok := err.(*ToolRejectedError); ok {
+		result.Rejected = true
+	}
+	return result
+}
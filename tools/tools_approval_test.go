@@ -0,0 +1,131 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/yu1ec/go-anyllm/types"
+)
+
+// emailRecorder 记录收到的参数
+type emailRecorder struct {
+	calls []string
+}
+
+func (h *emailRecorder) HandleToolCall(toolCall types.ToolCall) (string, error) {
+	args, _ := toolCall.Function.Arguments.(string)
+	h.calls = append(h.calls, args)
+	return "sent", nil
+}
+
+func newEmailCall(arguments string) types.ToolCall {
+	return types.ToolCall{
+		ID:       "call_1",
+		Type:     "function",
+		Function: types.ResponseToolFunction{Name: "send_email", Arguments: arguments},
+	}
+}
+
+func TestApprovalPolicies(t *testing.T) {
+	handler := &emailRecorder{}
+	registry := NewFunctionRegistry()
+	registry.RegisterTool(SendEmailTool(), handler)
+	args := `{"to":"a@example.com","subject":"hi","body":"hello"}`
+
+	// 默认直接执行
+	if result := registry.Handle(newEmailCall(args)); result.Error != "" || len(handler.calls) != 1 {
+		t.Fatalf("默认策略应允许执行: %+v", result)
+	}
+
+	registry.SetToolPolicy("send_email", PolicyDeny)
+	result := registry.Handle(newEmailCall(args))
+	if !result.Rejected || len(handler.calls) != 1 {
+		t.Errorf("禁止的工具不应执行: %+v", result)
+	}
+
+	// 需要审批但没有回调时拒绝
+	registry.RequireApproval("send_email")
+	if result := registry.Handle(newEmailCall(args)); !result.Rejected || !strings.Contains(result.Error, "no approval handler") {
+		t.Errorf("没有审批回调时应拒绝: %+v", result)
+	}
+	if registry.GetToolPolicy("send_email") != PolicyRequireApproval || registry.GetToolPolicy("other") != PolicyAllow {
+		t.Error("策略查询错误")
+	}
+}
+
+func TestApprovalHandler(t *testing.T) {
+	handler := &emailRecorder{}
+	registry := NewFunctionRegistry()
+	registry.RegisterTool(SendEmailTool(), handler)
+	registry.RequireApproval("send_email")
+
+	var requests []ApprovalRequest
+	registry.SetApprovalHandler(func(ctx context.Context, request ApprovalRequest) (ApprovalDecision, error) {
+		requests = append(requests, request)
+		switch request.Arguments["to"] {
+		case "boss@example.com":
+			return Reject("不能给老板发邮件"), nil
+		case "typo@exampel.com":
+			edited := request.Arguments
+			edited["to"] = "typo@example.com"
+			return ApproveWithArguments(edited), nil
+		case "invalid@example.com":
+			return ApproveWithArguments(map[string]interface{}{"to": "invalid@example.com"}), nil
+		case "error@example.com":
+			return ApprovalDecision{}, errors.New("审批服务不可用")
+		}
+		return Approve(), nil
+	})
+
+	result := registry.Handle(newEmailCall(`{"to":"a@example.com","subject":"s","body":"b"}`))
+	if result.Error != "" || result.Content != "sent" {
+		t.Errorf("批准后应执行: %+v", result)
+	}
+	if len(requests) != 1 || requests[0].Tool == nil || requests[0].Arguments["subject"] != "s" {
+		t.Errorf("审批请求错误: %+v", requests)
+	}
+
+	result = registry.Handle(newEmailCall(`{"to":"boss@example.com","subject":"s","body":"b"}`))
+	if !result.Rejected || len(handler.calls) != 1 {
+		t.Fatalf("拒绝后不应执行: %+v", result)
+	}
+	message := result.ToToolMessage()
+	content, _ := message.Content.(string)
+	if message.ToolCallID != "call_1" || !strings.Contains(content, "not executed") || !strings.Contains(content, "不能给老板发邮件") {
+		t.Errorf("拒绝原因应返回给模型: %q", content)
+	}
+
+	result = registry.Handle(newEmailCall(`{"to":"typo@exampel.com","subject":"s","body":"b"}`))
+	if result.Error != "" || !strings.Contains(handler.calls[1], `"to":"typo@example.com"`) {
+		t.Errorf("应使用修改后的参数执行: %+v %v", result, handler.calls)
+	}
+
+	// 修改后的参数不符合Schema时不执行
+	result = registry.Handle(newEmailCall(`{"to":"invalid@example.com","subject":"s","body":"b"}`))
+	if len(result.Violations) == 0 || len(handler.calls) != 2 {
+		t.Errorf("修改后的参数应重新校验: %+v", result)
+	}
+
+	result = registry.Handle(newEmailCall(`{"to":"error@example.com","subject":"s","body":"b"}`))
+	if result.Rejected || !strings.Contains(result.Error, "审批服务不可用") || len(handler.calls) != 2 {
+		t.Errorf("审批失败时不应执行: %+v", result)
+	}
+}
+
+func TestApprovalStreaming(t *testing.T) {
+	registry := NewFunctionRegistry()
+	registry.RegisterTool(SendEmailTool(), &emailRecorder{})
+	registry.SetDefaultPolicy(PolicyDeny)
+
+	chunks, err := registry.HandleStreaming(newEmailCall(`{"to":"a@example.com","subject":"s","body":"b"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	chunk := <-chunks
+	var rejected *ToolRejectedError
+	if !errors.As(chunk.Error, &rejected) || !rejected.Denied {
+		t.Errorf("流式调用同样应遵守策略: %+v", chunk)
+	}
+}
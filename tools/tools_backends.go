@@ -0,0 +1,190 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/yu1ec/go-anyllm/types"
+)
+
+// 搜索结果数量的默认值和上限
+const (
+	defaultSearchResults = 5
+	maxSearchResults     = 20
+)
+
+// WeatherReport 天气信息
+type WeatherReport struct {
+	Location    string  `json:"location"`
+	Temperature float64 `json:"temperature"`
+	Unit        string  `json:"unit"`
+	Condition   string  `json:"condition"`
+	Humidity    float64 `json:"humidity,omitempty"` // 相对湿度百分比
+}
+
+// WeatherBackend 天气数据源，由使用者接入具体的天气服务
+type WeatherBackend interface {
+	GetWeather(ctx context.Context, location, unit string) (*WeatherReport, error)
+}
+
+// WeatherBackendFunc 函数形式的天气数据源
+type WeatherBackendFunc func(ctx context.Context, location, unit string) (*WeatherReport, error)
+
+// GetWeather 实现WeatherBackend接口
+func (f WeatherBackendFunc) GetWeather(ctx context.Context, location, unit string) (*WeatherReport, error) {
+	return f(ctx, location, unit)
+}
+
+// WeatherHandler GetWeatherTool 的处理器
+type WeatherHandler struct {
+	Backend WeatherBackend
+}
+
+// HandleToolCall 实现ToolCallHandler接口
+func (h WeatherHandler) HandleToolCall(toolCall types.ToolCall) (string, error) {
+	return h.HandleToolCallContext(context.Background(), toolCall)
+}
+
+// HandleToolCallContext 实现ContextToolCallHandler接口
+func (h WeatherHandler) HandleToolCallContext(ctx context.Context, toolCall types.ToolCall) (string, error) {
+	if h.Backend == nil {
+		return "", errors.New("get_weather: no weather backend configured")
+	}
+	args, err := ParseToolCallArguments[struct {
+		Location string `json:"location"`
+		Unit     string `json:"unit"`
+	}](toolCall)
+	if err != nil {
+		return "", err
+	}
+	if args.Unit == "" {
+		args.Unit = "celsius"
+	}
+
+	report, err := h.Backend.GetWeather(ctx, args.Location, args.Unit)
+	if err != nil {
+		return "", err
+	}
+	return encodeToolResult(report)
+}
+
+// SearchResult 搜索结果
+type SearchResult struct {
+	Title   string `json:"title"`
+	URL     string `json:"url"`
+	Snippet string `json:"snippet,omitempty"`
+}
+
+// SearchBackend 搜索服务，由使用者接入具体的搜索引擎
+type SearchBackend interface {
+	Search(ctx context.Context, query string, maxResults int) ([]SearchResult, error)
+}
+
+// SearchBackendFunc 函数形式的搜索服务
+type SearchBackendFunc func(ctx context.Context, query string, maxResults int) ([]SearchResult, error)
+
+// Search 实现SearchBackend接口
+func (f SearchBackendFunc) Search(ctx context.Context, query string, maxResults int) ([]SearchResult, error) {
+	return f(ctx, query, maxResults)
+}
+
+// SearchHandler SearchTool 的处理器
+type SearchHandler struct {
+	Backend SearchBackend
+}
+
+// HandleToolCall 实现ToolCallHandler接口
+func (h SearchHandler) HandleToolCall(toolCall types.ToolCall) (string, error) {
+	return h.HandleToolCallContext(context.Background(), toolCall)
+}
+
+// HandleToolCallContext 实现ContextToolCallHandler接口
+func (h SearchHandler) HandleToolCallContext(ctx context.Context, toolCall types.ToolCall) (string, error) {
+	if h.Backend == nil {
+		return "", errors.New("search: no search backend configured")
+	}
+	args, err := ParseToolCallArguments[struct {
+		Query      string `json:"query"`
+		MaxResults int    `json:"max_results"`
+	}](toolCall)
+	if err != nil {
+		return "", err
+	}
+	if strings.TrimSpace(args.Query) == "" {
+		return "", errors.New("search: query is empty")
+	}
+	if args.MaxResults <= 0 {
+		args.MaxResults = defaultSearchResults
+	}
+	if args.MaxResults > maxSearchResults {
+		args.MaxResults = maxSearchResults
+	}
+
+	results, err := h.Backend.Search(ctx, args.Query, args.MaxResults)
+	if err != nil {
+		return "", err
+	}
+	if len(results) > args.MaxResults {
+		results = results[:args.MaxResults]
+	}
+	if results == nil {
+		results = []SearchResult{}
+	}
+	return encodeToolResult(map[string]interface{}{
+		"query":   args.Query,
+		"results": results,
+	})
+}
+
+// EmailMessage 待发送的邮件
+type EmailMessage struct {
+	To      string `json:"to"`
+	Subject string `json:"subject"`
+	Body    string `json:"body"`
+	CC      string `json:"cc,omitempty"`
+}
+
+// EmailSender 邮件发送服务，由使用者接入具体的邮件服务
+type EmailSender interface {
+	SendEmail(ctx context.Context, message EmailMessage) error
+}
+
+// EmailSenderFunc 函数形式的邮件发送服务
+type EmailSenderFunc func(ctx context.Context, message EmailMessage) error
+
+// SendEmail 实现EmailSender接口
+func (f EmailSenderFunc) SendEmail(ctx context.Context, message EmailMessage) error {
+	return f(ctx, message)
+}
+
+// EmailHandler SendEmailTool 的处理器
+//
+// 发送邮件有副作用，建议同时通过 FunctionRegistry.RequireApproval 设置执行前审批。
+type EmailHandler struct {
+	Sender EmailSender
+}
+
+// HandleToolCall 实现ToolCallHandler接口
+func (h EmailHandler) HandleToolCall(toolCall types.ToolCall) (string, error) {
+	return h.HandleToolCallContext(context.Background(), toolCall)
+}
+
+// HandleToolCallContext 实现ContextToolCallHandler接口
+func (h EmailHandler) HandleToolCallContext(ctx context.Context, toolCall types.ToolCall) (string, error) {
+	if h.Sender == nil {
+		return "", errors.New("send_email: no email sender configured")
+	}
+	message, err := ParseToolCallArguments[EmailMessage](toolCall)
+	if err != nil {
+		return "", err
+	}
+
+	if err := h.Sender.SendEmail(ctx, message); err != nil {
+		return "", err
+	}
+	return encodeToolResult(map[string]string{
+		"status": "sent",
+		"to":     message.To,
+	})
+}
@@ -0,0 +1,57 @@
+package tools
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/yu1ec/go-anyllm/types"
+)
+
+func TestSearchHandler(t *testing.T) {
+	var gotMax int
+	backend := SearchBackendFunc(func(ctx context.Context, query string, maxResults int) ([]SearchResult, error) {
+		gotMax = maxResults
+		return []SearchResult{
+			{Title: "Go", URL: "https://go.dev"},
+			{Title: "Go Blog", URL: "https://go.dev/blog"},
+		}, nil
+	})
+
+	registry := NewFunctionRegistry()
+	registry.RegisterTool(SearchTool(), SearchHandler{Backend: backend})
+
+	result := registry.Handle(types.ToolCall{ID: "1", Function: types.ResponseToolFunction{Name: "search", Arguments: `{"query":"golang","max_results":1}`}})
+	if result.Error != "" || gotMax != 1 || strings.Contains(result.Content, "Go Blog") {
+		t.Errorf("搜索结果错误: %+v (max=%d)", result, gotMax)
+	}
+
+	registry.Handle(types.ToolCall{ID: "2", Function: types.ResponseToolFunction{Name: "search", Arguments: `{"query":"golang"}`}})
+	if gotMax != defaultSearchResults {
+		t.Errorf("期望默认结果数 %d，得到 %d", defaultSearchResults, gotMax)
+	}
+
+	if _, err := (SearchHandler{}).HandleToolCall(types.ToolCall{Function: types.ResponseToolFunction{Arguments: `{"query":"golang"}`}}); err == nil || !strings.Contains(err.Error(), "no search backend") {
+		t.Errorf("未配置后端时应返回错误，得到 %v", err)
+	}
+}
+
+func TestWeatherAndEmailHandlers(t *testing.T) {
+	weather := WeatherHandler{Backend: WeatherBackendFunc(func(ctx context.Context, location, unit string) (*WeatherReport, error) {
+		return &WeatherReport{Location: location, Temperature: 20, Unit: unit, Condition: "晴"}, nil
+	})}
+	content, err := weather.HandleToolCall(types.ToolCall{Function: types.ResponseToolFunction{Arguments: `{"location":"北京"}`}})
+	if err != nil || content != `{"location":"北京","temperature":20,"unit":"celsius","condition":"晴"}` {
+		t.Errorf("天气结果错误: %s %v", content, err)
+	}
+
+	var sent EmailMessage
+	email := EmailHandler{Sender: EmailSenderFunc(func(ctx context.Context, message EmailMessage) error {
+		sent = message
+		return nil
+	})}
+	content, err = email.HandleToolCall(types.ToolCall{Function: types.ResponseToolFunction{Arguments: `{"to":"a@example.com","subject":"hi","body":"hello"}`}})
+	if err != nil || sent.Subject != "hi" || !strings.Contains(content, `"status":"sent"`) {
+		t.Errorf("邮件发送错误: %s %v %+v", content, err, sent)
+	}
+}
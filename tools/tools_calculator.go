@@ -0,0 +1,336 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/yu1ec/go-anyllm/types"
+)
+
+// 表达式长度和嵌套深度限制，防止恶意输入消耗过多资源
+const (
+	maxExpressionLength = 1024
+	maxExpressionDepth  = 64
+)
+
+// CalculatorHandler CalculatorTool 的参考实现，只做算术运算，不执行任何代码
+type CalculatorHandler struct{}
+
+// CalculatorResult 计算结果
+type CalculatorResult struct {
+	Expression string  `json:"expression"`
+	Result     float64 `json:"result"`
+}
+
+// HandleToolCall 实现ToolCallHandler接口
+func (h CalculatorHandler) HandleToolCall(toolCall types.ToolCall) (string, error) {
+	return h.HandleToolCallContext(context.Background(), toolCall)
+}
+
+// HandleToolCallContext 实现ContextToolCallHandler接口
+func (h CalculatorHandler) HandleToolCallContext(ctx context.Context, toolCall types.ToolCall) (string, error) {
+	args, err := ParseToolCallArguments[struct {
+		Expression string `json:"expression"`
+	}](toolCall)
+	if err != nil {
+		return "", err
+	}
+
+	value, err := EvaluateExpression(args.Expression)
+	if err != nil {
+		return "", err
+	}
+	return encodeToolResult(CalculatorResult{Expression: args.Expression, Result: value})
+}
+
+// EvaluateExpression 计算算术表达式
+//
+// 支持 + - * / % ^（乘方，也可写作 **）、括号、一元正负号、科学计数法，
+// 常量 pi、e，以及函数 sqrt、abs、floor、ceil、round、ln、log（以10为底）、exp、sin、cos、tan、min、max、pow。
+func EvaluateExpression(expression string) (float64, error) {
+	if len(expression) > maxExpressionLength {
+		return 0, fmt.Errorf("calculator: expression is too long (max %d characters)", maxExpressionLength)
+	}
+
+	p := &expressionParser{input: operatorReplacer.Replace(expression)}
+	value, err := p.parseExpression()
+	if err != nil {
+		return 0, err
+	}
+	p.skipSpaces()
+	if p.pos < len(p.input) {
+		return 0, p.errorf("unexpected %q", p.input[p.pos])
+	}
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return 0, fmt.Errorf("calculator: result is not a finite number")
+	}
+	return value, nil
+}
+
+// operatorReplacer 将模型常用的运算符写法统一为解析器支持的形式
+var operatorReplacer = strings.NewReplacer("×", "*", "÷", "/", "**", "^")
+
+// expressionParser 递归下降表达式解析器
+type expressionParser struct {
+	input string
+	pos   int
+	depth int
+}
+
+func (p *expressionParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("calculator: %s at position %d", fmt.Sprintf(format, args...), p.pos)
+}
+
+func (p *expressionParser) skipSpaces() {
+	for p.pos < len(p.input) && unicode.IsSpace(rune(p.input[p.pos])) {
+		p.pos++
+	}
+}
+
+// peek 返回下一个非空白字符，到达末尾时返回0
+func (p *expressionParser) peek() byte {
+	p.skipSpaces()
+	if p.pos >= len(p.input) {
+		return 0
+	}
+	return p.input[p.pos]
+}
+
+// parseExpression expression := term (('+' | '-') term)*
+func (p *expressionParser) parseExpression() (float64, error) {
+	p.depth++
+	defer func() { p.depth-- }()
+	if p.depth > maxExpressionDepth {
+		return 0, p.errorf("expression is nested too deeply")
+	}
+
+	left, err := p.parseTerm()
+	if err != nil {
+		return 0, err
+	}
+	for {
+		op := p.peek()
+		if op != '+' && op != '-' {
+			return left, nil
+		}
+		p.pos++
+		right, err := p.parseTerm()
+		if err != nil {
+			return 0, err
+		}
+		if op == '+' {
+			left += right
+		} else {
+			left -= right
+		}
+	}
+}
+
+// parseTerm term := unary (('*' | '/' | '%') unary)*
+func (p *expressionParser) parseTerm() (float64, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return 0, err
+	}
+	for {
+		op := p.peek()
+		if op != '*' && op != '/' && op != '%' {
+			return left, nil
+		}
+		p.pos++
+		right, err := p.parseUnary()
+		if err != nil {
+			return 0, err
+		}
+		switch op {
+		case '*':
+			left *= right
+		case '/':
+			if right == 0 {
+				return 0, p.errorf("division by zero")
+			}
+			left /= right
+		case '%':
+			if right == 0 {
+				return 0, p.errorf("modulo by zero")
+			}
+			left = math.Mod(left, right)
+		}
+	}
+}
+
+// parseUnary unary := ('+' | '-') unary | power
+func (p *expressionParser) parseUnary() (float64, error) {
+	switch p.peek() {
+	case '+':
+		p.pos++
+		return p.parseUnary()
+	case '-':
+		p.pos++
+		value, err := p.parseUnary()
+		return -value, err
+	}
+	return p.parsePower()
+}
+
+// parsePower power := primary ('^' unary)?，右结合，-2^2 = -4
+func (p *expressionParser) parsePower() (float64, error) {
+	base, err := p.parsePrimary()
+	if err != nil {
+		return 0, err
+	}
+	if p.peek() != '^' {
+		return base, nil
+	}
+	p.pos++
+
+	p.depth++
+	defer func() { p.depth-- }()
+	if p.depth > maxExpressionDepth {
+		return 0, p.errorf("expression is nested too deeply")
+	}
+	exponent, err := p.parseUnary()
+	if err != nil {
+		return 0, err
+	}
+	return math.Pow(base, exponent), nil
+}
+
+// parsePrimary primary := number | constant | function '(' args ')' | '(' expression ')'
+func (p *expressionParser) parsePrimary() (float64, error) {
+	c := p.peek()
+	switch {
+	case c == '(':
+		p.pos++
+		value, err := p.parseExpression()
+		if err != nil {
+			return 0, err
+		}
+		if p.peek() != ')' {
+			return 0, p.errorf("missing closing parenthesis")
+		}
+		p.pos++
+		return value, nil
+	case c >= '0' && c <= '9' || c == '.':
+		return p.parseNumber()
+	case unicode.IsLetter(rune(c)):
+		return p.parseIdentifier()
+	case c == 0:
+		return 0, p.errorf("unexpected end of expression")
+	default:
+		return 0, p.errorf("unexpected %q", c)
+	}
+}
+
+func (p *expressionParser) parseNumber() (float64, error) {
+	start := p.pos
+	for p.pos < len(p.input) {
+		c := p.input[p.pos]
+		if c >= '0' && c <= '9' || c == '.' {
+			p.pos++
+			continue
+		}
+		// 科学计数法，如 1e-3
+		if (c == 'e' || c == 'E') && p.pos+1 < len(p.input) {
+			next := p.input[p.pos+1]
+			if next >= '0' && next <= '9' {
+				p.pos += 2
+				continue
+			}
+			if (next == '+' || next == '-') && p.pos+2 < len(p.input) && p.input[p.pos+2] >= '0' && p.input[p.pos+2] <= '9' {
+				p.pos += 3
+				continue
+			}
+		}
+		break
+	}
+
+	text := p.input[start:p.pos]
+	value, err := strconv.ParseFloat(text, 64)
+	if err != nil {
+		p.pos = start
+		return 0, p.errorf("invalid number %q", text)
+	}
+	return value, nil
+}
+
+// 单参数函数
+var unaryFunctions = map[string]func(float64) float64{
+	"sqrt":  math.Sqrt,
+	"abs":   math.Abs,
+	"floor": math.Floor,
+	"ceil":  math.Ceil,
+	"round": math.Round,
+	"ln":    math.Log,
+	"log":   math.Log10,
+	"exp":   math.Exp,
+	"sin":   math.Sin,
+	"cos":   math.Cos,
+	"tan":   math.Tan,
+}
+
+// 双参数函数
+var binaryFunctions = map[string]func(float64, float64) float64{
+	"min": math.Min,
+	"max": math.Max,
+	"pow": math.Pow,
+}
+
+func (p *expressionParser) parseIdentifier() (float64, error) {
+	start := p.pos
+	for p.pos < len(p.input) && (unicode.IsLetter(rune(p.input[p.pos])) || unicode.IsDigit(rune(p.input[p.pos]))) {
+		p.pos++
+	}
+	name := strings.ToLower(p.input[start:p.pos])
+
+	switch name {
+	case "pi":
+		return math.Pi, nil
+	case "e":
+		return math.E, nil
+	}
+
+	unary, isUnary := unaryFunctions[name]
+	binary, isBinary := binaryFunctions[name]
+	if !isUnary && !isBinary {
+		p.pos = start
+		return 0, p.errorf("unknown identifier %q", name)
+	}
+
+	if p.peek() != '(' {
+		return 0, p.errorf("expected '(' after %s", name)
+	}
+	p.pos++
+
+	var args []float64
+	for {
+		value, err := p.parseExpression()
+		if err != nil {
+			return 0, err
+		}
+		args = append(args, value)
+		if p.peek() != ',' {
+			break
+		}
+		p.pos++
+	}
+	if p.peek() != ')' {
+		return 0, p.errorf("missing closing parenthesis")
+	}
+	p.pos++
+
+	if isUnary {
+		if len(args) != 1 {
+			return 0, p.errorf("%s expects 1 argument, got %d", name, len(args))
+		}
+		return unary(args[0]), nil
+	}
+	if len(args) != 2 {
+		return 0, p.errorf("%s expects 2 arguments, got %d", name, len(args))
+	}
+	return binary(args[0], args[1]), nil
+}
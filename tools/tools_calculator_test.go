@@ -0,0 +1,91 @@
+package tools
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/yu1ec/go-anyllm/types"
+)
+
+func TestEvaluateExpression(t *testing.T) {
+	tests := []struct {
+		expression string
+		want       float64
+	}{
+		{"1 + 2 * 3", 7},
+		{"(1 + 2) * 3", 9},
+		{"10 / 4", 2.5},
+		{"10 % 3", 1},
+		{"2 ^ 3 ^ 2", 512},
+		{"2 ** 10", 1024},
+		{"-2 ^ 2", -4},
+		{"--3", 3},
+		{"1.5e3 + .5", 1500.5},
+		{"2E-1", 0.2},
+		{"sqrt(16) + abs(-2)", 6},
+		{"max(1, min(5, 3))", 3},
+		{"round(pi * 100) / 100", 3.14},
+		{"log(1000) + ln(e)", 4},
+		{"3 × 4 ÷ 2", 6},
+	}
+
+	for _, tt := range tests {
+		got, err := EvaluateExpression(tt.expression)
+		if err != nil {
+			t.Errorf("%s: 意外错误 %v", tt.expression, err)
+			continue
+		}
+		if math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("%s: 期望 %v，得到 %v", tt.expression, tt.want, got)
+		}
+	}
+}
+
+func TestEvaluateExpressionErrors(t *testing.T) {
+	tests := []struct {
+		expression string
+		message    string
+	}{
+		{"", "unexpected end"},
+		{"1 +", "unexpected end"},
+		{"(1 + 2", "missing closing parenthesis"},
+		{"1 / 0", "division by zero"},
+		{"2 3", "unexpected"},
+		{"os.Exit(1)", "unknown identifier"},
+		{"sqrt 4", "expected '('"},
+		{"max(1)", "expects 2 arguments"},
+		{"sqrt(-1)", "not a finite number"},
+		{"1..2", "invalid number"},
+		{strings.Repeat("(", 100) + "1" + strings.Repeat(")", 100), "nested too deeply"},
+		{strings.Repeat("1+", 600) + "1", "too long"},
+	}
+
+	for _, tt := range tests {
+		_, err := EvaluateExpression(tt.expression)
+		if err == nil || !strings.Contains(err.Error(), tt.message) {
+			t.Errorf("%q: 期望包含 %q 的错误，得到 %v", tt.expression, tt.message, err)
+		}
+	}
+}
+
+func TestCalculatorHandler(t *testing.T) {
+	registry := NewFunctionRegistry()
+	registry.RegisterTool(CalculatorTool(), CalculatorHandler{})
+
+	result := registry.Handle(types.ToolCall{
+		ID:       "call_1",
+		Function: types.ResponseToolFunction{Name: "calculator", Arguments: `{"expression":"2+3*4"}`},
+	})
+	if result.Error != "" || result.Content != `{"expression":"2+3*4","result":14}` {
+		t.Errorf("计算结果错误: %+v", result)
+	}
+
+	result = registry.Handle(types.ToolCall{
+		ID:       "call_2",
+		Function: types.ResponseToolFunction{Name: "calculator", Arguments: `{"expression":"1/0"}`},
+	})
+	if !strings.Contains(result.Error, "division by zero") {
+		t.Errorf("期望除零错误，得到 %+v", result)
+	}
+}
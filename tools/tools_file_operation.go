@@ -0,0 +1,303 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/yu1ec/go-anyllm/types"
+)
+
+// 文件操作默认限制
+const (
+	defaultMaxReadBytes = 1 << 20
+	maxListEntries      = 1000
+)
+
+// FileOperationHandler FileOperationTool 的参考实现
+//
+// 所有路径都相对于根目录解析，包含 .. 或通过符号链接指向根目录之外的路径会被拒绝。
+// 默认支持 read、write、list，delete 需要通过 WithFileDelete 显式开启。
+type FileOperationHandler struct {
+	root         string
+	maxReadBytes int64
+	readOnly     bool
+	allowDelete  bool
+}
+
+// FileOperationOption 文件操作选项
+type FileOperationOption func(*FileOperationHandler)
+
+// WithMaxReadBytes 设置读取文件的最大字节数，超出部分被截断（默认1MB）
+func WithMaxReadBytes(n int64) FileOperationOption {
+	return func(h *FileOperationHandler) {
+		h.maxReadBytes = n
+	}
+}
+
+// WithReadOnly 禁止写入和删除
+func WithReadOnly() FileOperationOption {
+	return func(h *FileOperationHandler) {
+		h.readOnly = true
+	}
+}
+
+// WithFileDelete 允许删除文件
+func WithFileDelete() FileOperationOption {
+	return func(h *FileOperationHandler) {
+		h.allowDelete = true
+	}
+}
+
+// NewFileOperationHandler 创建限制在root目录内的文件操作处理器
+func NewFileOperationHandler(root string, opts ...FileOperationOption) (*FileOperationHandler, error) {
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return nil, fmt.Errorf("file_operation: invalid root: %w", err)
+	}
+	realRoot, err := filepath.EvalSymlinks(absRoot)
+	if err != nil {
+		return nil, fmt.Errorf("file_operation: invalid root: %w", err)
+	}
+	info, err := os.Stat(realRoot)
+	if err != nil {
+		return nil, fmt.Errorf("file_operation: invalid root: %w", err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("file_operation: root %s is not a directory", root)
+	}
+
+	h := &FileOperationHandler{
+		root:         realRoot,
+		maxReadBytes: defaultMaxReadBytes,
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h, nil
+}
+
+// FileOperationResult 文件操作结果
+type FileOperationResult struct {
+	Operation    string      `json:"operation"`
+	Path         string      `json:"path"`
+	Content      string      `json:"content,omitempty"`
+	BytesWritten int         `json:"bytes_written,omitempty"`
+	Entries      []FileEntry `json:"entries,omitempty"`
+	Truncated    bool        `json:"truncated,omitempty"`
+}
+
+// FileEntry 目录项
+type FileEntry struct {
+	Name  string `json:"name"`
+	IsDir bool   `json:"is_dir"`
+	Size  int64  `json:"size"`
+}
+
+// HandleToolCall 实现ToolCallHandler接口
+func (h *FileOperationHandler) HandleToolCall(toolCall types.ToolCall) (string, error) {
+	return h.HandleToolCallContext(context.Background(), toolCall)
+}
+
+// HandleToolCallContext 实现ContextToolCallHandler接口
+func (h *FileOperationHandler) HandleToolCallContext(ctx context.Context, toolCall types.ToolCall) (string, error) {
+	args, err := ParseToolCallArguments[struct {
+		Operation string `json:"operation"`
+		Path      string `json:"path"`
+		Content   string `json:"content"`
+	}](toolCall)
+	if err != nil {
+		return "", err
+	}
+
+	var result *FileOperationResult
+	switch args.Operation {
+	case "read":
+		result, err = h.Read(args.Path)
+	case "write":
+		result, err = h.Write(args.Path, args.Content)
+	case "list":
+		result, err = h.List(args.Path)
+	case "delete":
+		result, err = h.Delete(args.Path)
+	default:
+		return "", fmt.Errorf("file_operation: unsupported operation %q", args.Operation)
+	}
+	if err != nil {
+		return "", err
+	}
+	return encodeToolResult(result)
+}
+
+// resolve 将相对路径解析为根目录内的绝对路径
+func (h *FileOperationHandler) resolve(path string) (string, string, error) {
+	// 以 / 开头的路径视为相对于根目录
+	cleaned := filepath.Clean(strings.TrimLeft(filepath.FromSlash(path), `/\`))
+	if !filepath.IsLocal(cleaned) {
+		return "", "", fmt.Errorf("file_operation: path %q is outside the root directory", path)
+	}
+	full := filepath.Join(h.root, cleaned)
+
+	// 检查最深的已存在路径，防止通过符号链接跳出根目录
+	existing := full
+	for {
+		if _, err := os.Lstat(existing); err == nil {
+			break
+		}
+		parent := filepath.Dir(existing)
+		if parent == existing {
+			break
+		}
+		existing = parent
+	}
+	resolved, err := filepath.EvalSymlinks(existing)
+	if err != nil {
+		return "", "", fmt.Errorf("file_operation: %w", err)
+	}
+	if !h.within(resolved) {
+		return "", "", fmt.Errorf("file_operation: path %q is outside the root directory", path)
+	}
+
+	return full, filepath.ToSlash(cleaned), nil
+}
+
+// within 检查路径是否在根目录内
+func (h *FileOperationHandler) within(path string) bool {
+	rel, err := filepath.Rel(h.root, path)
+	if err != nil {
+		return false
+	}
+	return rel == "." || filepath.IsLocal(rel)
+}
+
+// Read 读取文本文件
+func (h *FileOperationHandler) Read(path string) (*FileOperationResult, error) {
+	full, rel, err := h.resolve(path)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(full)
+	if err != nil {
+		return nil, fmt.Errorf("file_operation: %w", relativeError(err, rel))
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("file_operation: %w", relativeError(err, rel))
+	}
+	if info.IsDir() {
+		return nil, fmt.Errorf("file_operation: %s is a directory, use the list operation", rel)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(file, h.maxReadBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("file_operation: %w", relativeError(err, rel))
+	}
+	truncated := int64(len(data)) > h.maxReadBytes
+	if truncated {
+		data = data[:h.maxReadBytes]
+		// 不在多字节字符中间截断
+		if n := bytesSinceRuneStart(data); n > 0 && !utf8.FullRune(data[len(data)-n:]) {
+			data = data[:len(data)-n]
+		}
+	}
+	if !utf8.Valid(data) {
+		return nil, fmt.Errorf("file_operation: %s is not a UTF-8 text file", rel)
+	}
+
+	return &FileOperationResult{Operation: "read", Path: rel, Content: string(data), Truncated: truncated}, nil
+}
+
+// bytesSinceRuneStart 返回最后一个字符起始位置到末尾的字节数
+func bytesSinceRuneStart(data []byte) int {
+	for i := len(data) - 1; i >= 0 && i >= len(data)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(data[i]) {
+			return len(data) - i
+		}
+	}
+	return 0
+}
+
+// Write 写入文本文件，必要时创建父目录
+func (h *FileOperationHandler) Write(path, content string) (*FileOperationResult, error) {
+	if h.readOnly {
+		return nil, errors.New("file_operation: write is not allowed (read-only)")
+	}
+	full, rel, err := h.resolve(path)
+	if err != nil {
+		return nil, err
+	}
+	if full == h.root {
+		return nil, errors.New("file_operation: path must be a file")
+	}
+
+	if err := os.MkdirAll(filepath.Dir(full), 0o755); err != nil {
+		return nil, fmt.Errorf("file_operation: %w", relativeError(err, rel))
+	}
+	if err := os.WriteFile(full, []byte(content), 0o644); err != nil {
+		return nil, fmt.Errorf("file_operation: %w", relativeError(err, rel))
+	}
+	return &FileOperationResult{Operation: "write", Path: rel, BytesWritten: len(content)}, nil
+}
+
+// List 列出目录内容
+func (h *FileOperationHandler) List(path string) (*FileOperationResult, error) {
+	full, rel, err := h.resolve(path)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(full)
+	if err != nil {
+		return nil, fmt.Errorf("file_operation: %w", relativeError(err, rel))
+	}
+
+	result := &FileOperationResult{Operation: "list", Path: rel, Entries: []FileEntry{}}
+	for i, entry := range entries {
+		if i >= maxListEntries {
+			result.Truncated = true
+			break
+		}
+		item := FileEntry{Name: entry.Name(), IsDir: entry.IsDir()}
+		if info, err := entry.Info(); err == nil && !entry.IsDir() {
+			item.Size = info.Size()
+		}
+		result.Entries = append(result.Entries, item)
+	}
+	return result, nil
+}
+
+// Delete 删除文件或空目录
+func (h *FileOperationHandler) Delete(path string) (*FileOperationResult, error) {
+	if h.readOnly || !h.allowDelete {
+		return nil, errors.New("file_operation: delete is not allowed")
+	}
+	full, rel, err := h.resolve(path)
+	if err != nil {
+		return nil, err
+	}
+	if full == h.root {
+		return nil, errors.New("file_operation: cannot delete the root directory")
+	}
+
+	if err := os.Remove(full); err != nil {
+		return nil, fmt.Errorf("file_operation: %w", relativeError(err, rel))
+	}
+	return &FileOperationResult{Operation: "delete", Path: rel}, nil
+}
+
+// relativeError 将错误中的绝对路径替换为相对路径，避免向模型暴露根目录位置
+func relativeError(err error, rel string) error {
+	var pathErr *os.PathError
+	if errors.As(err, &pathErr) {
+		return &os.PathError{Op: pathErr.Op, Path: rel, Err: pathErr.Err}
+	}
+	return err
+}
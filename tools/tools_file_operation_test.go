@@ -0,0 +1,128 @@
+package tools
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/yu1ec/go-anyllm/types"
+)
+
+func fileCall(arguments string) types.ToolCall {
+	return types.ToolCall{
+		ID:       "call_1",
+		Function: types.ResponseToolFunction{Name: "file_operation", Arguments: arguments},
+	}
+}
+
+func TestFileOperationHandler(t *testing.T) {
+	root := t.TempDir()
+	handler, err := NewFileOperationHandler(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	registry := NewFunctionRegistry()
+	registry.RegisterTool(FileOperationTool(), handler)
+
+	result := registry.Handle(fileCall(`{"operation":"write","path":"notes/今天.md","content":"# 笔记"}`))
+	if result.Error != "" {
+		t.Fatalf("写入失败: %s", result.Error)
+	}
+	data, err := os.ReadFile(filepath.Join(root, "notes", "今天.md"))
+	if err != nil || string(data) != "# 笔记" {
+		t.Fatalf("文件内容错误: %q %v", data, err)
+	}
+
+	result = registry.Handle(fileCall(`{"operation":"read","path":"/notes/今天.md"}`))
+	var read FileOperationResult
+	if err := json.Unmarshal([]byte(result.Content), &read); err != nil || read.Content != "# 笔记" || read.Path != "notes/今天.md" {
+		t.Errorf("读取结果错误: %+v %s", read, result.Error)
+	}
+
+	result = registry.Handle(fileCall(`{"operation":"list","path":""}`))
+	var list FileOperationResult
+	if err := json.Unmarshal([]byte(result.Content), &list); err != nil || len(list.Entries) != 1 || !list.Entries[0].IsDir {
+		t.Errorf("列出结果错误: %+v %s", list, result.Error)
+	}
+
+	// 默认不允许删除
+	if result := registry.Handle(fileCall(`{"operation":"delete","path":"notes/今天.md"}`)); !strings.Contains(result.Error, "not allowed") {
+		t.Errorf("默认应禁止删除: %+v", result)
+	}
+
+	if result := registry.Handle(fileCall(`{"operation":"read","path":"missing.txt"}`)); strings.Contains(result.Error, root) {
+		t.Errorf("错误信息不应包含根目录: %s", result.Error)
+	}
+}
+
+func TestFileOperationPathTraversal(t *testing.T) {
+	parent := t.TempDir()
+	root := filepath.Join(parent, "sandbox")
+	if err := os.Mkdir(root, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	secret := filepath.Join(parent, "secret.txt")
+	if err := os.WriteFile(secret, []byte("secret"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	// 指向根目录外的符号链接
+	if err := os.Symlink(parent, filepath.Join(root, "escape")); err != nil {
+		t.Skipf("无法创建符号链接: %v", err)
+	}
+
+	handler, err := NewFileOperationHandler(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, path := range []string{"../secret.txt", "a/../../secret.txt", "escape/secret.txt", "escape/new.txt"} {
+		if _, err := handler.Read(path); err == nil || !strings.Contains(err.Error(), "outside the root") {
+			t.Errorf("读取 %s 应被拒绝，得到 %v", path, err)
+		}
+		if _, err := handler.Write(path, "x"); err == nil {
+			t.Errorf("写入 %s 应被拒绝", path)
+		}
+	}
+	if data, _ := os.ReadFile(secret); string(data) != "secret" {
+		t.Error("根目录外的文件被修改")
+	}
+	if _, err := os.Stat(filepath.Join(parent, "new.txt")); !os.IsNotExist(err) {
+		t.Error("不应在根目录外创建文件")
+	}
+}
+
+func TestFileOperationOptions(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "big.txt"), []byte(strings.Repeat("中", 10)), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	handler, err := NewFileOperationHandler(root, WithMaxReadBytes(10), WithReadOnly())
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := handler.Read("big.txt")
+	if err != nil || !result.Truncated || result.Content != "中中中" {
+		t.Errorf("截断读取错误: %+v %v", result, err)
+	}
+	if _, err := handler.Write("a.txt", "x"); err == nil {
+		t.Error("只读模式不应允许写入")
+	}
+
+	handler, err = NewFileOperationHandler(root, WithFileDelete())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := handler.Delete("big.txt"); err != nil {
+		t.Errorf("允许删除时应删除成功: %v", err)
+	}
+	if _, err := handler.Delete("."); err == nil {
+		t.Error("不应删除根目录")
+	}
+
+	if _, err := NewFileOperationHandler(filepath.Join(root, "missing")); err == nil {
+		t.Error("根目录不存在时应返回错误")
+	}
+}
@@ -0,0 +1,132 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/yu1ec/go-anyllm/types"
+)
+
+// ToolFunc 类型化的工具函数
+type ToolFunc[Args, Result any] func(ctx context.Context, args Args) (Result, error)
+
+// funcHandler 将类型化函数适配为工具调用处理器
+type funcHandler[Args, Result any] struct {
+	fn ToolFunc[Args, Result]
+}
+
+// HandleToolCall 实现ToolCallHandler接口
+func (h *funcHandler[Args, Result]) HandleToolCall(toolCall types.ToolCall) (string, error) {
+	return h.HandleToolCallContext(context.Background(), toolCall)
+}
+
+// HandleToolCallContext 实现ContextToolCallHandler接口
+func (h *funcHandler[Args, Result]) HandleToolCallContext(ctx context.Context, toolCall types.ToolCall) (string, error) {
+	args, err := DecodeToolCallArguments[Args](toolCall)
+	if err != nil {
+		return "", err
+	}
+
+	result, err := h.fn(ctx, args)
+	if err != nil {
+		return "", err
+	}
+
+	return encodeToolResult(result)
+}
+
+// NewFuncTool 根据类型化函数生成工具定义和处理器
+//
+// 工具参数的JSON Schema由Args类型生成（见 SchemaFromType），
+// 处理器将参数解码为Args，并将Result编码为JSON作为工具消息内容（string类型的结果原样返回）。
+// 参数的Schema校验由 FunctionRegistry 在调用处理器前完成，单独使用处理器时不会校验。
+func NewFuncTool[Args, Result any](name, description string, fn ToolFunc[Args, Result]) (types.Tool, ToolCallHandler, error) {
+	if fn == nil {
+		return types.Tool{}, nil, fmt.Errorf("tools: function for %s is nil", name)
+	}
+
+	schema, err := SchemaFromType[Args]()
+	if err != nil {
+		return types.Tool{}, nil, err
+	}
+
+	tool := types.Tool{
+		Type: types.ToolTypeFunction,
+		Function: types.RequestToolFunction{
+			Name:        name,
+			Description: description,
+			Parameters:  schema,
+		},
+	}
+
+	return tool, &funcHandler[Args, Result]{fn: fn}, nil
+}
+
+// RegisterFunc 生成工具定义并将类型化函数注册到注册表
+//
+// 示例：
+//
+//	type WeatherArgs struct {
+//		Location string `json:"location" description:"城市名称"`
+//		Unit     string `json:"unit,omitempty" enum:"celsius,fahrenheit"`
+//	}
+//
+//	tool, err := tools.RegisterFunc(registry, "get_weather", "获取天气",
+//		func(ctx context.Context, args WeatherArgs) (Weather, error) { ... })
+func RegisterFunc[Args, Result any](registry *FunctionRegistry, name, description string, fn func(ctx context.Context, args Args) (Result, error)) (types.Tool, error) {
+	tool, handler, err := NewFuncTool[Args, Result](name, description, fn)
+	if err != nil {
+		return types.Tool{}, err
+	}
+
+	registry.RegisterTool(tool, handler)
+	return tool, nil
+}
+
+// DecodeToolCallArguments 解析工具调用参数
+//
+// 空参数按空对象处理；参数JSON不完整时返回错误。需要校验时先调用 FunctionRegistry.ValidateToolCall 或 ValidateAgainstSchema。
+func DecodeToolCallArguments[T any](toolCall types.ToolCall) (T, error) {
+	if isEmptyArguments(toolCall.Function.Arguments) {
+		toolCall.Function.Arguments = "{}"
+	}
+
+	result, ok, err := ParseToolCallArgumentsSafe[T](toolCall)
+	if err != nil {
+		return result, err
+	}
+	if !ok {
+		return result, fmt.Errorf("tools: incomplete arguments JSON for %s", toolCall.Function.Name)
+	}
+	return result, nil
+}
+
+// isEmptyArguments 检查工具调用参数是否为空
+func isEmptyArguments(arguments interface{}) bool {
+	switch a := arguments.(type) {
+	case nil:
+		return true
+	case string:
+		return a == ""
+	case []byte:
+		return len(a) == 0
+	}
+	return false
+}
+
+// encodeToolResult 将函数结果编码为工具消息内容
+func encodeToolResult(result interface{}) (string, error) {
+	switch r := result.(type) {
+	case string:
+		return r, nil
+	case json.RawMessage:
+		return string(r), nil
+	}
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		return "", fmt.Errorf("tools: failed to encode tool result: %w", err)
+	}
+	return string(data), nil
+}
@@ -0,0 +1,206 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/yu1ec/go-anyllm/types"
+)
+
+type testWeatherArgs struct {
+	Location string   `json:"location" description:"城市名称"`
+	Unit     string   `json:"unit,omitempty" enum:"celsius,fahrenheit"`
+	Days     *int     `json:"days"`
+	Tags     []string `json:"tags,omitempty"`
+	Options  struct {
+		Detail bool `json:"detail"`
+	} `json:"options,omitempty"`
+	internal string
+}
+
+type testWeatherResult struct {
+	Location    string `json:"location"`
+	Temperature int    `json:"temperature"`
+}
+
+func TestSchemaFromType(t *testing.T) {
+	schema, err := SchemaFromType[testWeatherArgs]()
+	if err != nil {
+		t.Fatalf("生成Schema失败: %v", err)
+	}
+
+	if schema.Type != TypeObject {
+		t.Errorf("期望类型为object，得到 %s", schema.Type)
+	}
+	if !reflect.DeepEqual(schema.Required, []string{"location"}) {
+		t.Errorf("期望必需字段为[location]，得到 %v", schema.Required)
+	}
+	if len(schema.Properties) != 5 {
+		t.Errorf("期望5个属性，得到 %d", len(schema.Properties))
+	}
+	if schema.Properties["location"].Description != "城市名称" {
+		t.Errorf("description标签未生效")
+	}
+	if !reflect.DeepEqual(schema.Properties["unit"].Enum, []interface{}{"celsius", "fahrenheit"}) {
+		t.Errorf("enum标签未生效: %v", schema.Properties["unit"].Enum)
+	}
+	if schema.Properties["days"].Type != TypeInteger {
+		t.Errorf("指针字段类型错误: %s", schema.Properties["days"].Type)
+	}
+	if schema.Properties["tags"].Items == nil || schema.Properties["tags"].Items.Type != TypeString {
+		t.Errorf("数组元素类型错误")
+	}
+	if schema.Properties["options"].Properties["detail"].Type != TypeBoolean {
+		t.Errorf("嵌套对象属性类型错误")
+	}
+
+	if _, err := SchemaFromType[string](); err == nil {
+		t.Error("非结构体类型应该返回错误")
+	}
+}
+
+func TestPropertyDefinitionTypeTag(t *testing.T) {
+	data, err := json.Marshal(&PropertyDefinition{Description: "手写定义"})
+	if err != nil {
+		t.Fatalf("序列化失败: %v", err)
+	}
+	if !strings.Contains(string(data), `"type":""`) {
+		t.Errorf("手写的属性定义应保留type字段，得到 %s", data)
+	}
+
+	type anyArgs struct {
+		Value interface{} `json:"value"`
+	}
+	schema, err := SchemaFromType[anyArgs]()
+	if err != nil {
+		t.Fatalf("生成Schema失败: %v", err)
+	}
+	data, err = json.Marshal(schema)
+	if err != nil {
+		t.Fatalf("序列化失败: %v", err)
+	}
+	if want := `"properties":{"value":{}}`; !strings.Contains(string(data), want) {
+		t.Errorf("期望无类型属性省略type，得到 %s", data)
+	}
+}
+
+func TestRegisterFunc(t *testing.T) {
+	registry := NewFunctionRegistry()
+
+	var gotArgs testWeatherArgs
+	tool, err := RegisterFunc(registry, "get_weather", "获取天气",
+		func(ctx context.Context, args testWeatherArgs) (testWeatherResult, error) {
+			gotArgs = args
+			return testWeatherResult{Location: args.Location, Temperature: 25}, nil
+		})
+	if err != nil {
+		t.Fatalf("注册失败: %v", err)
+	}
+
+	if tool.Type != types.ToolTypeFunction || tool.Function.Name != "get_weather" {
+		t.Errorf("工具定义错误: %+v", tool)
+	}
+	if registered, ok := registry.GetTool("get_weather"); !ok || registered.Function.Name != "get_weather" {
+		t.Error("工具定义未注册")
+	}
+	if len(registry.Tools()) != 1 {
+		t.Errorf("期望1个工具，得到 %d", len(registry.Tools()))
+	}
+
+	result := registry.Handle(types.ToolCall{
+		ID:   "call_1",
+		Type: "function",
+		Function: types.ResponseToolFunction{
+			Name:      "get_weather",
+			Arguments: `{"location": "北京", "unit": "celsius"}`,
+		},
+	})
+	if result.Error != "" {
+		t.Fatalf("处理失败: %s", result.Error)
+	}
+	if gotArgs.Location != "北京" || gotArgs.Unit != "celsius" {
+		t.Errorf("参数解析错误: %+v", gotArgs)
+	}
+
+	var decoded testWeatherResult
+	if err := json.Unmarshal([]byte(result.Content), &decoded); err != nil {
+		t.Fatalf("结果不是有效JSON: %s", result.Content)
+	}
+	if decoded.Temperature != 25 {
+		t.Errorf("结果错误: %+v", decoded)
+	}
+}
+
+func TestRegisterFuncValidation(t *testing.T) {
+	registry := NewFunctionRegistry()
+	called := false
+	_, err := RegisterFunc(registry, "get_weather", "获取天气",
+		func(ctx context.Context, args testWeatherArgs) (string, error) {
+			called = true
+			return "ok", nil
+		})
+	if err != nil {
+		t.Fatalf("注册失败: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		arguments string
+		wantError string
+	}{
+		{"缺少必需字段", `{"unit": "celsius"}`, "$.location: required field is missing"},
+		{"类型错误", `{"location": 123}`, "$.location: expected string, got integer"},
+		{"枚举错误", `{"location": "北京", "unit": "kelvin"}`, `$.unit: value "kelvin" is not one of ["celsius", "fahrenheit"]`},
+		{"数组元素类型错误", `{"location": "北京", "tags": ["a", 1]}`, "$.tags[1]: expected string, got integer"},
+		{"不完整JSON", `{"location": "北京"`, "$: arguments are not valid JSON"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			called = false
+			result := registry.Handle(types.ToolCall{
+				ID:       "call_1",
+				Function: types.ResponseToolFunction{Name: "get_weather", Arguments: tt.arguments},
+			})
+			if !strings.Contains(result.Error, tt.wantError) {
+				t.Errorf("期望错误包含 %q，得到 %q", tt.wantError, result.Error)
+			}
+			if called {
+				t.Error("参数无效时不应调用函数")
+			}
+		})
+	}
+}
+
+func TestRegisterFuncContextAndError(t *testing.T) {
+	type ctxKey struct{}
+	type noArgs struct{}
+
+	registry := NewFunctionRegistry()
+	_, err := RegisterFunc(registry, "whoami", "返回上下文中的用户",
+		func(ctx context.Context, args noArgs) (string, error) {
+			user, _ := ctx.Value(ctxKey{}).(string)
+			if user == "" {
+				return "", errors.New("no user")
+			}
+			return user, nil
+		})
+	if err != nil {
+		t.Fatalf("注册失败: %v", err)
+	}
+
+	toolCall := types.ToolCall{ID: "call_1", Function: types.ResponseToolFunction{Name: "whoami"}}
+
+	ctx := context.WithValue(context.Background(), ctxKey{}, "alice")
+	if result := registry.HandleContext(ctx, toolCall); result.Content != "alice" {
+		t.Errorf("期望结果为alice，得到 %+v", result)
+	}
+
+	if result := registry.Handle(toolCall); result.Error != "no user" {
+		t.Errorf("期望错误为no user，得到 %+v", result)
+	}
+}
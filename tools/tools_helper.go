@@ -1,8 +1,10 @@
 package tools
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -31,6 +33,20 @@ type PropertyDefinition struct {
 	Properties  map[string]*PropertyDefinition `json:"properties,omitempty"`
 	Required    []string                       `json:"required,omitempty"`
 	Default     interface{}                    `json:"default,omitempty"`
+
+	untyped bool // 由SchemaFromType生成的无类型属性，序列化时省略type
+}
+
+// MarshalJSON 实现json.Marshaler接口，无类型属性不输出type字段
+func (p PropertyDefinition) MarshalJSON() ([]byte, error) {
+	type plain PropertyDefinition
+	if !p.untyped {
+		return json.Marshal(plain(p))
+	}
+	return json.Marshal(struct {
+		Type string `json:"type,omitempty"`
+		plain
+	}{plain: plain(p)})
 }
 
 // FunctionSchema 函数参数的JSON Schema定义
@@ -233,6 +249,11 @@ type ToolCallHandler interface {
 	HandleToolCall(toolCall types.ToolCall) (string, error)
 }
 
+// ContextToolCallHandler 支持上下文的工具调用处理器接口
+type ContextToolCallHandler interface {
+	HandleToolCallContext(ctx context.Context, toolCall types.ToolCall) (string, error)
+}
+
 // StreamingToolCallHandler 流式工具调用处理器接口
 type StreamingToolCallHandler interface {
 	HandleToolCallStream(toolCall types.ToolCall) (<-chan StreamChunk, error)
@@ -262,41 +283,189 @@ type OptionalStreamingHandler interface {
 
 // ToolCallResult 工具调用结果
 type ToolCallResult struct {
-	ToolCallID string `json:"tool_call_id"`
-	Content    string `json:"content"`
-	Error      string `json:"error,omitempty"`
+	ToolCallID string            `json:"tool_call_id"`
+	Content    string            `json:"content"`
+	Error      string            `json:"error,omitempty"`
+	Violations []SchemaViolation `json:"violations,omitempty"` // 参数校验失败的详细信息
+	Rejected   bool              `json:"rejected,omitempty"`   // 是否被策略禁止或被审批拒绝
 }
 
 // FunctionRegistry 函数注册表
 type FunctionRegistry struct {
-	handlers map[string]ToolCallHandler
+	handlers  map[string]ToolCallHandler
+	tools     map[string]types.Tool
+	schemas   map[string]*compiledSchema // 注册时解析的参数Schema
+	toolOrder []string
+	mutex     sync.RWMutex
+
+	disableValidation bool // 是否关闭参数Schema校验
+
+	policies      map[string]ToolPolicy
+	defaultPolicy ToolPolicy
+	approver      ApprovalFunc
+
+	middlewares []Middleware
 }
 
 // NewFunctionRegistry 创建新的函数注册表
 func NewFunctionRegistry() *FunctionRegistry {
 	return &FunctionRegistry{
 		handlers: make(map[string]ToolCallHandler),
+		tools:    make(map[string]types.Tool),
+		schemas:  make(map[string]*compiledSchema),
+		policies: make(map[string]ToolPolicy),
+	}
+}
+
+// SetArgumentValidation 设置是否在调用处理器前按工具定义的parameters校验参数（默认开启）
+//
+// 只有通过 RegisterTool 或 RegisterFunc 注册了工具定义的函数才会被校验。
+func (fr *FunctionRegistry) SetArgumentValidation(enabled bool) {
+	fr.mutex.Lock()
+	defer fr.mutex.Unlock()
+
+	fr.disableValidation = !enabled
+}
+
+// ValidateToolCall 按已注册的工具定义校验工具调用参数
+//
+// 未注册工具定义或关闭校验时返回nil；参数不是有效JSON或不符合Schema时返回 *SchemaValidationError。
+// Schema中使用了不支持的关键字（见 ValidateAgainstSchema）的部分不做校验。
+func (fr *FunctionRegistry) ValidateToolCall(toolCall types.ToolCall) error {
+	fr.mutex.RLock()
+	disabled := fr.disableValidation
+	tool, exists := fr.tools[toolCall.Function.Name]
+	schema := fr.schemas[toolCall.Function.Name]
+	fr.mutex.RUnlock()
+
+	if disabled || !exists || tool.Function.Parameters == nil {
+		return nil
+	}
+
+	if isEmptyArguments(toolCall.Function.Arguments) {
+		toolCall.Function.Arguments = "{}"
+	}
+	args, ok, err := ParseToolCallArgumentsSafe[interface{}](toolCall)
+	if err != nil || !ok {
+		return &SchemaValidationError{Violations: []SchemaViolation{{
+			Path:    "$",
+			Message: "arguments are not valid JSON",
+		}}}
+	}
+
+	return schema.validate(args)
+}
+
+// validationFailure 构建参数校验失败的结果
+func validationFailure(toolCallID string, err error) *ToolCallResult {
+	result := &ToolCallResult{
+		ToolCallID: toolCallID,
+		Error:      err.Error(),
+	}
+	if validationErr, ok := err.(*SchemaValidationError); ok {
+		result.Violations = validationErr.Violations
+	}
+	return result
+}
+
+// RegisterTool 注册工具定义及其处理器
+//
+// 参数Schema在注册时解析一次，无法解析的Schema不做校验。
+func (fr *FunctionRegistry) RegisterTool(tool types.Tool, handler ToolCallHandler) {
+	schema, _ := compileSchema(tool.Function.Parameters)
+
+	fr.mutex.Lock()
+	defer fr.mutex.Unlock()
+
+	name := tool.Function.Name
+	if _, exists := fr.tools[name]; !exists {
+		fr.toolOrder = append(fr.toolOrder, name)
+	}
+	fr.tools[name] = tool
+	fr.schemas[name] = schema
+	fr.handlers[name] = handler
+}
+
+// GetTool 获取已注册的工具定义
+func (fr *FunctionRegistry) GetTool(functionName string) (types.Tool, bool) {
+	fr.mutex.RLock()
+	defer fr.mutex.RUnlock()
+
+	tool, exists := fr.tools[functionName]
+	return tool, exists
+}
+
+// Tools 按注册顺序返回所有已注册的工具定义，可直接用于请求的Tools字段
+func (fr *FunctionRegistry) Tools() []types.Tool {
+	fr.mutex.RLock()
+	defer fr.mutex.RUnlock()
+
+	result := make([]types.Tool, 0, len(fr.toolOrder))
+	for _, name := range fr.toolOrder {
+		result = append(result, fr.tools[name])
 	}
+	return result
 }
 
 // Register 注册工具处理器
 func (fr *FunctionRegistry) Register(functionName string, handler ToolCallHandler) {
+	fr.mutex.Lock()
+	defer fr.mutex.Unlock()
+
 	fr.handlers[functionName] = handler
 }
 
 // RegisterStreaming 注册流式工具处理器
 func (fr *FunctionRegistry) RegisterStreaming(functionName string, handler StreamingToolCallHandler) {
+	fr.mutex.Lock()
+	defer fr.mutex.Unlock()
+
 	fr.handlers[functionName] = &streamingToSyncAdapter{handler}
 }
 
 // RegisterUnified 注册统一工具处理器（同时支持同步和流式）
 func (fr *FunctionRegistry) RegisterUnified(functionName string, handler UnifiedToolCallHandler) {
+	fr.mutex.Lock()
+	defer fr.mutex.Unlock()
+
 	fr.handlers[functionName] = handler
 }
 
+// Unregister 移除工具处理器及其工具定义
+func (fr *FunctionRegistry) Unregister(functionName string) {
+	fr.mutex.Lock()
+	defer fr.mutex.Unlock()
+
+	delete(fr.handlers, functionName)
+	if _, exists := fr.tools[functionName]; exists {
+		delete(fr.tools, functionName)
+		delete(fr.schemas, functionName)
+		for i, name := range fr.toolOrder {
+			if name == functionName {
+				fr.toolOrder = append(fr.toolOrder[:i], fr.toolOrder[i+1:]...)
+				break
+			}
+		}
+	}
+}
+
+// getHandler 获取工具处理器
+func (fr *FunctionRegistry) getHandler(functionName string) (ToolCallHandler, bool) {
+	fr.mutex.RLock()
+	defer fr.mutex.RUnlock()
+
+	handler, exists := fr.handlers[functionName]
+	return handler, exists
+}
+
 // Handle 处理工具调用
 func (fr *FunctionRegistry) Handle(toolCall types.ToolCall) *ToolCallResult {
-	handler, exists := fr.handlers[toolCall.Function.Name]
+	return fr.HandleContext(context.Background(), toolCall)
+}
+
+// HandleContext 使用指定上下文处理工具调用
+func (fr *FunctionRegistry) HandleContext(ctx context.Context, toolCall types.ToolCall) *ToolCallResult {
+	handler, exists := fr.getHandler(toolCall.Function.Name)
 	if !exists {
 		return &ToolCallResult{
 			ToolCallID: toolCall.ID,
@@ -304,22 +473,41 @@ func (fr *FunctionRegistry) Handle(toolCall types.ToolCall) *ToolCallResult {
 		}
 	}
 
-	content, err := handler.HandleToolCall(toolCall)
-	result := &ToolCallResult{
-		ToolCallID: toolCall.ID,
-		Content:    content,
+	if err := fr.ValidateToolCall(toolCall); err != nil {
+		return validationFailure(toolCall.ID, err)
 	}
 
+	toolCall, err := fr.authorize(ctx, toolCall)
 	if err != nil {
-		result.Error = err.Error()
+		return rejectionFailure(toolCall.ID, err)
 	}
 
-	return result
+	invoke := func(ctx context.Context, toolCall types.ToolCall) *ToolCallResult {
+		var content string
+		var err error
+		if h, ok := handler.(ContextToolCallHandler); ok {
+			content, err = h.HandleToolCallContext(ctx, toolCall)
+		} else {
+			content, err = handler.HandleToolCall(toolCall)
+		}
+		result := &ToolCallResult{
+			ToolCallID: toolCall.ID,
+			Content:    content,
+		}
+
+		if err != nil {
+			result.Error = err.Error()
+		}
+
+		return result
+	}
+
+	return fr.wrapCall(invoke)(ctx, toolCall)
 }
 
 // HandleStreaming 处理流式工具调用
 func (fr *FunctionRegistry) HandleStreaming(toolCall types.ToolCall) (<-chan StreamChunk, error) {
-	handler, exists := fr.handlers[toolCall.Function.Name]
+	handler, exists := fr.getHandler(toolCall.Function.Name)
 	if !exists {
 		errChan := make(chan StreamChunk, 1)
 		errChan <- StreamChunk{
@@ -330,27 +518,52 @@ func (fr *FunctionRegistry) HandleStreaming(toolCall types.ToolCall) (<-chan Str
 		return errChan, nil
 	}
 
-	// 检查处理器类型
-	switch h := handler.(type) {
-	case StreamingToolCallHandler:
-		return h.HandleToolCallStream(toolCall)
-	case UnifiedToolCallHandler:
-		return h.HandleToolCallStream(toolCall)
-	case OptionalStreamingHandler:
-		if h.CanStream() {
+	if err := fr.ValidateToolCall(toolCall); err != nil {
+		errChan := make(chan StreamChunk, 1)
+		errChan <- StreamChunk{
+			Error: err,
+			Done:  true,
+		}
+		close(errChan)
+		return errChan, nil
+	}
+
+	toolCall, err := fr.authorize(context.Background(), toolCall)
+	if err != nil {
+		errChan := make(chan StreamChunk, 1)
+		errChan <- StreamChunk{
+			Error: err,
+			Done:  true,
+		}
+		close(errChan)
+		return errChan, nil
+	}
+
+	invoke := func(ctx context.Context, toolCall types.ToolCall) (<-chan StreamChunk, error) {
+		// 检查处理器类型
+		switch h := handler.(type) {
+		case StreamingToolCallHandler:
+			return h.HandleToolCallStream(toolCall)
+		case UnifiedToolCallHandler:
 			return h.HandleToolCallStream(toolCall)
+		case OptionalStreamingHandler:
+			if h.CanStream() {
+				return h.HandleToolCallStream(toolCall)
+			}
+			// 如果不支持流式，将同步结果转换为流式
+			return fr.syncToStream(h, toolCall), nil
+		default:
+			// 对于普通的 ToolCallHandler，将同步结果转换为流式
+			return fr.syncToStream(handler, toolCall), nil
 		}
-		// 如果不支持流式，将同步结果转换为流式
-		return fr.syncToStream(h, toolCall), nil
-	default:
-		// 对于普通的 ToolCallHandler，将同步结果转换为流式
-		return fr.syncToStream(handler, toolCall), nil
 	}
+
+	return fr.wrapStream(invoke)(context.Background(), toolCall)
 }
 
 // CanHandleStreaming 检查指定函数是否支持流式处理
 func (fr *FunctionRegistry) CanHandleStreaming(functionName string) bool {
-	handler, exists := fr.handlers[functionName]
+	handler, exists := fr.getHandler(functionName)
 	if !exists {
 		return false
 	}
@@ -413,9 +626,16 @@ func (adapter *streamingToSyncAdapter) HandleToolCall(toolCall types.ToolCall) (
 }
 
 // ToToolMessage 将工具调用结果转换为消息
+//
+// 参数校验失败时，消息内容为包含各项违规信息的JSON，便于模型修正参数后重新调用；
+// 被策略禁止或被审批拒绝时，消息内容说明调用未执行及原因。
 func (result *ToolCallResult) ToToolMessage() types.ChatCompletionMessage {
 	content := result.Content
-	if result.Error != "" {
+	if len(result.Violations) > 0 {
+		content = formatViolationsForModel(result.Violations)
+	} else if result.Rejected {
+		content = fmt.Sprintf("Tool call was not executed: %s. Do not retry it with the same arguments.", result.Error)
+	} else if result.Error != "" {
 		content = fmt.Sprintf("Error: %s", result.Error)
 	}
 
@@ -510,10 +730,24 @@ func IsValidJSON(s string) bool {
 }
 
 // StreamingToolCallAccumulator 流式工具调用累积器
+//
+// 带index的片段按 (choice index, tool index) 归属到对应的工具调用，
+// 这与OpenAI流式协议一致：并行工具调用按index交错发送，ID只出现在第一个片段中。
+// 不带index的片段按ID归属，ID为空时关联到最近的工具调用。
 type StreamingToolCallAccumulator struct {
 	toolCalls      map[string]*StreamingToolCall
-	lastToolCallID string // 最近处理的工具调用ID，用于处理ID为空的Delta
+	indexToID      map[toolCallIndexKey]string // (choice index, tool index) 到工具调用ID的映射
+	lastToolCallID string                      // 最近处理的工具调用ID，用于处理ID和index都为空的Delta
+	nextSeq        int                         // 工具调用的到达顺序
 	mutex          sync.RWMutex
+
+	repairOnFinalize bool // 流结束时是否尝试修复无效JSON
+}
+
+// toolCallIndexKey 工具调用在流中的位置
+type toolCallIndexKey struct {
+	choice int
+	index  int
 }
 
 // StreamingToolCall 流式工具调用状态
@@ -521,46 +755,60 @@ type StreamingToolCall struct {
 	ID              string
 	Type            string
 	FunctionName    string
+	ChoiceIndex     int // 所属choice的index
+	Index           int // 工具调用的index，Delta中没有index时为-1
 	ArgumentsBuffer strings.Builder
 	IsComplete      bool
+	Repaired        bool // 参数是否经过JSON修复
 	LastUpdateTime  time.Time
+
+	parser *PartialJSONParser // 增量跟踪参数JSON的结构
+	seq    int                // 到达顺序，用于没有index时排序
 }
 
 // NewStreamingToolCallAccumulator 创建新的流式工具调用累积器
 func NewStreamingToolCallAccumulator() *StreamingToolCallAccumulator {
 	return &StreamingToolCallAccumulator{
 		toolCalls: make(map[string]*StreamingToolCall),
+		indexToID: make(map[toolCallIndexKey]string),
 	}
 }
 
-// ProcessDelta 处理Delta中的工具调用
+// ProcessDelta 处理Delta中的工具调用（choice index为0）
 func (acc *StreamingToolCallAccumulator) ProcessDelta(deltaToolCalls []*response.ToolCall) {
+	acc.ProcessChoiceDelta(0, deltaToolCalls)
+}
+
+// ProcessChoiceDelta 处理指定choice的Delta中的工具调用
+func (acc *StreamingToolCallAccumulator) ProcessChoiceDelta(choiceIndex int, deltaToolCalls []*response.ToolCall) {
 	acc.mutex.Lock()
 	defer acc.mutex.Unlock()
 
 	for _, delta := range deltaToolCalls {
-		var targetID string
-
-		// 处理ID为空的情况：关联到最近的工具调用
-		if delta.Id == "" {
-			if acc.lastToolCallID == "" {
-				// 如果没有之前的工具调用ID，跳过这个Delta
-				continue
-			}
-			targetID = acc.lastToolCallID
-		} else {
-			targetID = delta.Id
-			acc.lastToolCallID = targetID // 更新最近的工具调用ID
+		targetID, ok := acc.resolveTargetID(choiceIndex, delta)
+		if !ok {
+			// 无法确定归属的Delta，跳过
+			continue
 		}
+		acc.lastToolCallID = targetID
 
 		// 获取或创建工具调用
 		if acc.toolCalls[targetID] == nil {
+			index := -1
+			if delta.Index != nil {
+				index = *delta.Index
+			}
 			acc.toolCalls[targetID] = &StreamingToolCall{
 				ID:             targetID,
 				Type:           delta.Type,
 				FunctionName:   delta.Function.Name,
+				ChoiceIndex:    choiceIndex,
+				Index:          index,
 				LastUpdateTime: time.Now(),
+				parser:         NewPartialJSONParser(),
+				seq:            acc.nextSeq,
 			}
+			acc.nextSeq++
 		}
 
 		streamingCall := acc.toolCalls[targetID]
@@ -579,32 +827,123 @@ func (acc *StreamingToolCallAccumulator) ProcessDelta(deltaToolCalls []*response
 		// 累积参数
 		if delta.Function.Arguments != "" {
 			streamingCall.ArgumentsBuffer.WriteString(delta.Function.Arguments)
+			streamingCall.parser.Write(delta.Function.Arguments)
 		}
 
-		// 检查JSON是否完整
-		currentArgs := streamingCall.ArgumentsBuffer.String()
-		if IsValidJSON(currentArgs) {
-			streamingCall.IsComplete = true
+		// 增量解析器判断顶层结构闭合后，再完整校验一次JSON
+		if !streamingCall.IsComplete && streamingCall.parser.IsComplete() {
+			streamingCall.IsComplete = IsValidJSON(streamingCall.ArgumentsBuffer.String())
+		}
+	}
+}
+
+// resolveTargetID 确定Delta所属的工具调用ID
+func (acc *StreamingToolCallAccumulator) resolveTargetID(choiceIndex int, delta *response.ToolCall) (string, bool) {
+	if delta.Index == nil {
+		// 没有index：按ID归属，ID为空时关联到最近的工具调用
+		if delta.Id != "" {
+			return delta.Id, true
+		}
+		return acc.lastToolCallID, acc.lastToolCallID != ""
+	}
+
+	key := toolCallIndexKey{choice: choiceIndex, index: *delta.Index}
+	existingID, exists := acc.indexToID[key]
+	switch {
+	case delta.Id == "" && exists:
+		return existingID, true
+	case delta.Id == "":
+		// 第一个片段没有ID时生成稳定的占位ID
+		existingID = placeholderToolCallID(key)
+		acc.indexToID[key] = existingID
+		return existingID, true
+	case exists && existingID == placeholderToolCallID(key):
+		// 后续片段带来真实ID时，把占位ID的工具调用改名，而不是开始新的工具调用
+		if streamingCall := acc.toolCalls[existingID]; streamingCall != nil {
+			delete(acc.toolCalls, existingID)
+			streamingCall.ID = delta.Id
+			acc.toolCalls[delta.Id] = streamingCall
+		}
+		if acc.lastToolCallID == existingID {
+			acc.lastToolCallID = delta.Id
+		}
+		acc.indexToID[key] = delta.Id
+		return delta.Id, true
+	default:
+		// 有ID时以ID为准；同一index出现新ID（部分服务商会复用index）视为新的工具调用
+		acc.indexToID[key] = delta.Id
+		return delta.Id, true
+	}
+}
+
+// placeholderToolCallID 返回第一个片段没有ID时使用的占位ID
+func placeholderToolCallID(key toolCallIndexKey) string {
+	return fmt.Sprintf("call_%d_%d", key.choice, key.index)
+}
+
+// sortedToolCalls 按 (choice index, tool index, 到达顺序) 排序返回工具调用
+func (acc *StreamingToolCallAccumulator) sortedToolCalls() []*StreamingToolCall {
+	calls := make([]*StreamingToolCall, 0, len(acc.toolCalls))
+	for _, streamingCall := range acc.toolCalls {
+		calls = append(calls, streamingCall)
+	}
+
+	sort.Slice(calls, func(i, j int) bool {
+		a, b := calls[i], calls[j]
+		if a.ChoiceIndex != b.ChoiceIndex {
+			return a.ChoiceIndex < b.ChoiceIndex
+		}
+		// 带index的工具调用排在前面并按index排序
+		if (a.Index < 0) != (b.Index < 0) {
+			return a.Index >= 0
+		}
+		if a.Index >= 0 && a.Index != b.Index {
+			return a.Index < b.Index
 		}
+		return a.seq < b.seq
+	})
+	return calls
+}
+
+// toToolCall 将流式状态转换为工具调用
+func (sc *StreamingToolCall) toToolCall() types.ToolCall {
+	toolCall := types.ToolCall{
+		ID:   sc.ID,
+		Type: sc.Type,
+		Function: types.ResponseToolFunction{
+			Name:      sc.FunctionName,
+			Arguments: sc.ArgumentsBuffer.String(),
+		},
+	}
+	if sc.Index >= 0 {
+		toolCall.Index = types.ToPtr(sc.Index)
+	}
+	return toolCall
+}
+
+// GetPartialArguments 返回指定工具调用当前尽力解析的部分参数
+//
+// 参数仍在流式生成时，未闭合的字符串、对象和数组会被自动补全，可用于实时展示工具输入。
+func (acc *StreamingToolCallAccumulator) GetPartialArguments(toolCallId string) (map[string]interface{}, bool) {
+	acc.mutex.RLock()
+	defer acc.mutex.RUnlock()
+
+	streamingCall, exists := acc.toolCalls[toolCallId]
+	if !exists {
+		return nil, false
 	}
+	return streamingCall.parser.PartialObject()
 }
 
-// GetCompletedToolCalls 获取已完成的工具调用
+// GetCompletedToolCalls 获取已完成的工具调用（按choice index和工具调用index排序）
 func (acc *StreamingToolCallAccumulator) GetCompletedToolCalls() []types.ToolCall {
 	acc.mutex.RLock()
 	defer acc.mutex.RUnlock()
 
 	var completed []types.ToolCall
-	for _, streamingCall := range acc.toolCalls {
+	for _, streamingCall := range acc.sortedToolCalls() {
 		if streamingCall.IsComplete {
-			completed = append(completed, types.ToolCall{
-				ID:   streamingCall.ID,
-				Type: streamingCall.Type,
-				Function: types.ResponseToolFunction{
-					Name:      streamingCall.FunctionName,
-					Arguments: streamingCall.ArgumentsBuffer.String(),
-				},
-			})
+			completed = append(completed, streamingCall.toToolCall())
 		}
 	}
 
@@ -636,6 +975,11 @@ func (acc *StreamingToolCallAccumulator) ClearCompleted() {
 			delete(acc.toolCalls, id)
 		}
 	}
+	for key, id := range acc.indexToID {
+		if _, exists := acc.toolCalls[id]; !exists {
+			delete(acc.indexToID, key)
+		}
+	}
 }
 
 // HasPendingToolCalls 检查是否有待完成的工具调用
@@ -687,7 +1031,26 @@ func (acc *StreamingToolCallAccumulator) GetTotalCount() int {
 	return len(acc.toolCalls)
 }
 
+// SetRepairOnFinalize 设置流结束时是否尝试修复无效的参数JSON（如被截断的流、多余的逗号等）
+func (acc *StreamingToolCallAccumulator) SetRepairOnFinalize(enabled bool) {
+	acc.mutex.Lock()
+	defer acc.mutex.Unlock()
+
+	acc.repairOnFinalize = enabled
+}
+
+// WasRepaired 检查指定ID的工具调用参数是否经过JSON修复
+func (acc *StreamingToolCallAccumulator) WasRepaired(toolCallId string) bool {
+	acc.mutex.RLock()
+	defer acc.mutex.RUnlock()
+
+	streamingCall, exists := acc.toolCalls[toolCallId]
+	return exists && streamingCall.Repaired
+}
+
 // FinalizeStream 在流结束时强制检查所有累积的工具调用，将有效JSON标记为完成
+//
+// 开启 SetRepairOnFinalize 后，无效的参数会先尝试修复，修复成功的工具调用同样标记为完成。
 func (acc *StreamingToolCallAccumulator) FinalizeStream() []types.ToolCall {
 	acc.mutex.Lock()
 	defer acc.mutex.Unlock()
@@ -699,22 +1062,24 @@ func (acc *StreamingToolCallAccumulator) FinalizeStream() []types.ToolCall {
 			// 强制检查JSON是否有效
 			if IsValidJSON(currentArgs) {
 				streamingCall.IsComplete = true
+			} else if acc.repairOnFinalize {
+				if repaired, changed, err := RepairJSON(currentArgs); err == nil && changed {
+					streamingCall.ArgumentsBuffer.Reset()
+					streamingCall.ArgumentsBuffer.WriteString(repaired)
+					streamingCall.parser.Reset()
+					streamingCall.parser.Write(repaired)
+					streamingCall.Repaired = true
+					streamingCall.IsComplete = true
+				}
 			}
 		}
 	}
 
-	// 返回所有已完成的工具调用
+	// 按index顺序返回所有已完成的工具调用
 	var completed []types.ToolCall
-	for _, streamingCall := range acc.toolCalls {
+	for _, streamingCall := range acc.sortedToolCalls() {
 		if streamingCall.IsComplete {
-			completed = append(completed, types.ToolCall{
-				ID:   streamingCall.ID,
-				Type: streamingCall.Type,
-				Function: types.ResponseToolFunction{
-					Name:      streamingCall.FunctionName,
-					Arguments: streamingCall.ArgumentsBuffer.String(),
-				},
-			})
+			completed = append(completed, streamingCall.toToolCall())
 		}
 	}
 
@@ -737,16 +1102,8 @@ func (acc *StreamingToolCallAccumulator) ForceCompleteToolCall(toolCallId string
 	streamingCall.LastUpdateTime = time.Now()
 
 	// 构建并返回工具调用对象
-	toolCall := &types.ToolCall{
-		ID:   streamingCall.ID,
-		Type: streamingCall.Type,
-		Function: types.ResponseToolFunction{
-			Name:      streamingCall.FunctionName,
-			Arguments: streamingCall.ArgumentsBuffer.String(),
-		},
-	}
-
-	return toolCall
+	toolCall := streamingCall.toToolCall()
+	return &toolCall
 }
 
 // GetPendingToolCallsDebugInfo 返回当前待处理工具调用的详细信息，用于调试
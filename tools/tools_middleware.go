@@ -0,0 +1,546 @@
+package tools
+
+import (
+	"container/list"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/yu1ec/go-anyllm/tokenizer"
+	"github.com/yu1ec/go-anyllm/types"
+)
+
+// ToolCallFunc 执行工具调用
+type ToolCallFunc func(ctx context.Context, toolCall types.ToolCall) *ToolCallResult
+
+// StreamToolCallFunc 执行流式工具调用
+type StreamToolCallFunc func(ctx context.Context, toolCall types.ToolCall) (<-chan StreamChunk, error)
+
+// Middleware 工具调用中间件，在参数校验和审批通过后包裹处理器的执行
+type Middleware interface {
+	WrapCall(next ToolCallFunc) ToolCallFunc
+}
+
+// StreamMiddleware 流式工具调用中间件，同时实现该接口的中间件也会作用于 HandleStreaming
+type StreamMiddleware interface {
+	WrapStream(next StreamToolCallFunc) StreamToolCallFunc
+}
+
+// MiddlewareFunc 函数形式的中间件（只作用于同步调用）
+type MiddlewareFunc func(next ToolCallFunc) ToolCallFunc
+
+// WrapCall 实现Middleware接口
+func (f MiddlewareFunc) WrapCall(next ToolCallFunc) ToolCallFunc {
+	return f(next)
+}
+
+// Use 添加中间件，先添加的中间件在最外层
+func (fr *FunctionRegistry) Use(middlewares ...Middleware) {
+	fr.mutex.Lock()
+	defer fr.mutex.Unlock()
+
+	fr.middlewares = append(fr.middlewares, middlewares...)
+}
+
+// wrapCall 用中间件链包裹同步调用
+func (fr *FunctionRegistry) wrapCall(invoke ToolCallFunc) ToolCallFunc {
+	fr.mutex.RLock()
+	middlewares := fr.middlewares
+	fr.mutex.RUnlock()
+
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		invoke = middlewares[i].WrapCall(invoke)
+	}
+	return invoke
+}
+
+// wrapStream 用中间件链包裹流式调用
+func (fr *FunctionRegistry) wrapStream(invoke StreamToolCallFunc) StreamToolCallFunc {
+	fr.mutex.RLock()
+	middlewares := fr.middlewares
+	fr.mutex.RUnlock()
+
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		if m, ok := middlewares[i].(StreamMiddleware); ok {
+			invoke = m.WrapStream(invoke)
+		}
+	}
+	return invoke
+}
+
+// drainStream 在后台读完剩余的块，避免处理器阻塞
+func drainStream(chunks <-chan StreamChunk) {
+	go func() {
+		for range chunks {
+		}
+	}()
+}
+
+// loggingMiddleware 记录每次调用的耗时和错误
+type loggingMiddleware struct {
+	logger *slog.Logger
+}
+
+// LoggingMiddleware 创建日志中间件，logger为nil时使用 slog.Default()
+func LoggingMiddleware(logger *slog.Logger) Middleware {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &loggingMiddleware{logger: logger}
+}
+
+func (m *loggingMiddleware) log(ctx context.Context, toolCall types.ToolCall, start time.Time, size int, errMsg string) {
+	attrs := []slog.Attr{
+		slog.String("tool", toolCall.Function.Name),
+		slog.String("call_id", toolCall.ID),
+		slog.Duration("duration", time.Since(start)),
+		slog.Int("result_bytes", size),
+	}
+	if errMsg != "" {
+		m.logger.LogAttrs(ctx, slog.LevelError, "tool call failed", append(attrs, slog.String("error", errMsg))...)
+		return
+	}
+	m.logger.LogAttrs(ctx, slog.LevelInfo, "tool call completed", attrs...)
+}
+
+// WrapCall 实现Middleware接口
+func (m *loggingMiddleware) WrapCall(next ToolCallFunc) ToolCallFunc {
+	return func(ctx context.Context, toolCall types.ToolCall) *ToolCallResult {
+		start := time.Now()
+		result := next(ctx, toolCall)
+		m.log(ctx, toolCall, start, len(result.Content), result.Error)
+		return result
+	}
+}
+
+// WrapStream 实现StreamMiddleware接口，在流结束时记录
+func (m *loggingMiddleware) WrapStream(next StreamToolCallFunc) StreamToolCallFunc {
+	return func(ctx context.Context, toolCall types.ToolCall) (<-chan StreamChunk, error) {
+		start := time.Now()
+		chunks, err := next(ctx, toolCall)
+		if err != nil {
+			m.log(ctx, toolCall, start, 0, err.Error())
+			return nil, err
+		}
+
+		out := make(chan StreamChunk)
+		go func() {
+			defer close(out)
+			size := 0
+			errMsg := ""
+			for chunk := range chunks {
+				size += len(chunk.Content)
+				if chunk.Error != nil && errMsg == "" {
+					errMsg = chunk.Error.Error()
+				}
+				out <- chunk
+			}
+			m.log(ctx, toolCall, start, size, errMsg)
+		}()
+		return out, nil
+	}
+}
+
+// ToolCache 按工具名和规范化参数缓存成功的调用结果
+//
+// 通常每个会话创建一个缓存，避免同一对话中重复执行相同的查询。
+type ToolCache struct {
+	ttl        time.Duration
+	maxEntries int
+	tools      map[string]bool
+
+	mutex   sync.Mutex
+	entries map[string]*list.Element
+	lru     *list.List
+	hits    int
+	misses  int
+}
+
+type cacheEntry struct {
+	key      string
+	content  string
+	storedAt time.Time
+}
+
+// CacheOption 缓存选项
+type CacheOption func(*ToolCache)
+
+// WithCacheTTL 设置缓存有效期，0表示不过期
+func WithCacheTTL(ttl time.Duration) CacheOption {
+	return func(c *ToolCache) {
+		c.ttl = ttl
+	}
+}
+
+// WithCacheMaxEntries 设置最大缓存条目数，超出时淘汰最久未使用的条目，0表示不限制
+func WithCacheMaxEntries(maxEntries int) CacheOption {
+	return func(c *ToolCache) {
+		c.maxEntries = maxEntries
+	}
+}
+
+// WithCacheTools 只缓存指定工具的结果（默认缓存所有工具）
+func WithCacheTools(functionNames ...string) CacheOption {
+	return func(c *ToolCache) {
+		c.tools = make(map[string]bool, len(functionNames))
+		for _, name := range functionNames {
+			c.tools[name] = true
+		}
+	}
+}
+
+// NewToolCache 创建工具结果缓存，可直接作为中间件使用
+func NewToolCache(opts ...CacheOption) *ToolCache {
+	c := &ToolCache{
+		entries: make(map[string]*list.Element),
+		lru:     list.New(),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Clear 清空缓存
+func (c *ToolCache) Clear() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.entries = make(map[string]*list.Element)
+	c.lru.Init()
+}
+
+// Stats 返回缓存命中和未命中次数
+func (c *ToolCache) Stats() (hits, misses int) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.hits, c.misses
+}
+
+// cacheKey 根据工具名和规范化后的参数生成缓存键，参数无法解析时不缓存
+func (c *ToolCache) cacheKey(toolCall types.ToolCall) (string, bool) {
+	name := toolCall.Function.Name
+	if c.tools != nil && !c.tools[name] {
+		return "", false
+	}
+
+	if isEmptyArguments(toolCall.Function.Arguments) {
+		return name + "\x00{}", true
+	}
+	args, err := ParseToolCallArguments[interface{}](toolCall)
+	if err != nil {
+		return "", false
+	}
+	// 重新编码后对象的键有序，空白和键顺序不同的参数得到相同的键
+	canonical, err := json.Marshal(args)
+	if err != nil {
+		return "", false
+	}
+	return name + "\x00" + string(canonical), true
+}
+
+// get 查询缓存
+func (c *ToolCache) get(key string) (string, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	element, exists := c.entries[key]
+	if exists {
+		entry := element.Value.(*cacheEntry)
+		if c.ttl <= 0 || time.Since(entry.storedAt) < c.ttl {
+			c.lru.MoveToFront(element)
+			c.hits++
+			return entry.content, true
+		}
+		c.lru.Remove(element)
+		delete(c.entries, key)
+	}
+	c.misses++
+	return "", false
+}
+
+// put 写入缓存
+func (c *ToolCache) put(key, content string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if element, exists := c.entries[key]; exists {
+		entry := element.Value.(*cacheEntry)
+		entry.content = content
+		entry.storedAt = time.Now()
+		c.lru.MoveToFront(element)
+		return
+	}
+
+	c.entries[key] = c.lru.PushFront(&cacheEntry{key: key, content: content, storedAt: time.Now()})
+	if c.maxEntries > 0 && c.lru.Len() > c.maxEntries {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry).key)
+	}
+}
+
+// WrapCall 实现Middleware接口
+func (c *ToolCache) WrapCall(next ToolCallFunc) ToolCallFunc {
+	return func(ctx context.Context, toolCall types.ToolCall) *ToolCallResult {
+		key, cacheable := c.cacheKey(toolCall)
+		if !cacheable {
+			return next(ctx, toolCall)
+		}
+		if content, hit := c.get(key); hit {
+			return &ToolCallResult{ToolCallID: toolCall.ID, Content: content}
+		}
+
+		result := next(ctx, toolCall)
+		if result.Error == "" {
+			c.put(key, result.Content)
+		}
+		return result
+	}
+}
+
+// WrapStream 实现StreamMiddleware接口，命中时以单个块返回缓存结果
+func (c *ToolCache) WrapStream(next StreamToolCallFunc) StreamToolCallFunc {
+	return func(ctx context.Context, toolCall types.ToolCall) (<-chan StreamChunk, error) {
+		key, cacheable := c.cacheKey(toolCall)
+		if !cacheable {
+			return next(ctx, toolCall)
+		}
+		if content, hit := c.get(key); hit {
+			out := make(chan StreamChunk, 1)
+			out <- StreamChunk{Content: content, Done: true}
+			close(out)
+			return out, nil
+		}
+
+		chunks, err := next(ctx, toolCall)
+		if err != nil {
+			return nil, err
+		}
+
+		out := make(chan StreamChunk)
+		go func() {
+			defer close(out)
+			var content strings.Builder
+			failed := false
+			for chunk := range chunks {
+				content.WriteString(chunk.Content)
+				if chunk.Error != nil {
+					failed = true
+				}
+				out <- chunk
+			}
+			if !failed {
+				c.put(key, content.String())
+			}
+		}()
+		return out, nil
+	}
+}
+
+// TruncateOptions 结果截断选项，MaxBytes和MaxTokens至少设置一个
+type TruncateOptions struct {
+	MaxBytes  int // 结果的最大字节数，0表示不限制
+	MaxTokens int // 结果的最大token数，0表示不限制
+
+	// CountTokens 计算token数，为nil时使用 tokenizer.DefaultEstimator
+	CountTokens func(text string) int
+
+	// Summarize 可选，结果超出限制时先尝试生成摘要（如调用模型），失败或摘要仍超限时再截断
+	Summarize func(ctx context.Context, toolCall types.ToolCall, content string) (string, error)
+}
+
+// truncateMiddleware 截断过长的工具结果，避免占满上下文窗口
+type truncateMiddleware struct {
+	opts TruncateOptions
+}
+
+// TruncateMiddleware 创建结果截断中间件
+//
+// 截断后的内容末尾会追加说明，告知模型结果不完整，说明也计入限制。流式调用在达到限制后停止转发。
+func TruncateMiddleware(opts TruncateOptions) Middleware {
+	if opts.CountTokens == nil {
+		opts.CountTokens = tokenizer.DefaultEstimator.Count
+	}
+	return &truncateMiddleware{opts: opts}
+}
+
+// exceeds 检查内容是否超出限制
+func (m *truncateMiddleware) exceeds(content string) bool {
+	if m.opts.MaxBytes > 0 && len(content) > m.opts.MaxBytes {
+		return true
+	}
+	return m.opts.MaxTokens > 0 && m.opts.CountTokens(content) > m.opts.MaxTokens
+}
+
+// budget 返回为说明预留空间后内容可用的字节数和token数，0表示不限制，负数表示没有剩余空间
+func (m *truncateMiddleware) budget(notice string) (maxBytes, maxTokens int) {
+	if m.opts.MaxBytes > 0 {
+		maxBytes = m.opts.MaxBytes - len(notice)
+		if maxBytes <= 0 {
+			maxBytes = -1
+		}
+	}
+	if m.opts.MaxTokens > 0 {
+		maxTokens = m.opts.MaxTokens - m.opts.CountTokens(notice)
+		if maxTokens <= 0 {
+			maxTokens = -1
+		}
+	}
+	return maxBytes, maxTokens
+}
+
+// cut 返回不超出限制的最长前缀（按字符边界截断），限制为0表示不限制，负数表示不保留内容
+func (m *truncateMiddleware) cut(content string, maxBytes, maxTokens int) string {
+	if maxBytes < 0 || maxTokens < 0 {
+		return ""
+	}
+
+	prefix := content
+	if maxBytes > 0 && len(prefix) > maxBytes {
+		end := maxBytes
+		for end > 0 && !utf8.RuneStart(prefix[end]) {
+			end--
+		}
+		prefix = prefix[:end]
+	}
+
+	if maxTokens > 0 && m.opts.CountTokens(prefix) > maxTokens {
+		// 在字符边界上二分查找满足token限制的最长前缀
+		boundaries := make([]int, 0, len(prefix)+1)
+		for i := range prefix {
+			boundaries = append(boundaries, i)
+		}
+		boundaries = append(boundaries, len(prefix))
+		n := sort.Search(len(boundaries), func(i int) bool {
+			return m.opts.CountTokens(prefix[:boundaries[i]]) > maxTokens
+		})
+		if n > 0 {
+			n--
+		}
+		prefix = prefix[:boundaries[n]]
+	}
+	return prefix
+}
+
+// truncationNotice 截断说明
+func truncationNotice(kept, total int) string {
+	return fmt.Sprintf("\n\n[truncated: showing the first %d of %d bytes]", kept, total)
+}
+
+// streamTruncationNotice 流式截断说明，总长度未知，只说明已截断
+func streamTruncationNotice(kept int) string {
+	return fmt.Sprintf("\n\n[truncated: output exceeded the limit after %d bytes]", kept)
+}
+
+// WrapCall 实现Middleware接口
+func (m *truncateMiddleware) WrapCall(next ToolCallFunc) ToolCallFunc {
+	return func(ctx context.Context, toolCall types.ToolCall) *ToolCallResult {
+		result := next(ctx, toolCall)
+		if !m.exceeds(result.Content) {
+			return result
+		}
+
+		if m.opts.Summarize != nil {
+			summary, err := m.opts.Summarize(ctx, toolCall, result.Content)
+			if err == nil && !m.exceeds(summary) {
+				result.Content = summary
+				return result
+			}
+		}
+
+		// 保留的字节数不超过总字节数，按总字节数预留说明的长度
+		total := len(result.Content)
+		maxBytes, maxTokens := m.budget(truncationNotice(total, total))
+		kept := m.cut(result.Content, maxBytes, maxTokens)
+		result.Content = m.cut(kept+truncationNotice(len(kept), total), m.opts.MaxBytes, m.opts.MaxTokens)
+		return result
+	}
+}
+
+// WrapStream 实现StreamMiddleware接口
+//
+// 已转发的块无法撤回，因此超出预留说明后的空间但仍在限制内的块会先暂存，
+// 流正常结束时再转发，超出限制时丢弃并发送截断说明。
+func (m *truncateMiddleware) WrapStream(next StreamToolCallFunc) StreamToolCallFunc {
+	return func(ctx context.Context, toolCall types.ToolCall) (<-chan StreamChunk, error) {
+		chunks, err := next(ctx, toolCall)
+		if err != nil {
+			return nil, err
+		}
+
+		// 保留的字节数不超过MaxBytes，按MaxBytes预留说明的长度
+		maxBytes, maxTokens := m.budget(streamTruncationNotice(m.opts.MaxBytes))
+
+		out := make(chan StreamChunk)
+		go func() {
+			defer close(out)
+			var content strings.Builder // 已转发的内容
+			var pending []StreamChunk   // 暂存的块
+			pendingBytes := 0
+			tokens := 0
+			flush := func() {
+				for _, chunk := range pending {
+					out <- chunk
+				}
+				pending = nil
+			}
+
+			for chunk := range chunks {
+				if chunk.Error != nil {
+					flush()
+					out <- chunk
+					continue
+				}
+
+				// token数按块累加估算，避免每个块都重新计算全文
+				chunkTokens := 0
+				if m.opts.MaxTokens > 0 {
+					chunkTokens = m.opts.CountTokens(chunk.Content)
+				}
+				size := content.Len() + pendingBytes + len(chunk.Content)
+				if pending == nil && within(size, maxBytes) && within(tokens+chunkTokens, maxTokens) {
+					content.WriteString(chunk.Content)
+					tokens += chunkTokens
+					out <- chunk
+					continue
+				}
+				if within(size, m.opts.MaxBytes) && within(tokens+chunkTokens, m.opts.MaxTokens) {
+					pending = append(pending, chunk)
+					pendingBytes += len(chunk.Content)
+					tokens += chunkTokens
+					continue
+				}
+
+				// 发送预留说明后空间内的部分后结束
+				var rest strings.Builder
+				for _, p := range pending {
+					rest.WriteString(p.Content)
+				}
+				rest.WriteString(chunk.Content)
+				sent := content.Len()
+				kept := m.cut(content.String()+rest.String(), maxBytes, maxTokens)
+				if len(kept) > sent {
+					out <- StreamChunk{Content: kept[sent:]}
+				}
+				out <- StreamChunk{Content: m.cut(streamTruncationNotice(max(len(kept), sent)), m.opts.MaxBytes, m.opts.MaxTokens), Done: true}
+				drainStream(chunks)
+				return
+			}
+			flush()
+		}()
+		return out, nil
+	}
+}
+
+// within 检查数量是否在限制内，0表示不限制，负数表示没有空间
+func within(n, limit int) bool {
+	return limit == 0 || (limit > 0 && n <= limit)
+}
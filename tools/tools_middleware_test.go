@@ -0,0 +1,261 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yu1ec/go-anyllm/types"
+)
+
+// countingHandler 记录调用次数并返回固定结果
+type countingHandler struct {
+	calls   int
+	content string
+	err     error
+}
+
+func (h *countingHandler) HandleToolCall(toolCall types.ToolCall) (string, error) {
+	h.calls++
+	return h.content, h.err
+}
+
+func newLookupCall(arguments string) types.ToolCall {
+	return types.ToolCall{
+		ID:       "call_" + arguments,
+		Type:     "function",
+		Function: types.ResponseToolFunction{Name: "lookup", Arguments: arguments},
+	}
+}
+
+func TestMiddlewareOrder(t *testing.T) {
+	registry := NewFunctionRegistry()
+	registry.Register("lookup", &countingHandler{content: "result"})
+
+	var order []string
+	trace := func(name string) Middleware {
+		return MiddlewareFunc(func(next ToolCallFunc) ToolCallFunc {
+			return func(ctx context.Context, toolCall types.ToolCall) *ToolCallResult {
+				order = append(order, name+">")
+				result := next(ctx, toolCall)
+				order = append(order, "<"+name)
+				return result
+			}
+		})
+	}
+	registry.Use(trace("a"), trace("b"))
+
+	if result := registry.Handle(newLookupCall(`{}`)); result.Content != "result" {
+		t.Errorf("结果错误: %+v", result)
+	}
+	if strings.Join(order, " ") != "a> b> <b <a" {
+		t.Errorf("中间件顺序错误: %v", order)
+	}
+}
+
+func TestLoggingMiddleware(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, nil))
+
+	registry := NewFunctionRegistry()
+	registry.Register("lookup", &countingHandler{err: errors.New("backend down")})
+	registry.Use(LoggingMiddleware(logger))
+
+	registry.Handle(newLookupCall(`{}`))
+	output := buf.String()
+	if !strings.Contains(output, "tool call failed") || !strings.Contains(output, "tool=lookup") ||
+		!strings.Contains(output, "duration=") || !strings.Contains(output, "backend down") {
+		t.Errorf("日志内容错误: %s", output)
+	}
+}
+
+func TestToolCache(t *testing.T) {
+	handler := &countingHandler{content: "北京: 晴"}
+	registry := NewFunctionRegistry()
+	registry.Register("lookup", handler)
+	cache := NewToolCache()
+	registry.Use(cache)
+
+	first := registry.Handle(newLookupCall(`{"city":"北京","unit":"c"}`))
+	// 键顺序和空白不同的相同参数命中缓存
+	second := registry.Handle(newLookupCall(`{ "unit": "c", "city": "北京" }`))
+	if handler.calls != 1 || second.Content != first.Content {
+		t.Errorf("相同参数应命中缓存，调用次数 %d", handler.calls)
+	}
+	if second.ToolCallID != `call_{ "unit": "c", "city": "北京" }` {
+		t.Errorf("缓存结果应使用当前调用的ID: %s", second.ToolCallID)
+	}
+
+	registry.Handle(newLookupCall(`{"city":"上海","unit":"c"}`))
+	if handler.calls != 2 {
+		t.Error("不同参数不应命中缓存")
+	}
+	if hits, misses := cache.Stats(); hits != 1 || misses != 2 {
+		t.Errorf("统计错误: hits=%d misses=%d", hits, misses)
+	}
+
+	// 失败的结果不缓存
+	handler.err = errors.New("timeout")
+	registry.Handle(newLookupCall(`{"city":"广州"}`))
+	handler.err = nil
+	registry.Handle(newLookupCall(`{"city":"广州"}`))
+	if handler.calls != 4 {
+		t.Errorf("失败结果不应被缓存，调用次数 %d", handler.calls)
+	}
+
+	cache.Clear()
+	registry.Handle(newLookupCall(`{"city":"北京","unit":"c"}`))
+	if handler.calls != 5 {
+		t.Error("清空后应重新执行")
+	}
+}
+
+func TestToolCacheEviction(t *testing.T) {
+	handler := &countingHandler{content: "ok"}
+	registry := NewFunctionRegistry()
+	registry.Register("lookup", handler)
+	registry.Register("other", handler)
+	registry.Use(NewToolCache(WithCacheMaxEntries(1), WithCacheTTL(time.Hour), WithCacheTools("lookup")))
+
+	registry.Handle(newLookupCall(`{"a":1}`))
+	registry.Handle(newLookupCall(`{"a":2}`))
+	registry.Handle(newLookupCall(`{"a":1}`))
+	if handler.calls != 3 {
+		t.Errorf("超出容量的条目应被淘汰，调用次数 %d", handler.calls)
+	}
+
+	other := types.ToolCall{ID: "x", Function: types.ResponseToolFunction{Name: "other", Arguments: `{}`}}
+	registry.Handle(other)
+	registry.Handle(other)
+	if handler.calls != 5 {
+		t.Errorf("未指定的工具不应缓存，调用次数 %d", handler.calls)
+	}
+}
+
+func TestTruncateMiddleware(t *testing.T) {
+	handler := &countingHandler{content: strings.Repeat("数据", 100)}
+	registry := NewFunctionRegistry()
+	registry.Register("lookup", handler)
+	registry.Use(TruncateMiddleware(TruncateOptions{MaxBytes: 100}))
+
+	result := registry.Handle(newLookupCall(`{}`))
+	kept, notice, found := strings.Cut(result.Content, "\n\n[truncated")
+	if !found || kept == "" || !strings.HasPrefix(handler.content, kept) {
+		t.Errorf("截断结果错误: %q", result.Content)
+	}
+	if len(result.Content) > 100 {
+		t.Errorf("截断说明也应计入限制，得到 %d 字节", len(result.Content))
+	}
+	if !strings.Contains(notice, "of 600 bytes") {
+		t.Errorf("截断说明错误: %q", notice)
+	}
+
+	handler.content = "short"
+	if result := registry.Handle(newLookupCall(`{}`)); result.Content != "short" {
+		t.Errorf("未超出限制时不应修改: %q", result.Content)
+	}
+}
+
+func TestTruncateMiddlewareTokensAndSummary(t *testing.T) {
+	handler := &countingHandler{content: strings.Repeat("word ", 200)}
+	registry := NewFunctionRegistry()
+	registry.Register("lookup", handler)
+
+	words := func(s string) int { return len(strings.Fields(s)) }
+	var summarized bool
+	registry.Use(TruncateMiddleware(TruncateOptions{
+		MaxTokens:   20,
+		CountTokens: words,
+		Summarize: func(ctx context.Context, toolCall types.ToolCall, content string) (string, error) {
+			summarized = true
+			if toolCall.Function.Arguments == `{"fail":true}` {
+				return "", errors.New("summarizer unavailable")
+			}
+			return "200 repeated words", nil
+		},
+	}))
+
+	if result := registry.Handle(newLookupCall(`{}`)); !summarized || result.Content != "200 repeated words" {
+		t.Errorf("应使用摘要: %q", result.Content)
+	}
+
+	result := registry.Handle(newLookupCall(`{"fail":true}`))
+	kept, notice, _ := strings.Cut(result.Content, "\n\n[truncated")
+	if words(result.Content) > 20 || words(kept) != 20-words("[truncated"+notice) {
+		t.Errorf("摘要失败时应按token截断并为说明预留空间: %q", result.Content)
+	}
+}
+
+func TestStreamingMiddleware(t *testing.T) {
+	registry := NewFunctionRegistry()
+	registry.RegisterStreaming("countdown", chunkHandler{chunks: []string{
+		strings.Repeat("a", 20), strings.Repeat("b", 20), strings.Repeat("c", 20), strings.Repeat("d", 20),
+	}})
+	cache := NewToolCache()
+	// 截断说明占55字节，内容最多保留15字节
+	registry.Use(cache, TruncateMiddleware(TruncateOptions{MaxBytes: 70}))
+
+	collect := func() string {
+		chunks, err := registry.HandleStreaming(types.ToolCall{ID: "c", Function: types.ResponseToolFunction{Name: "countdown", Arguments: `{}`}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var sb strings.Builder
+		for chunk := range chunks {
+			sb.WriteString(chunk.Content)
+		}
+		return sb.String()
+	}
+
+	first := collect()
+	if !strings.HasPrefix(first, strings.Repeat("a", 15)+"\n\n[truncated") || len(first) > 70 {
+		t.Errorf("流式结果截断错误: %q", first)
+	}
+	if second := collect(); second != first {
+		t.Errorf("缓存的流式结果错误: %q", second)
+	}
+	if hits, _ := cache.Stats(); hits != 1 {
+		t.Errorf("第二次流式调用应命中缓存，hits=%d", hits)
+	}
+}
+
+func TestStreamingTruncateWithinLimit(t *testing.T) {
+	registry := NewFunctionRegistry()
+	registry.RegisterStreaming("countdown", chunkHandler{chunks: []string{"aaaa", "bbbb", "cccc", "dddd"}})
+	// 内容超出为说明预留后的空间，但没有超出限制，流结束后应完整转发
+	registry.Use(TruncateMiddleware(TruncateOptions{MaxBytes: 20}))
+
+	chunks, err := registry.HandleStreaming(types.ToolCall{ID: "c", Function: types.ResponseToolFunction{Name: "countdown", Arguments: `{}`}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var sb strings.Builder
+	done := false
+	for chunk := range chunks {
+		sb.WriteString(chunk.Content)
+		done = chunk.Done
+	}
+	if sb.String() != "aaaabbbbccccdddd" || !done {
+		t.Errorf("未超出限制的流式结果不应截断: %q done=%v", sb.String(), done)
+	}
+}
+
+// chunkHandler 依次输出固定块的流式处理器
+type chunkHandler struct {
+	chunks []string
+}
+
+func (h chunkHandler) HandleToolCallStream(toolCall types.ToolCall) (<-chan StreamChunk, error) {
+	out := make(chan StreamChunk)
+	go func() {
+		defer close(out)
+		for i, content := range h.chunks {
+			out <- StreamChunk{Content: content, Done: i == len(h.chunks)-1}
+		}
+	}()
+	return out, nil
+}
@@ -0,0 +1,602 @@
+package tools
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SchemaFromType 根据Go类型生成函数参数的JSON Schema
+//
+// 参数类型必须是结构体（或结构体指针）。字段名取自json标签，支持以下标签：
+//
+//	description:"参数说明"
+//	enum:"a,b,c"        枚举值（逗号分隔）
+//	required:"true"     显式指定是否必需（默认：非指针且没有omitempty的字段为必需）
+func SchemaFromType[T any]() (*FunctionSchema, error) {
+	return schemaFromReflectType(reflect.TypeOf((*T)(nil)).Elem())
+}
+
+// schemaFromReflectType 根据反射类型生成函数参数Schema
+func schemaFromReflectType(t reflect.Type) (*FunctionSchema, error) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("tools: arguments type must be a struct, got %s", t)
+	}
+
+	prop := structProperty(t, map[reflect.Type]bool{})
+	schema := &FunctionSchema{
+		Type:       TypeObject,
+		Properties: prop.Properties,
+		Required:   prop.Required,
+	}
+	if schema.Required == nil {
+		schema.Required = []string{}
+	}
+	return schema, nil
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// typeProperty 将Go类型转换为属性定义
+func typeProperty(t reflect.Type, visiting map[reflect.Type]bool) *PropertyDefinition {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if t == timeType {
+		return &PropertyDefinition{Type: TypeString}
+	}
+	if t.Implements(jsonMarshalerType) || reflect.PtrTo(t).Implements(jsonMarshalerType) {
+		// 自定义序列化的类型无法推断结构，不限制类型
+		return &PropertyDefinition{untyped: true}
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return &PropertyDefinition{Type: TypeString}
+	case reflect.Bool:
+		return &PropertyDefinition{Type: TypeBoolean}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &PropertyDefinition{Type: TypeInteger}
+	case reflect.Float32, reflect.Float64:
+		return &PropertyDefinition{Type: TypeNumber}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			// []byte 按base64字符串序列化
+			return &PropertyDefinition{Type: TypeString}
+		}
+		return &PropertyDefinition{
+			Type:  TypeArray,
+			Items: typeProperty(t.Elem(), visiting),
+		}
+	case reflect.Map:
+		return &PropertyDefinition{Type: TypeObject}
+	case reflect.Struct:
+		if visiting[t] {
+			// 递归类型只保留对象类型，避免无限展开
+			return &PropertyDefinition{Type: TypeObject}
+		}
+		return structProperty(t, visiting)
+	default:
+		// interface{} 等无法确定类型的字段
+		return &PropertyDefinition{untyped: true}
+	}
+}
+
+var jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+
+// structProperty 将结构体转换为对象属性定义
+func structProperty(t reflect.Type, visiting map[reflect.Type]bool) *PropertyDefinition {
+	visiting[t] = true
+	defer delete(visiting, t)
+
+	prop := &PropertyDefinition{
+		Type:       TypeObject,
+		Properties: make(map[string]*PropertyDefinition),
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, omitEmpty, skip := jsonFieldName(field)
+		if skip {
+			continue
+		}
+
+		// 匿名嵌入且没有json名称的结构体字段会被展开
+		if field.Anonymous && field.Tag.Get("json") == "" {
+			ft := field.Type
+			for ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				embedded := structProperty(ft, visiting)
+				for k, v := range embedded.Properties {
+					prop.Properties[k] = v
+				}
+				prop.Required = append(prop.Required, embedded.Required...)
+				continue
+			}
+		}
+
+		fieldProp := typeProperty(field.Type, visiting)
+		fieldProp.Description = field.Tag.Get("description")
+		if enum := field.Tag.Get("enum"); enum != "" {
+			fieldProp.Enum = parseEnumTag(enum, fieldProp.Type)
+		}
+		prop.Properties[name] = fieldProp
+
+		required := !omitEmpty && field.Type.Kind() != reflect.Ptr
+		if tag, ok := field.Tag.Lookup("required"); ok {
+			required = tag == "true"
+		}
+		if required {
+			prop.Required = append(prop.Required, name)
+		}
+	}
+
+	sort.Strings(prop.Required)
+	return prop
+}
+
+// jsonFieldName 解析字段的json名称
+func jsonFieldName(field reflect.StructField) (name string, omitEmpty bool, skip bool) {
+	if !field.IsExported() && !field.Anonymous {
+		return "", false, true
+	}
+
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return "", false, true
+	}
+
+	parts := strings.Split(tag, ",")
+	name = parts[0]
+	if name == "" {
+		name = field.Name
+	}
+	for _, opt := range parts[1:] {
+		if opt == "omitempty" {
+			omitEmpty = true
+		}
+	}
+	return name, omitEmpty, false
+}
+
+// parseEnumTag 解析enum标签，根据属性类型转换枚举值
+func parseEnumTag(tag string, propType string) []interface{} {
+	values := strings.Split(tag, ",")
+	enum := make([]interface{}, 0, len(values))
+	for _, v := range values {
+		v = strings.TrimSpace(v)
+		if propType == TypeInteger || propType == TypeNumber {
+			if f, err := strconv.ParseFloat(v, 64); err == nil {
+				enum = append(enum, f)
+				continue
+			}
+		}
+		enum = append(enum, v)
+	}
+	return enum
+}
+
+// StrictSchema 将Schema转换为OpenAI strict模式要求的形式
+//
+// strict模式要求每个对象都设置 additionalProperties: false 并把所有属性列为必需，
+// 因此非必需属性会改为可以为 null 的类型。包含无类型属性或不固定键的对象（map）时
+// 无法使用strict模式，ok 返回false。
+func StrictSchema(schema *FunctionSchema) (strict map[string]interface{}, ok bool) {
+	return strictProperty(&PropertyDefinition{Type: schema.Type, Properties: schema.Properties, Required: schema.Required}, false)
+}
+
+// strictProperty 递归转换属性定义，nullable 表示属性不是必需的
+func strictProperty(prop *PropertyDefinition, nullable bool) (map[string]interface{}, bool) {
+	if prop == nil || prop.Type == "" {
+		return nil, false
+	}
+
+	result := map[string]interface{}{"type": prop.Type}
+	if nullable {
+		result["type"] = []string{prop.Type, "null"}
+	}
+	if prop.Description != "" {
+		result["description"] = prop.Description
+	}
+	if len(prop.Enum) > 0 {
+		enum := append([]interface{}(nil), prop.Enum...)
+		if nullable {
+			enum = append(enum, nil)
+		}
+		result["enum"] = enum
+	}
+
+	switch prop.Type {
+	case TypeObject:
+		if len(prop.Properties) == 0 {
+			return nil, false
+		}
+		required := make(map[string]bool, len(prop.Required))
+		for _, name := range prop.Required {
+			required[name] = true
+		}
+		names := make([]string, 0, len(prop.Properties))
+		properties := make(map[string]interface{}, len(prop.Properties))
+		for name, fieldProp := range prop.Properties {
+			converted, ok := strictProperty(fieldProp, !required[name])
+			if !ok {
+				return nil, false
+			}
+			properties[name] = converted
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		result["properties"] = properties
+		result["required"] = names
+		result["additionalProperties"] = false
+	case TypeArray:
+		items, ok := strictProperty(prop.Items, false)
+		if !ok {
+			return nil, false
+		}
+		result["items"] = items
+	}
+	return result, true
+}
+
+// SchemaViolation 参数不符合Schema的具体位置和原因
+type SchemaViolation struct {
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+// SchemaValidationError 参数Schema校验错误
+type SchemaValidationError struct {
+	Violations []SchemaViolation `json:"violations"`
+}
+
+// Error 实现error接口
+func (e *SchemaValidationError) Error() string {
+	parts := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		parts[i] = fmt.Sprintf("%s: %s", v.Path, v.Message)
+	}
+	return "invalid arguments: " + strings.Join(parts, "; ")
+}
+
+// formatViolationsForModel 将校验错误格式化为返回给模型的结构化消息
+func formatViolationsForModel(violations []SchemaViolation) string {
+	data, err := json.Marshal(struct {
+		Error      string            `json:"error"`
+		Message    string            `json:"message"`
+		Violations []SchemaViolation `json:"violations"`
+	}{
+		Error:      "invalid_arguments",
+		Message:    "The tool arguments do not match the parameters schema. Fix the listed problems and call the tool again.",
+		Violations: violations,
+	})
+	if err != nil {
+		return (&SchemaValidationError{Violations: violations}).Error()
+	}
+	return string(data)
+}
+
+// ValidateAgainstSchema 校验已解码的JSON值是否符合Schema
+//
+// schema 可以是 *FunctionSchema、*PropertyDefinition、map[string]interface{} 或任何可序列化为JSON Schema的值。
+// 支持 type（包括 ["string", "null"] 形式的类型数组）、required、enum、properties（嵌套对象）和 items（数组）。
+// 使用 anyOf、oneOf、allOf、not、$ref 等不支持的关键字的子Schema不做校验。
+func ValidateAgainstSchema(schema interface{}, value interface{}) error {
+	root, err := compileSchema(schema)
+	if err != nil {
+		return err
+	}
+	return root.validate(value)
+}
+
+// unsupportedSchemaKeywords 校验时不支持的组合与引用关键字，出现时跳过所在的子Schema
+var unsupportedSchemaKeywords = []string{"anyOf", "oneOf", "allOf", "not", "$ref", "if"}
+
+// compiledSchema 解析后的Schema，注册工具时解析一次，校验时重复使用
+type compiledSchema struct {
+	types      []string
+	enum       []interface{}
+	properties map[string]*compiledSchema
+	required   []string
+	items      *compiledSchema
+}
+
+// compileSchema 将任意形式的Schema解析为校验使用的结构，schema为nil时返回nil（不校验）
+func compileSchema(schema interface{}) (*compiledSchema, error) {
+	var data []byte
+	switch s := schema.(type) {
+	case nil:
+		return nil, nil
+	case *FunctionSchema:
+		if s == nil {
+			return nil, nil
+		}
+	case *PropertyDefinition:
+		if s == nil {
+			return nil, nil
+		}
+	case json.RawMessage:
+		data = s
+	case []byte:
+		data = s
+	case string:
+		data = []byte(s)
+	}
+	if data == nil {
+		var err error
+		if data, err = json.Marshal(schema); err != nil {
+			return nil, fmt.Errorf("tools: failed to marshal schema: %w", err)
+		}
+	}
+
+	var raw interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("tools: failed to parse schema: %w", err)
+	}
+	return compileNode(raw)
+}
+
+// compileNode 递归解析Schema节点
+func compileNode(node interface{}) (*compiledSchema, error) {
+	if node == nil {
+		return nil, nil
+	}
+	m, ok := node.(map[string]interface{})
+	if !ok {
+		// true/false 等布尔Schema不做校验
+		if _, isBool := node.(bool); isBool {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("tools: failed to parse schema: expected object, got %s", jsonTypeName(node))
+	}
+	for _, keyword := range unsupportedSchemaKeywords {
+		if _, exists := m[keyword]; exists {
+			return nil, nil
+		}
+	}
+
+	schema := &compiledSchema{}
+	switch t := m["type"].(type) {
+	case string:
+		if t != "" {
+			schema.types = []string{t}
+		}
+	case []interface{}:
+		for _, item := range t {
+			name, ok := item.(string)
+			if !ok {
+				return nil, fmt.Errorf("tools: failed to parse schema: type must be a string or an array of strings")
+			}
+			schema.types = append(schema.types, name)
+		}
+	case nil:
+	default:
+		return nil, fmt.Errorf("tools: failed to parse schema: type must be a string or an array of strings")
+	}
+
+	if enum, ok := m["enum"].([]interface{}); ok {
+		schema.enum = enum
+	}
+
+	if required, ok := m["required"].([]interface{}); ok {
+		for _, item := range required {
+			if name, ok := item.(string); ok {
+				schema.required = append(schema.required, name)
+			}
+		}
+	}
+
+	if properties, ok := m["properties"].(map[string]interface{}); ok {
+		schema.properties = make(map[string]*compiledSchema, len(properties))
+		for name, propNode := range properties {
+			prop, err := compileNode(propNode)
+			if err != nil {
+				return nil, err
+			}
+			if prop != nil {
+				schema.properties[name] = prop
+			}
+		}
+	}
+
+	if itemsNode, ok := m["items"]; ok {
+		items, err := compileNode(itemsNode)
+		if err != nil {
+			return nil, err
+		}
+		schema.items = items
+	}
+	return schema, nil
+}
+
+// validate 校验值，schema为nil时不校验
+func (s *compiledSchema) validate(value interface{}) error {
+	if s == nil {
+		return nil
+	}
+	var violations []SchemaViolation
+	s.validateValue(value, "$", &violations)
+	if len(violations) > 0 {
+		return &SchemaValidationError{Violations: violations}
+	}
+	return nil
+}
+
+// validateValue 递归校验值
+func (s *compiledSchema) validateValue(value interface{}, path string, violations *[]SchemaViolation) {
+	if s == nil {
+		return
+	}
+
+	if len(s.types) > 0 && !matchesAnyType(s.types, value) {
+		*violations = append(*violations, SchemaViolation{
+			Path:    path,
+			Message: fmt.Sprintf("expected %s, got %s", strings.Join(s.types, " or "), jsonTypeName(value)),
+		})
+		return
+	}
+
+	if len(s.enum) > 0 && !enumContains(s.enum, value) {
+		*violations = append(*violations, SchemaViolation{
+			Path:    path,
+			Message: fmt.Sprintf("value %v is not one of %v", formatValue(value), formatEnum(s.enum)),
+		})
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for _, name := range s.required {
+			if _, ok := v[name]; !ok {
+				*violations = append(*violations, SchemaViolation{
+					Path:    path + "." + name,
+					Message: "required field is missing",
+				})
+			}
+		}
+		names := make([]string, 0, len(s.properties))
+		for name := range s.properties {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if fieldValue, ok := v[name]; ok {
+				s.properties[name].validateValue(fieldValue, path+"."+name, violations)
+			}
+		}
+	case []interface{}:
+		for i, item := range v {
+			s.items.validateValue(item, fmt.Sprintf("%s[%d]", path, i), violations)
+		}
+	}
+}
+
+// matchesAnyType 检查值是否符合类型列表中的任意一个
+func matchesAnyType(schemaTypes []string, value interface{}) bool {
+	for _, schemaType := range schemaTypes {
+		if matchesType(schemaType, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesType 检查值是否符合JSON Schema类型
+func matchesType(schemaType string, value interface{}) bool {
+	switch schemaType {
+	case TypeString:
+		_, ok := value.(string)
+		return ok
+	case TypeBoolean:
+		_, ok := value.(bool)
+		return ok
+	case TypeNumber:
+		switch value.(type) {
+		case float64, json.Number:
+			return true
+		}
+		return false
+	case TypeInteger:
+		switch n := value.(type) {
+		case float64:
+			return n == float64(int64(n))
+		case json.Number:
+			_, err := n.Int64()
+			return err == nil
+		}
+		return false
+	case TypeArray:
+		_, ok := value.([]interface{})
+		return ok
+	case TypeObject:
+		_, ok := value.(map[string]interface{})
+		return ok
+	case "null":
+		return value == nil
+	default:
+		return true
+	}
+}
+
+// jsonTypeName 返回值对应的JSON类型名称
+func jsonTypeName(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case string:
+		return TypeString
+	case bool:
+		return TypeBoolean
+	case float64:
+		if v == float64(int64(v)) {
+			return TypeInteger
+		}
+		return TypeNumber
+	case json.Number:
+		return TypeNumber
+	case []interface{}:
+		return TypeArray
+	case map[string]interface{}:
+		return TypeObject
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
+
+// enumContains 检查值是否在枚举列表中
+func enumContains(enum []interface{}, value interface{}) bool {
+	for _, e := range enum {
+		if reflect.DeepEqual(e, value) {
+			return true
+		}
+		// 数字枚举可能以不同的Go类型存储
+		if ef, ok := toFloat(e); ok {
+			if vf, ok := toFloat(value); ok && ef == vf {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// toFloat 尝试将数字转换为float64
+func toFloat(v interface{}) (float64, bool) {
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(rv.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), true
+	}
+	return 0, false
+}
+
+// formatValue 格式化值用于错误信息
+func formatValue(v interface{}) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	return string(data)
+}
+
+// formatEnum 格式化枚举列表用于错误信息
+func formatEnum(enum []interface{}) string {
+	parts := make([]string, len(enum))
+	for i, e := range enum {
+		parts[i] = formatValue(e)
+	}
+	return "[" + strings.Join(parts, ", ") + "]"
+}
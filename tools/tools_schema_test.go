@@ -0,0 +1,286 @@
+package tools
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/yu1ec/go-anyllm/types"
+)
+
+type recordingHandler struct {
+	calls int
+}
+
+func (h *recordingHandler) HandleToolCall(toolCall types.ToolCall) (string, error) {
+	h.calls++
+	return "ok", nil
+}
+
+func TestValidateAgainstSchemaMap(t *testing.T) {
+	schema := map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"user": map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"id":   map[string]interface{}{"type": "integer"},
+					"role": map[string]interface{}{"type": "string", "enum": []interface{}{"admin", "guest"}},
+				},
+				"required": []interface{}{"id"},
+			},
+			"scores": map[string]interface{}{
+				"type":  "array",
+				"items": map[string]interface{}{"type": "number"},
+			},
+		},
+		"required": []interface{}{"user"},
+	}
+
+	tests := []struct {
+		name           string
+		arguments      string
+		wantViolations []SchemaViolation
+	}{
+		{"有效参数", `{"user": {"id": 1, "role": "admin"}, "scores": [1.5, 2]}`, nil},
+		{"嵌套必需字段", `{"user": {}}`, []SchemaViolation{{Path: "$.user.id", Message: "required field is missing"}}},
+		{"嵌套枚举", `{"user": {"id": 1, "role": "root"}}`, []SchemaViolation{{Path: "$.user.role", Message: `value "root" is not one of ["admin", "guest"]`}}},
+		{"整数类型", `{"user": {"id": 1.5}}`, []SchemaViolation{{Path: "$.user.id", Message: "expected integer, got number"}}},
+		{"多个错误", `{"scores": ["x"]}`, []SchemaViolation{
+			{Path: "$.user", Message: "required field is missing"},
+			{Path: "$.scores[0]", Message: "expected number, got string"},
+		}},
+		{"顶层类型", `[]`, []SchemaViolation{{Path: "$", Message: "expected object, got array"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var value interface{}
+			if err := json.Unmarshal([]byte(tt.arguments), &value); err != nil {
+				t.Fatal(err)
+			}
+
+			err := ValidateAgainstSchema(schema, value)
+			if tt.wantViolations == nil {
+				if err != nil {
+					t.Errorf("期望无错误，得到 %v", err)
+				}
+				return
+			}
+
+			var validationErr *SchemaValidationError
+			if !errors.As(err, &validationErr) {
+				t.Fatalf("期望SchemaValidationError，得到 %v", err)
+			}
+			if len(validationErr.Violations) != len(tt.wantViolations) {
+				t.Fatalf("期望 %v，得到 %v", tt.wantViolations, validationErr.Violations)
+			}
+			for i, v := range tt.wantViolations {
+				if validationErr.Violations[i] != v {
+					t.Errorf("第%d项期望 %+v，得到 %+v", i, v, validationErr.Violations[i])
+				}
+			}
+		})
+	}
+}
+
+func TestFunctionRegistryValidatesArguments(t *testing.T) {
+	registry := NewFunctionRegistry()
+	handler := &recordingHandler{}
+	registry.RegisterTool(CustomUserProfileTool(), handler)
+
+	toolCall := types.ToolCall{
+		ID: "call_1",
+		Function: types.ResponseToolFunction{
+			Name:      "query_user_profile",
+			Arguments: `{"user_id": "u1", "query_type": "all", "filters": {"status": "deleted"}}`,
+		},
+	}
+
+	result := registry.Handle(toolCall)
+	if handler.calls != 0 {
+		t.Error("参数无效时不应调用处理器")
+	}
+	if len(result.Violations) != 2 {
+		t.Fatalf("期望2个违规项，得到 %+v", result.Violations)
+	}
+
+	message := result.ToToolMessage()
+	if message.Role != types.RoleTool || message.ToolCallID != "call_1" {
+		t.Errorf("工具消息错误: %+v", message)
+	}
+	var payload struct {
+		Error      string            `json:"error"`
+		Violations []SchemaViolation `json:"violations"`
+	}
+	if err := json.Unmarshal([]byte(message.Content.(string)), &payload); err != nil {
+		t.Fatalf("工具消息不是结构化JSON: %v", message.Content)
+	}
+	if payload.Error != "invalid_arguments" || payload.Violations[0].Path != "$.filters.status" {
+		t.Errorf("结构化错误内容错误: %+v", payload)
+	}
+
+	// 流式处理同样校验
+	stream, err := registry.HandleStreaming(toolCall)
+	if err != nil {
+		t.Fatal(err)
+	}
+	chunk := <-stream
+	var validationErr *SchemaValidationError
+	if !errors.As(chunk.Error, &validationErr) || !chunk.Done {
+		t.Errorf("期望流式返回校验错误，得到 %+v", chunk)
+	}
+
+	// 无效JSON
+	result = registry.Handle(types.ToolCall{
+		ID:       "call_2",
+		Function: types.ResponseToolFunction{Name: "query_user_profile", Arguments: `{"user_id": `},
+	})
+	if len(result.Violations) != 1 || result.Violations[0].Message != "arguments are not valid JSON" {
+		t.Errorf("期望JSON无效错误，得到 %+v", result)
+	}
+
+	// 有效参数
+	toolCall.Function.Arguments = `{"user_id": "u1", "query_type": "basic", "filters": {"status": "active"}}`
+	if result := registry.Handle(toolCall); result.Error != "" || handler.calls != 1 {
+		t.Errorf("有效参数应调用处理器，得到 %+v", result)
+	}
+}
+
+func TestFunctionRegistryValidationDisabled(t *testing.T) {
+	registry := NewFunctionRegistry()
+	handler := &recordingHandler{}
+	registry.RegisterTool(GetWeatherTool(), handler)
+	registry.SetArgumentValidation(false)
+
+	result := registry.Handle(types.ToolCall{
+		ID:       "call_1",
+		Function: types.ResponseToolFunction{Name: "get_weather", Arguments: `{}`},
+	})
+	if result.Error != "" || handler.calls != 1 {
+		t.Errorf("关闭校验后应直接调用处理器，得到 %+v", result)
+	}
+
+	// 仅注册处理器（无工具定义）时不校验
+	plain := NewFunctionRegistry()
+	plain.Register("get_weather", handler)
+	if result := plain.Handle(types.ToolCall{Function: types.ResponseToolFunction{Name: "get_weather"}}); result.Error != "" {
+		t.Errorf("无工具定义时不应校验，得到 %+v", result)
+	}
+}
+
+func TestStrictSchema(t *testing.T) {
+	type address struct {
+		City string `json:"city"`
+	}
+	type profile struct {
+		Name    string   `json:"name"`
+		Age     *int     `json:"age"`
+		Level   string   `json:"level,omitempty" enum:"low,high"`
+		Address address  `json:"address"`
+		Tags    []string `json:"tags"`
+	}
+
+	schema, err := SchemaFromType[profile]()
+	if err != nil {
+		t.Fatal(err)
+	}
+	strict, ok := StrictSchema(schema)
+	if !ok {
+		t.Fatal("期望可以转换为strict Schema")
+	}
+	data, _ := json.Marshal(strict)
+	want := `{"additionalProperties":false,"properties":{"address":{"additionalProperties":false,"properties":{"city":{"type":"string"}},"required":["city"],"type":"object"},"age":{"type":["integer","null"]},"level":{"enum":["low","high",null],"type":["string","null"]},"name":{"type":"string"},"tags":{"items":{"type":"string"},"type":"array"}},"required":["address","age","level","name","tags"],"type":"object"}`
+	if string(data) != want {
+		t.Errorf("strict Schema错误:\n期望 %s\n得到 %s", want, data)
+	}
+
+	type freeform struct {
+		Extra map[string]string `json:"extra"`
+	}
+	schema, _ = SchemaFromType[freeform]()
+	if _, ok := StrictSchema(schema); ok {
+		t.Error("包含map的Schema不能使用strict模式")
+	}
+}
+
+func TestFunctionRegistryNullableArguments(t *testing.T) {
+	type searchArgs struct {
+		Query string `json:"query"`
+		Limit *int   `json:"limit,omitempty"`
+	}
+	schema, err := SchemaFromType[searchArgs]()
+	if err != nil {
+		t.Fatal(err)
+	}
+	strict, ok := StrictSchema(schema)
+	if !ok {
+		t.Fatal("期望可以转换为strict模式")
+	}
+
+	registry := NewFunctionRegistry()
+	handler := &recordingHandler{}
+	registry.RegisterTool(types.Tool{
+		Type:     types.ToolTypeFunction,
+		Function: types.RequestToolFunction{Name: "search", Parameters: strict},
+	}, handler)
+
+	tests := []struct {
+		name      string
+		arguments string
+		wantValid bool
+	}{
+		{"可空字段为null", `{"query": "go", "limit": null}`, true},
+		{"可空字段有值", `{"query": "go", "limit": 10}`, true},
+		{"可空字段类型错误", `{"query": "go", "limit": "ten"}`, false},
+		{"必需字段为null", `{"query": null, "limit": null}`, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := registry.ValidateToolCall(types.ToolCall{
+				Function: types.ResponseToolFunction{Name: "search", Arguments: tt.arguments},
+			})
+			if (err == nil) != tt.wantValid {
+				t.Errorf("期望有效=%v，得到 %v", tt.wantValid, err)
+			}
+		})
+	}
+
+	err = registry.ValidateToolCall(types.ToolCall{
+		Function: types.ResponseToolFunction{Name: "search", Arguments: `{"query": "go", "limit": "ten"}`},
+	})
+	if err == nil || err.Error() != "invalid arguments: $.limit: expected integer or null, got string" {
+		t.Errorf("错误信息不符合预期: %v", err)
+	}
+}
+
+func TestValidateAgainstSchemaUnsupportedKeywords(t *testing.T) {
+	schema := map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"id": map[string]interface{}{
+				"anyOf": []interface{}{
+					map[string]interface{}{"type": "string"},
+					map[string]interface{}{"type": "integer"},
+				},
+			},
+			"name": map[string]interface{}{"type": "string"},
+		},
+		"required": []interface{}{"id"},
+	}
+
+	if err := ValidateAgainstSchema(schema, map[string]interface{}{"id": 42.0}); err != nil {
+		t.Errorf("anyOf字段应跳过校验，得到 %v", err)
+	}
+	if err := ValidateAgainstSchema(schema, map[string]interface{}{"id": "a", "name": 1.0}); err == nil {
+		t.Error("其他字段仍应校验")
+	}
+	if err := ValidateAgainstSchema(schema, map[string]interface{}{}); err == nil {
+		t.Error("required仍应校验")
+	}
+
+	root := map[string]interface{}{"oneOf": []interface{}{map[string]interface{}{"type": "object"}}}
+	if err := ValidateAgainstSchema(root, []interface{}{}); err != nil {
+		t.Errorf("顶层使用oneOf时应跳过校验，得到 %v", err)
+	}
+}
@@ -2,7 +2,7 @@ package tools
 
 import "github.com/yu1ec/go-anyllm/types"
 
-// GetWeatherTool 获取天气信息的工具
+// GetWeatherTool 获取天气信息的工具，处理器见 WeatherHandler
 func GetWeatherTool() types.Tool {
 	return NewTool("get_weather", "获取指定地点的天气信息").
 		AddStringParam("location", "城市和州，例如：北京, 中国", true).
@@ -10,14 +10,14 @@ func GetWeatherTool() types.Tool {
 		BuildForTypes()
 }
 
-// CalculatorTool 计算器工具
+// CalculatorTool 计算器工具，处理器见 CalculatorHandler
 func CalculatorTool() types.Tool {
 	return NewTool("calculator", "执行数学计算").
 		AddStringParam("expression", "要计算的数学表达式，例如：2+3*4", true).
 		BuildForTypes()
 }
 
-// SearchTool 搜索工具
+// SearchTool 搜索工具，处理器见 SearchHandler
 func SearchTool() types.Tool {
 	return NewTool("search", "在互联网上搜索信息").
 		AddStringParam("query", "搜索查询词", true).
@@ -25,7 +25,7 @@ func SearchTool() types.Tool {
 		BuildForTypes()
 }
 
-// SendEmailTool 发送邮件工具
+// SendEmailTool 发送邮件工具，处理器见 EmailHandler（有副作用，建议通过 RequireApproval 设置执行前审批）
 func SendEmailTool() types.Tool {
 	return NewTool("send_email", "发送电子邮件").
 		AddStringParam("to", "收件人邮箱地址", true).
@@ -35,7 +35,7 @@ func SendEmailTool() types.Tool {
 		BuildForTypes()
 }
 
-// FileOperationTool 文件操作工具
+// FileOperationTool 文件操作工具，处理器见 FileOperationHandler（有副作用，建议通过 RequireApproval 设置执行前审批）
 func FileOperationTool() types.Tool {
 	return NewTool("file_operation", "执行文件操作").
 		AddStringParam("operation", "操作类型", true, "read", "write", "delete", "list").
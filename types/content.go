@@ -0,0 +1,201 @@
+package types
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// 音频和文件内容类型常量
+const (
+	MessageContentTypeInputAudio = "input_audio"
+	MessageContentTypeFile       = "file"
+)
+
+// InputAudio 音频输入
+type InputAudio struct {
+	Data   string `json:"data"`   // base64编码的音频数据或URL
+	Format string `json:"format"` // "wav"、"mp3" 等
+}
+
+// FileContent 文件输入
+type FileContent struct {
+	FileID   string `json:"file_id,omitempty"`   // 已上传文件的ID
+	Filename string `json:"filename,omitempty"`  // 文件名
+	FileData string `json:"file_data,omitempty"` // base64编码的文件数据
+}
+
+// Content 消息内容的类型化表示，可以是纯文本，也可以是多个内容项
+// Content 实现了JSON编解码，可以直接赋值给 ChatCompletionMessage.Content
+type Content struct {
+	text  string
+	parts []MessageContent
+	multi bool
+}
+
+// TextOf 创建纯文本内容
+func TextOf(text string) Content {
+	return Content{text: text}
+}
+
+// PartsOf 创建由多个内容项组成的内容
+func PartsOf(parts ...MessageContent) Content {
+	return Content{parts: parts, multi: true}
+}
+
+// ParseContent 把 ChatCompletionMessage.Content 中的值转换为 Content
+// 支持 string、[]MessageContent、MessageContent、Content，以及通用JSON解码得到的 []interface{}
+func ParseContent(value interface{}) (Content, error) {
+	switch v := value.(type) {
+	case nil:
+		return Content{}, nil
+	case string:
+		return TextOf(v), nil
+	case []MessageContent:
+		return PartsOf(v...), nil
+	case MessageContent:
+		return PartsOf(v), nil
+	case Content:
+		return v, nil
+	case *Content:
+		if v == nil {
+			return Content{}, nil
+		}
+		return *v, nil
+	default:
+		// 其他形式（例如 []interface{}）经过JSON重新解析
+		data, err := json.Marshal(v)
+		if err != nil {
+			return Content{}, fmt.Errorf("types: unsupported message content %T: %w", value, err)
+		}
+		var content Content
+		if err := json.Unmarshal(data, &content); err != nil {
+			return Content{}, fmt.Errorf("types: unsupported message content %T: %w", value, err)
+		}
+		return content, nil
+	}
+}
+
+// IsZero 内容是否为空
+func (c Content) IsZero() bool {
+	return !c.multi && c.text == ""
+}
+
+// IsMultiPart 内容是否为内容项数组
+func (c Content) IsMultiPart() bool {
+	return c.multi
+}
+
+// Value 返回可以赋值给 ChatCompletionMessage.Content 的值：string 或 []MessageContent
+func (c Content) Value() interface{} {
+	if c.multi {
+		return c.parts
+	}
+	return c.text
+}
+
+// Parts 返回全部内容项，纯文本内容返回一个文本项
+func (c Content) Parts() []MessageContent {
+	if c.multi {
+		return c.parts
+	}
+	if c.text == "" {
+		return nil
+	}
+	return []MessageContent{NewTextContent(c.text)}
+}
+
+// Texts 返回所有文本
+func (c Content) Texts() []string {
+	if !c.multi {
+		if c.text == "" {
+			return nil
+		}
+		return []string{c.text}
+	}
+	var texts []string
+	for _, part := range c.parts {
+		if part.Type == MessageContentTypeText {
+			texts = append(texts, part.Text)
+		}
+	}
+	return texts
+}
+
+// Images 返回所有图像
+func (c Content) Images() []ImageURL {
+	var images []ImageURL
+	for _, part := range c.parts {
+		if part.Type == MessageContentTypeImageURL && part.ImageURL != nil {
+			images = append(images, *part.ImageURL)
+		}
+	}
+	return images
+}
+
+// Audios 返回所有音频
+func (c Content) Audios() []InputAudio {
+	var audios []InputAudio
+	for _, part := range c.parts {
+		if part.Type == MessageContentTypeInputAudio && part.InputAudio != nil {
+			audios = append(audios, *part.InputAudio)
+		}
+	}
+	return audios
+}
+
+// Files 返回所有文件
+func (c Content) Files() []FileContent {
+	var files []FileContent
+	for _, part := range c.parts {
+		if part.Type == MessageContentTypeFile && part.File != nil {
+			files = append(files, *part.File)
+		}
+	}
+	return files
+}
+
+// HasNonText 是否包含文本以外的内容项
+func (c Content) HasNonText() bool {
+	for _, part := range c.parts {
+		if part.Type != MessageContentTypeText {
+			return true
+		}
+	}
+	return false
+}
+
+// MarshalJSON 纯文本编码为字符串，多个内容项编码为数组
+func (c Content) MarshalJSON() ([]byte, error) {
+	if c.multi {
+		if c.parts == nil {
+			return []byte("[]"), nil
+		}
+		return json.Marshal(c.parts)
+	}
+	return json.Marshal(c.text)
+}
+
+// UnmarshalJSON 接受字符串、内容项数组或null
+func (c *Content) UnmarshalJSON(data []byte) error {
+	*c = Content{}
+	raw := bytes.TrimSpace(data)
+	if len(raw) == 0 || bytes.Equal(raw, []byte("null")) {
+		return nil
+	}
+	switch raw[0] {
+	case '"':
+		return json.Unmarshal(raw, &c.text)
+	case '[':
+		c.multi = true
+		return json.Unmarshal(raw, &c.parts)
+	default:
+		return fmt.Errorf("types: message content must be a string or an array, got %s", raw)
+	}
+}
+
+// GetContent 返回消息内容的类型化表示，无法识别的内容返回空内容
+func (m *ChatCompletionMessage) GetContent() Content {
+	content, _ := ParseContent(m.Content)
+	return content
+}
@@ -0,0 +1,97 @@
+package types
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestMessageContentRoundTrip(t *testing.T) {
+	messages := []ChatCompletionMessage{
+		NewTextMessage(RoleUser, "你好"),
+		NewTextMessage(RoleAssistant, ""),
+		NewMultiModalMessage(RoleUser, []MessageContent{
+			NewTextContent("描述这些内容"),
+			NewImageContent("https://example.com/a.png", ImageDetailLow),
+			{Type: MessageContentTypeInputAudio, InputAudio: &InputAudio{Data: "UklGRg==", Format: "wav"}},
+			{Type: MessageContentTypeFile, File: &FileContent{FileID: "file-1"}},
+		}),
+		{Role: RoleAssistant, ToolCalls: []ToolCall{{ID: "call_1", Type: "function", Function: ResponseToolFunction{Name: "f", Arguments: "{}"}}}},
+	}
+
+	for _, message := range messages {
+		data, err := json.Marshal(message)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var decoded ChatCompletionMessage
+		if err := json.Unmarshal(data, &decoded); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(decoded, message) {
+			t.Errorf("消息未能完整还原:\n期望 %#v\n得到 %#v", message, decoded)
+		}
+	}
+}
+
+func TestContentAccessors(t *testing.T) {
+	var message ChatCompletionMessage
+	data := `{"role":"user","content":[{"type":"text","text":"第一段"},{"type":"image_url","image_url":{"url":"https://example.com/a.png"}},{"type":"text","text":"第二段"},{"type":"input_audio","input_audio":{"data":"abc","format":"mp3"}}]}`
+	if err := json.Unmarshal([]byte(data), &message); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := message.Content.([]MessageContent); !ok {
+		t.Fatalf("期望 []MessageContent，得到 %T", message.Content)
+	}
+
+	content := message.GetContent()
+	if !content.IsMultiPart() || !reflect.DeepEqual(content.Texts(), []string{"第一段", "第二段"}) {
+		t.Errorf("文本错误: %v", content.Texts())
+	}
+	if images := content.Images(); len(images) != 1 || images[0].URL != "https://example.com/a.png" {
+		t.Errorf("图像错误: %v", images)
+	}
+	if audios := content.Audios(); len(audios) != 1 || audios[0].Format != "mp3" {
+		t.Errorf("音频错误: %v", audios)
+	}
+	if !message.IsMultiModal() || len(message.GetImageContents()) != 1 {
+		t.Error("期望识别为多模态消息")
+	}
+
+	// 通用JSON解码得到的 []interface{} 也能被识别
+	var generic map[string]interface{}
+	json.Unmarshal([]byte(data), &generic)
+	loose := ChatCompletionMessage{Role: RoleUser, Content: generic["content"]}
+	if loose.GetContentAsString() != "第一段\n第二段" || !loose.IsMultiModal() {
+		t.Errorf("[]interface{} 内容未被识别: %q", loose.GetContentAsString())
+	}
+
+	// Content 可以直接作为消息内容编码
+	typed := ChatCompletionMessage{Role: RoleUser, Content: PartsOf(NewTextContent("hi"))}
+	encoded, _ := json.Marshal(typed)
+	if string(encoded) != `{"role":"user","content":[{"type":"text","text":"hi"}]}` {
+		t.Errorf("编码结果错误: %s", encoded)
+	}
+	if TextOf("hi").Value() != "hi" {
+		t.Error("纯文本内容应返回字符串")
+	}
+
+	if err := json.Unmarshal([]byte(`{"role":"user","content":42}`), &message); err == nil {
+		t.Error("非法的内容应返回错误")
+	}
+}
+
+func TestMessageObjectContentFallback(t *testing.T) {
+	var message ChatCompletionMessage
+	data := `{"role":"assistant","content":{"answer":42}}`
+	if err := json.Unmarshal([]byte(data), &message); err != nil {
+		t.Fatalf("对象形式的content不应导致解码失败: %v", err)
+	}
+	want := map[string]interface{}{"answer": float64(42)}
+	if !reflect.DeepEqual(message.Content, want) {
+		t.Errorf("期望保留原始值 %v，得到 %#v", want, message.Content)
+	}
+	if message.Role != RoleAssistant {
+		t.Errorf("期望role为assistant，得到 %s", message.Role)
+	}
+}
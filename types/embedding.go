@@ -0,0 +1,105 @@
+package types
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+)
+
+// 向量编码格式常量
+const (
+	EmbeddingEncodingFloat  = "float"
+	EmbeddingEncodingBase64 = "base64"
+)
+
+// EmbeddingRequest 向量化请求
+type EmbeddingRequest struct {
+	Model          string   `json:"model"`
+	Input          []string `json:"input"`
+	Dimensions     *int     `json:"dimensions,omitempty"`      // 输出维度，只有部分模型支持
+	EncodingFormat string   `json:"encoding_format,omitempty"` // "float" 或 "base64"，不影响解码后的结果
+	User           string   `json:"user,omitempty"`
+}
+
+// EmbeddingResponse 向量化响应
+type EmbeddingResponse struct {
+	Object string          `json:"object"`
+	Data   []Embedding     `json:"data"`
+	Model  string          `json:"model"`
+	Usage  *EmbeddingUsage `json:"usage,omitempty"`
+}
+
+// Embedding 单个输入的向量
+type Embedding struct {
+	Object    string    `json:"object"`
+	Index     int       `json:"index"` // 对应 EmbeddingRequest.Input 中的位置
+	Embedding []float32 `json:"embedding"`
+}
+
+// EmbeddingUsage 向量化的token用量
+type EmbeddingUsage struct {
+	PromptTokens int `json:"prompt_tokens"`
+	TotalTokens  int `json:"total_tokens"`
+}
+
+// UnmarshalJSON 解析向量，embedding 可以是浮点数数组，也可以是base64编码的小端float32
+func (e *Embedding) UnmarshalJSON(data []byte) error {
+	type alias Embedding
+	aux := struct {
+		*alias
+		Embedding json.RawMessage `json:"embedding"`
+	}{alias: (*alias)(e)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	raw := bytes.TrimSpace(aux.Embedding)
+	if len(raw) == 0 || raw[0] != '"' {
+		e.Embedding = nil
+		if len(raw) == 0 {
+			return nil
+		}
+		return json.Unmarshal(raw, &e.Embedding)
+	}
+
+	var encoded string
+	if err := json.Unmarshal(raw, &encoded); err != nil {
+		return err
+	}
+	vector, err := DecodeEmbeddingBase64(encoded)
+	if err != nil {
+		return err
+	}
+	e.Embedding = vector
+	return nil
+}
+
+// DecodeEmbeddingBase64 解码base64编码的小端float32向量
+func DecodeEmbeddingBase64(encoded string) ([]float32, error) {
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("types: decode embedding: %w", err)
+	}
+	if len(data)%4 != 0 {
+		return nil, fmt.Errorf("types: decode embedding: %d bytes is not a multiple of 4", len(data))
+	}
+	vector := make([]float32, len(data)/4)
+	for i := range vector {
+		vector[i] = math.Float32frombits(binary.LittleEndian.Uint32(data[i*4:]))
+	}
+	return vector, nil
+}
+
+// Vectors 按输入顺序返回所有向量
+func (r *EmbeddingResponse) Vectors() [][]float32 {
+	vectors := make([][]float32, len(r.Data))
+	for _, item := range r.Data {
+		if item.Index >= 0 && item.Index < len(vectors) {
+			vectors[item.Index] = item.Embedding
+		}
+	}
+	return vectors
+}
@@ -0,0 +1,38 @@
+package types
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestEmbeddingUnmarshal(t *testing.T) {
+	want := []float32{0.5, -1.25, 3}
+	raw := make([]byte, 4*len(want))
+	for i, v := range want {
+		binary.LittleEndian.PutUint32(raw[i*4:], math.Float32bits(v))
+	}
+
+	data := `{"object":"list","model":"m","data":[` +
+		`{"object":"embedding","index":1,"embedding":"` + base64.StdEncoding.EncodeToString(raw) + `"},` +
+		`{"object":"embedding","index":0,"embedding":[1,2]}]}`
+	var resp EmbeddingResponse
+	if err := json.Unmarshal([]byte(data), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(resp.Data[0].Embedding, want) {
+		t.Errorf("base64向量解码错误: %v", resp.Data[0].Embedding)
+	}
+	vectors := resp.Vectors()
+	if !reflect.DeepEqual(vectors[0], []float32{1, 2}) || !reflect.DeepEqual(vectors[1], want) {
+		t.Errorf("Vectors 应按输入顺序返回: %v", vectors)
+	}
+
+	var invalid Embedding
+	if err := json.Unmarshal([]byte(`{"embedding":"AAA="}`), &invalid); err == nil {
+		t.Error("长度不是4的倍数时应返回错误")
+	}
+}
@@ -0,0 +1,115 @@
+package types
+
+import (
+	"fmt"
+	"strings"
+)
+
+// FlattenPolicy 把多模态内容转换为纯文本时对非文本内容的处理策略
+type FlattenPolicy int
+
+const (
+	// FlattenPlaceholder 拼接所有文本，非文本内容替换为占位符，例如 "[image]"
+	FlattenPlaceholder FlattenPolicy = iota
+	// FlattenTextOnly 只拼接文本，丢弃非文本内容
+	FlattenTextOnly
+	// FlattenStrict 遇到非文本内容时返回 *UnsupportedModalityError
+	FlattenStrict
+)
+
+// String 返回策略名称
+func (p FlattenPolicy) String() string {
+	switch p {
+	case FlattenPlaceholder:
+		return "placeholder"
+	case FlattenTextOnly:
+		return "text_only"
+	case FlattenStrict:
+		return "strict"
+	default:
+		return fmt.Sprintf("FlattenPolicy(%d)", int(p))
+	}
+}
+
+// DefaultTextSeparator 拼接文本内容项时默认使用的分隔符
+const DefaultTextSeparator = "\n"
+
+// UnsupportedModalityError 服务商或模型不支持某种内容类型
+type UnsupportedModalityError struct {
+	Provider string // 服务商名称，可能为空
+	Modality string // 内容类型，例如 "image_url"
+}
+
+// Error 实现error接口
+func (e *UnsupportedModalityError) Error() string {
+	if e.Provider == "" {
+		return fmt.Sprintf("types: %s content is not supported", e.Modality)
+	}
+	return fmt.Sprintf("%s: %s content is not supported", e.Provider, e.Modality)
+}
+
+// Flattener 把消息内容转换为纯文本，供只支持文本的服务商使用
+type Flattener struct {
+	Policy    FlattenPolicy
+	Separator string // 文本之间的分隔符，为空时使用 DefaultTextSeparator
+	Provider  string // 写入 UnsupportedModalityError 的服务商名称
+}
+
+// Flatten 转换 ChatCompletionMessage.Content 中的值
+func (f Flattener) Flatten(content interface{}) (string, error) {
+	parsed, err := ParseContent(content)
+	if err != nil {
+		return "", err
+	}
+	if !parsed.IsMultiPart() {
+		return parsed.text, nil
+	}
+
+	separator := f.Separator
+	if separator == "" {
+		separator = DefaultTextSeparator
+	}
+	var texts []string
+	for _, part := range parsed.parts {
+		if part.Type == MessageContentTypeText {
+			texts = append(texts, part.Text)
+			continue
+		}
+		switch f.Policy {
+		case FlattenTextOnly:
+		case FlattenStrict:
+			return "", &UnsupportedModalityError{Provider: f.Provider, Modality: part.Type}
+		default:
+			texts = append(texts, Placeholder(part))
+		}
+	}
+	return strings.Join(texts, separator), nil
+}
+
+// FlattenContent 使用默认分隔符按给定策略转换内容
+func FlattenContent(content interface{}, policy FlattenPolicy) (string, error) {
+	return Flattener{Policy: policy}.Flatten(content)
+}
+
+// Placeholder 返回非文本内容项的占位文本
+func Placeholder(part MessageContent) string {
+	switch part.Type {
+	case MessageContentTypeImageURL:
+		// 只展示普通URL，data URL 过长且对模型没有意义
+		if part.ImageURL != nil && (strings.HasPrefix(part.ImageURL.URL, "http://") || strings.HasPrefix(part.ImageURL.URL, "https://")) {
+			return "[image: " + part.ImageURL.URL + "]"
+		}
+		return "[image]"
+	case MessageContentTypeInputAudio:
+		return "[audio]"
+	case MessageContentTypeVideo, MessageContentTypeVideoURL:
+		return "[video]"
+	case MessageContentTypeFile:
+		if part.File != nil && part.File.Filename != "" {
+			return "[file: " + part.File.Filename + "]"
+		}
+		return "[file]"
+	default:
+		return "[" + part.Type + "]"
+	}
+}
@@ -0,0 +1,46 @@
+package types
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestFlattenContent(t *testing.T) {
+	content := []MessageContent{
+		NewTextContent("第一段"),
+		NewImageContent("https://example.com/a.png"),
+		NewImageContent("data:image/png;base64,iVBORw0KGgo="),
+		{Type: MessageContentTypeFile, File: &FileContent{Filename: "report.pdf"}},
+		NewTextContent("第二段"),
+	}
+
+	tests := []struct {
+		policy FlattenPolicy
+		want   string
+	}{
+		{FlattenPlaceholder, "第一段\n[image: https://example.com/a.png]\n[image]\n[file: report.pdf]\n第二段"},
+		{FlattenTextOnly, "第一段\n第二段"},
+	}
+	for _, tt := range tests {
+		got, err := FlattenContent(content, tt.policy)
+		if err != nil || got != tt.want {
+			t.Errorf("%s: 期望 %q，得到 %q (%v)", tt.policy, tt.want, got, err)
+		}
+	}
+
+	got, err := Flattener{Policy: FlattenTextOnly, Separator: " "}.Flatten(content)
+	if err != nil || got != "第一段 第二段" {
+		t.Errorf("自定义分隔符结果错误: %q", got)
+	}
+
+	_, err = Flattener{Policy: FlattenStrict, Provider: "deepseek"}.Flatten(content)
+	var modalityErr *UnsupportedModalityError
+	if !errors.As(err, &modalityErr) || modalityErr.Modality != MessageContentTypeImageURL || err.Error() != "deepseek: image_url content is not supported" {
+		t.Errorf("期望 UnsupportedModalityError，得到 %v", err)
+	}
+
+	// 纯文本内容在任何策略下都原样返回
+	if got, err := FlattenContent("你好", FlattenStrict); err != nil || got != "你好" {
+		t.Errorf("纯文本结果错误: %q %v", got, err)
+	}
+}
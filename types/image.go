@@ -0,0 +1,283 @@
+package types
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"net/http"
+	"os"
+
+	// 注册GIF解码器
+	_ "image/gif"
+)
+
+// 本地图像的默认限制，兼顾OpenAI和通义千问VL的要求
+const (
+	DefaultImageMaxBytes     = 5 << 20 // 编码后的图像大小（base64之前）
+	DefaultImageMaxDimension = 2048    // 最长边的像素数
+	lowDetailDimension       = 512     // low detail 下模型看到的最长边
+	maxImageInputBytes       = 64 << 20
+	maxImagePixels           = 50_000_000 // 解码前检查，避免小文件声明超大尺寸时解码占用大量内存
+	minImageDimension        = 16
+)
+
+// ErrUnsupportedImageFormat 图像格式不受支持
+var ErrUnsupportedImageFormat = errors.New("types: unsupported image format")
+
+// supportedImageTypes 服务商普遍接受的图像格式
+var supportedImageTypes = map[string]bool{
+	"image/png":  true,
+	"image/jpeg": true,
+	"image/gif":  true,
+	"image/webp": true,
+}
+
+// imageConfig 本地图像处理配置
+type imageConfig struct {
+	maxBytes     int
+	maxDimension int
+	detail       string
+	quality      int
+}
+
+// ImageOption 本地图像处理选项
+type ImageOption func(*imageConfig)
+
+// WithImageMaxBytes 设置编码后图像的最大字节数，默认 DefaultImageMaxBytes
+func WithImageMaxBytes(n int) ImageOption {
+	return func(c *imageConfig) {
+		c.maxBytes = n
+	}
+}
+
+// WithImageMaxDimension 设置图像最长边的最大像素数，默认 DefaultImageMaxDimension
+func WithImageMaxDimension(n int) ImageOption {
+	return func(c *imageConfig) {
+		c.maxDimension = n
+	}
+}
+
+// WithImageDetail 指定图像详细度
+// 默认根据图像尺寸选择：最长边不超过512像素时使用low，否则使用high
+// 指定low时图像会缩小到512像素以内，因为模型在low模式下不会看到更多细节
+func WithImageDetail(detail string) ImageOption {
+	return func(c *imageConfig) {
+		c.detail = detail
+	}
+}
+
+// WithImageQuality 设置重新编码为JPEG时的初始质量（1-100），默认85
+func WithImageQuality(quality int) ImageOption {
+	return func(c *imageConfig) {
+		c.quality = quality
+	}
+}
+
+// PreparedImage 处理后可以发送给服务商的图像
+type PreparedImage struct {
+	MIMEType string
+	Data     []byte
+	Width    int // WebP无法解码，宽高为0
+	Height   int // WebP无法解码，宽高为0
+	Detail   string
+	Resized  bool // 是否被缩小或重新编码
+}
+
+// DataURL 返回 data:<mime>;base64,<data> 形式的URL
+func (img *PreparedImage) DataURL() string {
+	return "data:" + img.MIMEType + ";base64," + base64.StdEncoding.EncodeToString(img.Data)
+}
+
+// Content 返回图像内容项
+func (img *PreparedImage) Content() MessageContent {
+	return NewImageContent(img.DataURL(), img.Detail)
+}
+
+// NewImageContentFromFile 从本地文件创建图像内容
+func NewImageContentFromFile(path string, opts ...ImageOption) (MessageContent, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return MessageContent{}, fmt.Errorf("types: open image: %w", err)
+	}
+	defer file.Close()
+	return NewImageContentFromReader(file, opts...)
+}
+
+// NewImageContentFromReader 从 io.Reader 创建图像内容
+func NewImageContentFromReader(r io.Reader, opts ...ImageOption) (MessageContent, error) {
+	data, err := io.ReadAll(io.LimitReader(r, maxImageInputBytes+1))
+	if err != nil {
+		return MessageContent{}, fmt.Errorf("types: read image: %w", err)
+	}
+	if len(data) > maxImageInputBytes {
+		return MessageContent{}, fmt.Errorf("types: image is larger than %d bytes", maxImageInputBytes)
+	}
+	return NewImageContentFromBytes(data, opts...)
+}
+
+// NewImageContentFromBytes 从图像数据创建图像内容
+func NewImageContentFromBytes(data []byte, opts ...ImageOption) (MessageContent, error) {
+	img, err := PrepareImage(data, opts...)
+	if err != nil {
+		return MessageContent{}, err
+	}
+	return img.Content(), nil
+}
+
+// PrepareImage 识别图像格式，并在超出限制时缩小或重新编码
+// 支持PNG、JPEG、GIF和WebP；WebP无法用标准库解码，超出大小限制时直接返回错误
+func PrepareImage(data []byte, opts ...ImageOption) (*PreparedImage, error) {
+	config := imageConfig{
+		maxBytes:     DefaultImageMaxBytes,
+		maxDimension: DefaultImageMaxDimension,
+		quality:      85,
+	}
+	for _, opt := range opts {
+		opt(&config)
+	}
+
+	mimeType := http.DetectContentType(data)
+	if !supportedImageTypes[mimeType] {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedImageFormat, mimeType)
+	}
+
+	if mimeType == "image/webp" {
+		if len(data) > config.maxBytes {
+			return nil, fmt.Errorf("types: webp image is %d bytes, larger than the %d byte limit, and cannot be re-encoded", len(data), config.maxBytes)
+		}
+		detail := config.detail
+		if detail == "" {
+			detail = ImageDetailAuto
+		}
+		return &PreparedImage{MIMEType: mimeType, Data: data, Detail: detail}, nil
+	}
+
+	header, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("types: decode image: %w", err)
+	}
+	if int64(header.Width)*int64(header.Height) > maxImagePixels {
+		return nil, fmt.Errorf("types: image is %dx%d, more than %d pixels", header.Width, header.Height, maxImagePixels)
+	}
+
+	maxDimension := config.maxDimension
+	detail := config.detail
+	if detail == "" {
+		detail = ImageDetailHigh
+		if max(header.Width, header.Height) <= lowDetailDimension {
+			detail = ImageDetailLow
+		}
+	}
+	if detail == ImageDetailLow && maxDimension > lowDetailDimension {
+		maxDimension = lowDetailDimension
+	}
+
+	// 尺寸和大小都在限制内时保留原图
+	if header.Width <= maxDimension && header.Height <= maxDimension && len(data) <= config.maxBytes {
+		return &PreparedImage{MIMEType: mimeType, Data: data, Width: header.Width, Height: header.Height, Detail: detail}, nil
+	}
+
+	decoded, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("types: decode image: %w", err)
+	}
+	return reencodeImage(decoded, maxDimension, detail, config)
+}
+
+// reencodeImage 把图像缩小到限制以内并重新编码
+// 不透明的图像编码为JPEG并逐步降低质量，透明图像编码为PNG；仍然过大时继续缩小
+func reencodeImage(src image.Image, maxDimension int, detail string, config imageConfig) (*PreparedImage, error) {
+	rgba := image.NewRGBA(image.Rect(0, 0, src.Bounds().Dx(), src.Bounds().Dy()))
+	draw.Draw(rgba, rgba.Bounds(), src, src.Bounds().Min, draw.Src)
+	opaque := rgba.Opaque()
+
+	width, height := fitDimensions(rgba.Bounds().Dx(), rgba.Bounds().Dy(), maxDimension)
+	for {
+		scaled := rgba
+		if width != rgba.Bounds().Dx() || height != rgba.Bounds().Dy() {
+			scaled = downscale(rgba, width, height)
+		}
+
+		var buf bytes.Buffer
+		mimeType := "image/png"
+		if opaque {
+			mimeType = "image/jpeg"
+			for quality := config.quality; ; quality -= 15 {
+				buf.Reset()
+				if err := jpeg.Encode(&buf, scaled, &jpeg.Options{Quality: max(quality, 1)}); err != nil {
+					return nil, fmt.Errorf("types: encode image: %w", err)
+				}
+				if buf.Len() <= config.maxBytes || quality <= 40 {
+					break
+				}
+			}
+		} else if err := (&png.Encoder{CompressionLevel: png.BestCompression}).Encode(&buf, scaled); err != nil {
+			return nil, fmt.Errorf("types: encode image: %w", err)
+		}
+
+		if buf.Len() <= config.maxBytes {
+			return &PreparedImage{
+				MIMEType: mimeType,
+				Data:     buf.Bytes(),
+				Width:    width,
+				Height:   height,
+				Detail:   detail,
+				Resized:  true,
+			}, nil
+		}
+		if max(width, height)*3/4 < minImageDimension {
+			return nil, fmt.Errorf("types: image cannot be reduced below %d bytes", config.maxBytes)
+		}
+		width, height = max(width*3/4, 1), max(height*3/4, 1)
+	}
+}
+
+// fitDimensions 按比例缩放，使最长边不超过 maxDimension
+func fitDimensions(width, height, maxDimension int) (int, int) {
+	if width <= maxDimension && height <= maxDimension {
+		return width, height
+	}
+	if width >= height {
+		return maxDimension, max(height*maxDimension/width, 1)
+	}
+	return max(width*maxDimension/height, 1), maxDimension
+}
+
+// downscale 使用区域平均（box filter）缩小图像
+func downscale(src *image.RGBA, width, height int) *image.RGBA {
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	srcWidth, srcHeight := src.Bounds().Dx(), src.Bounds().Dy()
+	for y := 0; y < height; y++ {
+		y0 := y * srcHeight / height
+		y1 := max((y+1)*srcHeight/height, y0+1)
+		for x := 0; x < width; x++ {
+			x0 := x * srcWidth / width
+			x1 := max((x+1)*srcWidth/width, x0+1)
+
+			var r, g, b, a, n uint32
+			for sy := y0; sy < y1; sy++ {
+				offset := sy*src.Stride + x0*4
+				for sx := x0; sx < x1; sx++ {
+					r += uint32(src.Pix[offset])
+					g += uint32(src.Pix[offset+1])
+					b += uint32(src.Pix[offset+2])
+					a += uint32(src.Pix[offset+3])
+					offset += 4
+					n++
+				}
+			}
+			i := y*dst.Stride + x*4
+			dst.Pix[i] = uint8(r / n)
+			dst.Pix[i+1] = uint8(g / n)
+			dst.Pix[i+2] = uint8(b / n)
+			dst.Pix[i+3] = uint8(a / n)
+		}
+	}
+	return dst
+}
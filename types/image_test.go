@@ -0,0 +1,133 @@
+package types
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// encodeTestPNG 生成带噪点的PNG图像，alpha为255时图像不透明
+func encodeTestPNG(t *testing.T, width, height int, alpha uint8) []byte {
+	t.Helper()
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.Set(x, y, color.NRGBA{R: uint8(x * 7), G: uint8(y * 13), B: uint8(x * y), A: alpha})
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestPrepareImage(t *testing.T) {
+	small := encodeTestPNG(t, 100, 80, 255)
+	img, err := PrepareImage(small)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if img.Resized || img.Detail != ImageDetailLow || img.MIMEType != "image/png" || !bytes.Equal(img.Data, small) {
+		t.Errorf("小图应保留原图并使用low: %+v", img)
+	}
+
+	large := encodeTestPNG(t, 1200, 800, 255)
+	img, err = PrepareImage(large, WithImageMaxDimension(600))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !img.Resized || img.Width != 600 || img.Height != 400 || img.MIMEType != "image/jpeg" || img.Detail != ImageDetailHigh {
+		t.Errorf("大图应缩小并重新编码为JPEG: %dx%d %s %s", img.Width, img.Height, img.MIMEType, img.Detail)
+	}
+
+	// 指定low时缩小到512像素以内
+	img, err = PrepareImage(large, WithImageDetail(ImageDetailLow))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if img.Width != 512 || img.Height != 341 || img.Detail != ImageDetailLow {
+		t.Errorf("low detail 应缩小到512像素: %dx%d", img.Width, img.Height)
+	}
+
+	// 透明图像保持PNG
+	img, err = PrepareImage(encodeTestPNG(t, 800, 800, 128), WithImageMaxDimension(400))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if img.MIMEType != "image/png" || img.Width != 400 {
+		t.Errorf("透明图像应编码为PNG: %s %d", img.MIMEType, img.Width)
+	}
+
+	// 超出字节限制时降低质量并继续缩小
+	img, err = PrepareImage(large, WithImageMaxBytes(20<<10))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(img.Data) > 20<<10 || !img.Resized {
+		t.Errorf("期望不超过20KB，得到 %d 字节", len(img.Data))
+	}
+}
+
+func TestPrepareImageUnsupported(t *testing.T) {
+	bmp := append([]byte("BM"), make([]byte, 64)...)
+	if _, err := PrepareImage(bmp); !errors.Is(err, ErrUnsupportedImageFormat) {
+		t.Errorf("期望 ErrUnsupportedImageFormat，得到 %v", err)
+	}
+	if _, err := PrepareImage([]byte("not an image")); !errors.Is(err, ErrUnsupportedImageFormat) {
+		t.Errorf("期望 ErrUnsupportedImageFormat，得到 %v", err)
+	}
+
+	webp := append([]byte("RIFF\x00\x00\x00\x00WEBPVP8 "), make([]byte, 64)...)
+	img, err := PrepareImage(webp)
+	if err != nil || img.MIMEType != "image/webp" || img.Detail != ImageDetailAuto {
+		t.Errorf("WebP应原样返回: %+v %v", img, err)
+	}
+	if _, err := PrepareImage(webp, WithImageMaxBytes(10)); err == nil {
+		t.Error("超出限制的WebP应返回错误")
+	}
+}
+
+func TestPrepareImagePixelLimit(t *testing.T) {
+	// 修改PNG头部声明的尺寸，文件很小但解码需要大量内存
+	data := encodeTestPNG(t, 1, 1, 255)
+	ihdr := data[8:]
+	binary.BigEndian.PutUint32(ihdr[8:12], 20000)
+	binary.BigEndian.PutUint32(ihdr[12:16], 20000)
+	binary.BigEndian.PutUint32(ihdr[21:25], crc32.ChecksumIEEE(ihdr[4:21]))
+
+	_, err := PrepareImage(data)
+	if err == nil || !strings.Contains(err.Error(), "pixels") {
+		t.Errorf("期望超出像素限制的错误，得到 %v", err)
+	}
+}
+
+func TestNewImageContentFromFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "photo.png")
+	data := encodeTestPNG(t, 64, 64, 255)
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	content, err := NewImageContentFromFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "data:image/png;base64," + base64.StdEncoding.EncodeToString(data)
+	if content.Type != MessageContentTypeImageURL || content.ImageURL.URL != want || content.ImageURL.Detail != ImageDetailLow {
+		t.Errorf("图像内容错误: %s %s", content.Type, content.ImageURL.Detail)
+	}
+
+	if _, err := NewImageContentFromFile(filepath.Join(t.TempDir(), "missing.png")); err == nil || !strings.Contains(err.Error(), "open image") {
+		t.Errorf("文件不存在时应返回错误，得到 %v", err)
+	}
+}
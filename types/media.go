@@ -0,0 +1,106 @@
+package types
+
+import (
+	"strings"
+)
+
+// 视频内容类型常量（通义千问VL/Omni兼容模式）
+const (
+	MessageContentTypeVideo    = "video"     // 视频帧列表
+	MessageContentTypeVideoURL = "video_url" // 视频文件URL
+)
+
+// 输出模态常量，用于 ChatCompletionRequest.Modalities
+const (
+	ModalityText  = "text"
+	ModalityAudio = "audio"
+)
+
+// VideoURL 视频URL结构
+type VideoURL struct {
+	URL string `json:"url"`
+}
+
+// AudioOutputOptions 音频输出参数，配合 Modalities 包含 "audio" 使用
+type AudioOutputOptions struct {
+	Voice  string `json:"voice"`  // 音色，例如OpenAI的 "alloy"，通义千问Omni的 "Cherry"
+	Format string `json:"format"` // 音频格式，例如 "wav"、"mp3"
+}
+
+// MessageAudio 模型返回的音频，流式响应中按增量返回
+type MessageAudio struct {
+	ID         string `json:"id,omitempty"`
+	Data       string `json:"data,omitempty"`       // base64编码的音频数据
+	Transcript string `json:"transcript,omitempty"` // 音频对应的文本
+	ExpiresAt  int64  `json:"expires_at,omitempty"`
+}
+
+// NewInputAudioContent 创建音频内容，data 可以是base64数据、data URL或（部分服务商支持的）URL
+func NewInputAudioContent(data, format string) MessageContent {
+	return MessageContent{
+		Type:       MessageContentTypeInputAudio,
+		InputAudio: &InputAudio{Data: data, Format: format},
+	}
+}
+
+// NewVideoURLContent 创建视频文件内容
+func NewVideoURLContent(url string) MessageContent {
+	return MessageContent{
+		Type:     MessageContentTypeVideoURL,
+		VideoURL: &VideoURL{URL: url},
+	}
+}
+
+// NewVideoFramesContent 创建由图像帧组成的视频内容
+func NewVideoFramesContent(frames ...string) MessageContent {
+	return MessageContent{
+		Type:  MessageContentTypeVideo,
+		Video: frames,
+	}
+}
+
+// MapContentParts 对所有多部分消息的内容项执行转换，返回新的消息列表，不修改原消息
+// 转换函数返回错误时立即停止，可用于服务商的模态校验和格式转换。
+// 只转换内容项数组，无法解析的内容（例如对象形式的content）原样保留
+func MapContentParts(messages []ChatCompletionMessage, transform func(part MessageContent) (MessageContent, error)) ([]ChatCompletionMessage, error) {
+	result := make([]ChatCompletionMessage, len(messages))
+	for i, message := range messages {
+		result[i] = message
+		content, err := ParseContent(message.Content)
+		if err != nil || !content.IsMultiPart() {
+			continue
+		}
+		parts := make([]MessageContent, len(content.parts))
+		for j, part := range content.parts {
+			if parts[j], err = transform(part); err != nil {
+				return nil, err
+			}
+		}
+		result[i].Content = parts
+	}
+	return result, nil
+}
+
+// HasModality 请求的输出模态是否包含指定模态
+func (r *ChatCompletionRequest) HasModality(modality string) bool {
+	for _, m := range r.Modalities {
+		if m == modality {
+			return true
+		}
+	}
+	return false
+}
+
+// SplitDataURL 拆分 data:<mime>;base64,<data> 形式的URL，不是data URL时 ok 为false
+func SplitDataURL(url string) (mimeType, data string, ok bool) {
+	rest, found := strings.CutPrefix(url, "data:")
+	if !found {
+		return "", "", false
+	}
+	header, data, found := strings.Cut(rest, ",")
+	if !found {
+		return "", "", false
+	}
+	mimeType, _, _ = strings.Cut(header, ";")
+	return mimeType, data, true
+}
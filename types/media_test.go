@@ -0,0 +1,70 @@
+package types
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestMapContentParts(t *testing.T) {
+	messages := []ChatCompletionMessage{
+		NewTextMessage(RoleSystem, "系统"),
+		NewMultiModalMessage(RoleUser, []MessageContent{
+			NewTextContent("看看这段视频"),
+			NewVideoURLContent("https://example.com/a.mp4"),
+		}),
+	}
+
+	result, err := MapContentParts(messages, func(part MessageContent) (MessageContent, error) {
+		if part.Type == MessageContentTypeVideoURL {
+			return NewVideoFramesContent("https://example.com/1.jpg", "https://example.com/2.jpg"), nil
+		}
+		return part, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result[0].Content != "系统" {
+		t.Errorf("纯文本消息不应改变，得到 %v", result[0].Content)
+	}
+	if parts := result[1].Content.([]MessageContent); parts[1].Type != MessageContentTypeVideo || len(parts[1].Video) != 2 {
+		t.Errorf("内容项未被转换: %+v", parts[1])
+	}
+	if parts := messages[1].Content.([]MessageContent); parts[1].Type != MessageContentTypeVideoURL {
+		t.Error("原消息不应被修改")
+	}
+
+	unsupported := &UnsupportedModalityError{Provider: "test", Modality: MessageContentTypeVideoURL}
+	_, err = MapContentParts(messages, func(part MessageContent) (MessageContent, error) {
+		if part.Type != MessageContentTypeText {
+			return part, unsupported
+		}
+		return part, nil
+	})
+	if !errors.Is(err, unsupported) {
+		t.Errorf("期望返回转换函数的错误，得到 %v", err)
+	}
+
+	passthrough := []ChatCompletionMessage{{Role: RoleUser, Content: map[string]interface{}{"foo": "bar"}}}
+	result, err = MapContentParts(passthrough, func(part MessageContent) (MessageContent, error) {
+		return part, unsupported
+	})
+	if err != nil {
+		t.Fatalf("无法解析的内容应原样保留，得到 %v", err)
+	}
+	if content, ok := result[0].Content.(map[string]interface{}); !ok || content["foo"] != "bar" {
+		t.Errorf("对象形式的content不应改变，得到 %v", result[0].Content)
+	}
+}
+
+func TestSplitDataURL(t *testing.T) {
+	mimeType, data, ok := SplitDataURL("data:audio/wav;base64,UklGRg==")
+	if !ok || mimeType != "audio/wav" || data != "UklGRg==" {
+		t.Errorf("拆分结果错误: %q %q %v", mimeType, data, ok)
+	}
+	if _, _, ok := SplitDataURL("https://example.com/a.wav"); ok {
+		t.Error("普通URL不是data URL")
+	}
+	if Placeholder(NewVideoFramesContent("a.jpg")) != "[video]" {
+		t.Error("视频占位符错误")
+	}
+}
@@ -0,0 +1,15 @@
+package types
+
+// Model /models 接口返回的模型
+type Model struct {
+	ID      string `json:"id"`
+	Object  string `json:"object"`
+	Created int64  `json:"created,omitempty"`
+	OwnedBy string `json:"owned_by,omitempty"`
+}
+
+// ModelList /models 接口的响应
+type ModelList struct {
+	Object string  `json:"object"`
+	Data   []Model `json:"data"`
+}
@@ -1,6 +1,10 @@
 package types
 
-import "time"
+import (
+	"bytes"
+	"encoding/json"
+	"time"
+)
 
 // ChatCompletionRequest OpenAI兼容的聊天完成请求
 type ChatCompletionRequest struct {
@@ -23,6 +27,18 @@ type ChatCompletionRequest struct {
 	Logprobs         bool                    `json:"logprobs,omitempty"`
 	TopLogprobs      *int                    `json:"top_logprobs,omitempty"`
 
+	// 输出模态，例如 []string{"text", "audio"}，需要音频输出时配合 Audio 使用
+	Modalities []string            `json:"modalities,omitempty"`
+	Audio      *AudioOutputOptions `json:"audio,omitempty"`
+
+	// 推理控制，由服务商转换为各自的参数，推荐使用它代替下面的服务商参数
+	Reasoning *Reasoning `json:"-"`
+	// 历史消息中思考内容的处理策略，默认使用服务商的策略
+	ReasoningHistory ReasoningHistoryPolicy `json:"-"`
+
+	// OpenAI推理模型参数
+	ReasoningEffort string `json:"reasoning_effort,omitempty"`
+
 	// 阿里云特有参数
 	EnableThinking *bool `json:"enable_thinking,omitempty"` // 是否开启思考模式
 	ThinkingBudget *int  `json:"thinking_budget,omitempty"` // 思考预算token数
@@ -31,20 +47,61 @@ type ChatCompletionRequest struct {
 // ChatCompletionMessage 聊天消息
 type ChatCompletionMessage struct {
 	Role       string      `json:"role"`
-	Content    interface{} `json:"content,omitempty"` // 支持string或[]MessageContent
+	Content    interface{} `json:"content,omitempty"` // 支持string、[]MessageContent或Content
 	Name       string      `json:"name,omitempty"`
 	ToolCalls  []ToolCall  `json:"tool_calls,omitempty"`
 	ToolCallID string      `json:"tool_call_id,omitempty"`
 
-	// DeepSeek特有字段
+	// 模型返回的音频（输出模态包含audio时）
+	Audio *MessageAudio `json:"audio,omitempty"`
+
+	// 思考内容，作为请求历史发送时按 ChatCompletionRequest.ReasoningHistory 处理
 	ReasoningContent string `json:"reasoning_content,omitempty"`
 }
 
-// MessageContent 消息内容项，支持文本和图像
+// UnmarshalJSON 解析消息，content 为数组时还原为 []MessageContent，而不是 []interface{}
+// content 为对象时按 map[string]interface{} 保留，不返回错误
+// 部分服务商（例如Ollama）以 reasoning 字段返回思考内容，统一转换为 ReasoningContent
+func (m *ChatCompletionMessage) UnmarshalJSON(data []byte) error {
+	type alias ChatCompletionMessage
+	aux := struct {
+		*alias
+		Content   json.RawMessage `json:"content,omitempty"`
+		Reasoning string          `json:"reasoning,omitempty"`
+	}{alias: (*alias)(m)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	if m.ReasoningContent == "" {
+		m.ReasoningContent = aux.Reasoning
+	}
+
+	m.Content = nil
+	raw := bytes.TrimSpace(aux.Content)
+	if len(raw) == 0 || bytes.Equal(raw, []byte("null")) {
+		return nil
+	}
+	if raw[0] == '{' {
+		// 部分服务商以对象返回content，保留原始的解码结果
+		return json.Unmarshal(raw, &m.Content)
+	}
+	var content Content
+	if err := content.UnmarshalJSON(raw); err != nil {
+		return err
+	}
+	m.Content = content.Value()
+	return nil
+}
+
+// MessageContent 消息内容项，支持文本、图像、音频和文件
 type MessageContent struct {
-	Type     string    `json:"type"`                // "text" 或 "image_url"
-	Text     string    `json:"text,omitempty"`      // 文本内容
-	ImageURL *ImageURL `json:"image_url,omitempty"` // 图像URL
+	Type       string       `json:"type"`                  // "text"、"image_url"、"input_audio"、"file"、"video" 或 "video_url"
+	Text       string       `json:"text,omitempty"`        // 文本内容
+	ImageURL   *ImageURL    `json:"image_url,omitempty"`   // 图像URL
+	InputAudio *InputAudio  `json:"input_audio,omitempty"` // 音频输入
+	File       *FileContent `json:"file,omitempty"`        // 文件输入
+	VideoURL   *VideoURL    `json:"video_url,omitempty"`   // 视频文件URL
+	Video      []string     `json:"video,omitempty"`       // 视频帧的图像URL列表
 }
 
 // ImageURL 图像URL结构
@@ -96,7 +153,16 @@ type CompletionTokensDetails struct {
 
 // ResponseFormat 响应格式
 type ResponseFormat struct {
-	Type string `json:"type"` // "text" or "json_object"
+	Type       string            `json:"type"`                  // "text"、"json_object" 或 "json_schema"
+	JSONSchema *JSONSchemaFormat `json:"json_schema,omitempty"` // Type为json_schema时使用
+}
+
+// JSONSchemaFormat 结构化输出的JSON Schema
+type JSONSchemaFormat struct {
+	Name        string      `json:"name"` // 只能包含字母、数字、下划线和连字符
+	Description string      `json:"description,omitempty"`
+	Schema      interface{} `json:"schema"`
+	Strict      bool        `json:"strict,omitempty"` // 严格模式，要求Schema满足OpenAI的strict限制
 }
 
 // StreamOptions 流选项
@@ -126,6 +192,7 @@ type ResponseToolFunction struct {
 
 // ToolCall 工具调用
 type ToolCall struct {
+	Index    *int                 `json:"index,omitempty"` // 流式响应中工具调用的序号
 	ID       string               `json:"id"`
 	Type     string               `json:"type"`
 	Function ResponseToolFunction `json:"function"`
@@ -168,12 +235,15 @@ const (
 	RoleUser      = "user"
 	RoleAssistant = "assistant"
 	RoleTool      = "tool"
+	RoleDeveloper = "developer" // OpenAI推理模型用于代替system
+	RoleFunction  = "function"  // 已废弃的函数调用结果，OpenAI仍然接受
 )
 
 // 响应格式常量
 const (
 	ResponseFormatText       = "text"
 	ResponseFormatJSONObject = "json_object"
+	ResponseFormatJSONSchema = "json_schema"
 )
 
 // 完成原因常量
@@ -214,9 +284,12 @@ func (r *ChatCompletionRequest) WithThinkingBudget(budget int) *ChatCompletionRe
 	return r
 }
 
-// IsThinkingEnabled 检查是否开启了思考模式
+// IsThinkingEnabled 检查是否开启了思考模式，同时考虑 Reasoning 和旧的服务商参数
 func (r *ChatCompletionRequest) IsThinkingEnabled() bool {
-	return r.EnableThinking != nil && *r.EnableThinking
+	if r.Reasoning != nil {
+		return r.Reasoning.IsEnabled()
+	}
+	return (r.EnableThinking != nil && *r.EnableThinking) || r.ReasoningEffort != ""
 }
 
 // GetThinkingBudget 获取思考预算
@@ -278,48 +351,23 @@ func NewImageContent(imageURL string, detail ...string) MessageContent {
 	}
 }
 
-// GetContentAsString 获取消息内容的字符串表示
+// GetContentAsString 获取消息内容的字符串表示，多个文本部分用换行拼接，忽略非文本内容
 func (m *ChatCompletionMessage) GetContentAsString() string {
-	switch content := m.Content.(type) {
-	case string:
-		return content
-	case []MessageContent:
-		// 只返回文本内容，忽略图像
-		var textParts []string
-		for _, part := range content {
-			if part.Type == MessageContentTypeText {
-				textParts = append(textParts, part.Text)
-			}
-		}
-		if len(textParts) > 0 {
-			return textParts[0] // 返回第一个文本部分
-		}
-		return ""
-	default:
-		return ""
-	}
+	text, _ := FlattenContent(m.Content, FlattenTextOnly)
+	return text
 }
 
-// IsMultiModal 检查消息是否包含多模态内容
+// IsMultiModal 检查消息是否包含文本以外的内容
 func (m *ChatCompletionMessage) IsMultiModal() bool {
-	if contents, ok := m.Content.([]MessageContent); ok {
-		for _, content := range contents {
-			if content.Type == MessageContentTypeImageURL {
-				return true
-			}
-		}
-	}
-	return false
+	return m.GetContent().HasNonText()
 }
 
 // GetImageContents 获取消息中的所有图像内容
 func (m *ChatCompletionMessage) GetImageContents() []MessageContent {
 	var images []MessageContent
-	if contents, ok := m.Content.([]MessageContent); ok {
-		for _, content := range contents {
-			if content.Type == MessageContentTypeImageURL {
-				images = append(images, content)
-			}
+	for _, part := range m.GetContent().Parts() {
+		if part.Type == MessageContentTypeImageURL {
+			images = append(images, part)
 		}
 	}
 	return images
@@ -0,0 +1,172 @@
+package types
+
+import (
+	"fmt"
+)
+
+// 推理强度常量，用于 Reasoning.Effort
+const (
+	ReasoningEffortMinimal = "minimal"
+	ReasoningEffortLow     = "low"
+	ReasoningEffortMedium  = "medium"
+	ReasoningEffortHigh    = "high"
+)
+
+// Reasoning 与服务商无关的推理（思考）控制，由各服务商转换为自己的参数：
+//   - OpenAI推理模型：Effort 转换为 reasoning_effort
+//   - 阿里云通义千问：Enabled 转换为 enable_thinking，BudgetTokens 转换为 thinking_budget
+//   - DeepSeek：由模型决定（deepseek-reasoner），只能检查设置是否与模型一致
+//   - Ollama：Effort 转换为 reasoning_effort，关闭时为 "none"
+//
+// 服务商无法表达的设置会返回 *ReasoningError，而不是被忽略
+type Reasoning struct {
+	Enabled      *bool  // 是否开启推理，nil表示由 Effort 和 BudgetTokens 决定，都未设置时使用模型的默认行为
+	Effort       string // 推理强度，见 ReasoningEffort 常量
+	BudgetTokens int    // 推理最多使用的token数，0表示不限制
+}
+
+// IsEnabled 是否要求开启推理
+func (r Reasoning) IsEnabled() bool {
+	if r.Enabled != nil {
+		return *r.Enabled
+	}
+	return r.Effort != "" || r.BudgetTokens > 0
+}
+
+// IsDisabled 是否明确要求关闭推理
+func (r Reasoning) IsDisabled() bool {
+	return r.Enabled != nil && !*r.Enabled
+}
+
+// validate 检查设置是否自相矛盾
+func (r Reasoning) validate() error {
+	switch r.Effort {
+	case "", ReasoningEffortMinimal, ReasoningEffortLow, ReasoningEffortMedium, ReasoningEffortHigh:
+	default:
+		return fmt.Errorf("types: unknown reasoning effort %q", r.Effort)
+	}
+	if r.BudgetTokens < 0 {
+		return fmt.Errorf("types: reasoning budget must not be negative, got %d", r.BudgetTokens)
+	}
+	if r.IsDisabled() && (r.Effort != "" || r.BudgetTokens > 0) {
+		return fmt.Errorf("types: reasoning effort or budget is set but reasoning is disabled")
+	}
+	return nil
+}
+
+// ReasoningError 服务商或模型无法表达请求的推理设置
+type ReasoningError struct {
+	Provider string
+	Model    string
+	Reason   string // 例如 "reasoning effort is not supported"
+}
+
+// Error 实现error接口
+func (e *ReasoningError) Error() string {
+	return fmt.Sprintf("%s: %s for model %s", e.Provider, e.Reason, e.Model)
+}
+
+// WithReasoning 设置推理控制
+func (r *ChatCompletionRequest) WithReasoning(reasoning Reasoning) *ChatCompletionRequest {
+	r.Reasoning = &reasoning
+	return r
+}
+
+// ResolveReasoning 返回请求的推理设置，未设置时返回nil
+// Reasoning 未设置时使用旧的服务商参数（EnableThinking、ThinkingBudget、ReasoningEffort），两者不能同时使用
+func (r *ChatCompletionRequest) ResolveReasoning() (*Reasoning, error) {
+	legacy := r.EnableThinking != nil || r.ThinkingBudget != nil || r.ReasoningEffort != ""
+	if r.Reasoning != nil {
+		if legacy {
+			return nil, fmt.Errorf("types: Reasoning cannot be combined with EnableThinking, ThinkingBudget or ReasoningEffort")
+		}
+		reasoning := *r.Reasoning
+		return &reasoning, reasoning.validate()
+	}
+	if !legacy {
+		return nil, nil
+	}
+
+	reasoning := Reasoning{Enabled: r.EnableThinking, Effort: r.ReasoningEffort}
+	// 旧参数关闭思考时保留的预算没有作用
+	if r.ThinkingBudget != nil && !reasoning.IsDisabled() {
+		reasoning.BudgetTokens = *r.ThinkingBudget
+	}
+	return &reasoning, reasoning.validate()
+}
+
+// ReasoningTokens 返回推理使用的token数，服务商未返回时为0
+func (u *Usage) ReasoningTokens() int {
+	if u == nil || u.CompletionTokensDetails == nil {
+		return 0
+	}
+	return u.CompletionTokensDetails.ReasoningTokens
+}
+
+// ReasoningHistoryPolicy 请求历史中assistant消息的思考内容（ReasoningContent）的处理策略
+type ReasoningHistoryPolicy int
+
+const (
+	// ReasoningHistoryAuto 使用服务商的默认策略
+	ReasoningHistoryAuto ReasoningHistoryPolicy = iota
+	// ReasoningHistoryStrip 移除所有思考内容，例如DeepSeek收到思考内容时会返回400
+	ReasoningHistoryStrip
+	// ReasoningHistoryToolLoop 只保留当前工具调用循环（最后一条user消息之后）中的思考内容
+	ReasoningHistoryToolLoop
+	// ReasoningHistoryKeep 保留所有思考内容
+	ReasoningHistoryKeep
+)
+
+// String 返回策略名称
+func (p ReasoningHistoryPolicy) String() string {
+	switch p {
+	case ReasoningHistoryAuto:
+		return "auto"
+	case ReasoningHistoryStrip:
+		return "strip"
+	case ReasoningHistoryToolLoop:
+		return "tool_loop"
+	case ReasoningHistoryKeep:
+		return "keep"
+	default:
+		return fmt.Sprintf("ReasoningHistoryPolicy(%d)", int(p))
+	}
+}
+
+// ApplyReasoningHistory 按策略处理消息中的思考内容，返回的切片在需要修改时是副本，不修改原消息
+// policy 为 ReasoningHistoryAuto 时使用 fallback
+func ApplyReasoningHistory(messages []ChatCompletionMessage, policy, fallback ReasoningHistoryPolicy) []ChatCompletionMessage {
+	if policy == ReasoningHistoryAuto {
+		policy = fallback
+	}
+	if policy == ReasoningHistoryKeep || policy == ReasoningHistoryAuto {
+		return messages
+	}
+
+	// 工具调用循环从最后一条user消息之后开始
+	keepFrom := len(messages)
+	if policy == ReasoningHistoryToolLoop {
+		keepFrom = 0
+		for i := len(messages) - 1; i >= 0; i-- {
+			if messages[i].Role == RoleUser {
+				keepFrom = i + 1
+				break
+			}
+		}
+	}
+
+	var result []ChatCompletionMessage
+	for i := 0; i < keepFrom && i < len(messages); i++ {
+		if messages[i].ReasoningContent == "" {
+			continue
+		}
+		if result == nil {
+			result = append([]ChatCompletionMessage(nil), messages...)
+		}
+		result[i].ReasoningContent = ""
+	}
+	if result == nil {
+		return messages
+	}
+	return result
+}
@@ -0,0 +1,109 @@
+package types
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestResolveReasoning(t *testing.T) {
+	req := &ChatCompletionRequest{}
+	if reasoning, err := req.ResolveReasoning(); reasoning != nil || err != nil {
+		t.Errorf("未设置时应返回nil，得到 %+v, %v", reasoning, err)
+	}
+
+	// 旧的阿里云参数
+	req.WithEnableThinking(true).WithThinkingBudget(500)
+	reasoning, err := req.ResolveReasoning()
+	if err != nil || !reasoning.IsEnabled() || reasoning.BudgetTokens != 500 {
+		t.Errorf("期望开启推理且预算为500，得到 %+v, %v", reasoning, err)
+	}
+	req.WithEnableThinking(false)
+	if reasoning, err := req.ResolveReasoning(); err != nil || !reasoning.IsDisabled() || reasoning.BudgetTokens != 0 {
+		t.Errorf("关闭思考时应忽略预算，得到 %+v, %v", reasoning, err)
+	}
+
+	// 与 Reasoning 同时使用
+	req.WithReasoning(Reasoning{Effort: ReasoningEffortHigh})
+	if _, err := req.ResolveReasoning(); err == nil {
+		t.Error("Reasoning 和旧参数同时使用时应返回错误")
+	}
+
+	invalid := []Reasoning{
+		{Effort: "max"},
+		{BudgetTokens: -1},
+		{Enabled: ToPtr(false), Effort: ReasoningEffortLow},
+	}
+	for _, r := range invalid {
+		req := (&ChatCompletionRequest{}).WithReasoning(r)
+		if _, err := req.ResolveReasoning(); err == nil {
+			t.Errorf("期望 %+v 返回错误", r)
+		}
+	}
+
+	req = (&ChatCompletionRequest{}).WithReasoning(Reasoning{BudgetTokens: 1024})
+	if !req.IsThinkingEnabled() {
+		t.Error("设置预算时应视为开启推理")
+	}
+}
+
+func TestReasoningFieldNormalized(t *testing.T) {
+	var resp ChatCompletionResponse
+	data := `{"choices":[{"index":0,"message":{"role":"assistant","content":"4","reasoning":"2+2=4"}}],
+		"usage":{"prompt_tokens":5,"completion_tokens":10,"total_tokens":15,"completion_tokens_details":{"reasoning_tokens":8}}}`
+	if err := json.Unmarshal([]byte(data), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if got := resp.Choices[0].Message.ReasoningContent; got != "2+2=4" {
+		t.Errorf("期望 reasoning 转换为 ReasoningContent，得到 %q", got)
+	}
+	if resp.Usage.ReasoningTokens() != 8 {
+		t.Errorf("期望推理token为8，得到 %d", resp.Usage.ReasoningTokens())
+	}
+
+	var usage *Usage
+	if usage.ReasoningTokens() != 0 {
+		t.Error("nil Usage 应返回0")
+	}
+}
+
+func TestApplyReasoningHistory(t *testing.T) {
+	assistant := func(reasoning string) ChatCompletionMessage {
+		return ChatCompletionMessage{Role: RoleAssistant, Content: "ok", ReasoningContent: reasoning}
+	}
+	messages := []ChatCompletionMessage{
+		NewTextMessage(RoleUser, "第一个问题"),
+		assistant("第一轮思考"),
+		NewTextMessage(RoleUser, "查一下天气"),
+		assistant("需要调用工具"),
+		{Role: RoleTool, ToolCallID: "call_1", Content: "晴"},
+	}
+	reasoning := func(messages []ChatCompletionMessage) []string {
+		var result []string
+		for _, m := range messages {
+			if m.ReasoningContent != "" {
+				result = append(result, m.ReasoningContent)
+			}
+		}
+		return result
+	}
+
+	tests := []struct {
+		policy   ReasoningHistoryPolicy
+		fallback ReasoningHistoryPolicy
+		want     []string
+	}{
+		{ReasoningHistoryStrip, ReasoningHistoryKeep, nil},
+		{ReasoningHistoryToolLoop, ReasoningHistoryStrip, []string{"需要调用工具"}},
+		{ReasoningHistoryKeep, ReasoningHistoryStrip, []string{"第一轮思考", "需要调用工具"}},
+		{ReasoningHistoryAuto, ReasoningHistoryStrip, nil},
+	}
+	for _, tt := range tests {
+		got := reasoning(ApplyReasoningHistory(messages, tt.policy, tt.fallback))
+		if len(got) != len(tt.want) || (len(got) > 0 && got[0] != tt.want[0]) {
+			t.Errorf("%s: 期望 %v，得到 %v", tt.policy, tt.want, got)
+		}
+	}
+	if messages[1].ReasoningContent != "第一轮思考" {
+		t.Error("原消息不应被修改")
+	}
+}
@@ -1,8 +1,16 @@
 package deepseek
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
+	"github.com/yu1ec/go-anyllm/models"
+	"github.com/yu1ec/go-anyllm/pricing"
 	"github.com/yu1ec/go-anyllm/providers"
 	"github.com/yu1ec/go-anyllm/types"
 
@@ -245,3 +253,104 @@ func BenchmarkRequestCreation(b *testing.B) {
 		_ = req
 	}
 }
+
+func TestUnifiedClientValidatesBeforeSending(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Write([]byte(`{"choices":[{"index":0,"message":{"role":"assistant","content":"ok"}}]}`))
+	}))
+	defer server.Close()
+
+	temperature := float32(0.5)
+	req := &types.ChatCompletionRequest{
+		Model:       "o3-mini",
+		Messages:    []types.ChatCompletionMessage{types.NewTextMessage(types.RoleUser, "hi")},
+		Temperature: &temperature,
+	}
+
+	client, err := NewClientWithProvider(providers.ProviderOpenAI, "test-api-key", server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var validationErr *models.ValidationError
+	if _, err := client.CreateChatCompletion(context.Background(), req); !errors.As(err, &validationErr) {
+		t.Errorf("期望 ValidationError，得到 %v", err)
+	}
+	if requests != 0 {
+		t.Error("校验失败时不应发送请求")
+	}
+
+	client, err = NewUnifiedClient(&ClientConfig{Provider: providers.ProviderOpenAI, APIKey: "test-api-key", BaseURL: server.URL, SkipValidation: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.CreateChatCompletion(context.Background(), req); err != nil || requests != 1 {
+		t.Errorf("跳过校验时应发送请求: %v", err)
+	}
+}
+
+func TestUnifiedClientTracksCost(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req types.ChatCompletionRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		if !req.Stream {
+			w.Write([]byte(`{"model":"gpt-4o-2024-08-06","choices":[{"index":0,"message":{"role":"assistant","content":"ok"}}],
+				"usage":{"prompt_tokens":1000,"completion_tokens":100,"total_tokens":1100}}`))
+			return
+		}
+		if req.StreamOptions == nil || !req.StreamOptions.IncludeUsage {
+			t.Error("统计费用时流式请求应带上 include_usage")
+		}
+		w.Write([]byte("data: {\"model\":\"gpt-4o\",\"choices\":[{\"index\":0,\"delta\":{\"content\":\"ok\"}}]}\n\n" +
+			"data: {\"model\":\"gpt-4o\",\"choices\":[],\"usage\":{\"prompt_tokens\":2000,\"completion_tokens\":200,\"total_tokens\":2200}}\n\n" +
+			"data: [DONE]\n\n"))
+	}))
+	defer server.Close()
+
+	tracker := pricing.NewTracker()
+	client, err := NewUnifiedClient(&ClientConfig{Provider: providers.ProviderOpenAI, APIKey: "test-api-key", BaseURL: server.URL, CostTracker: tracker, CostTag: "default"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := &types.ChatCompletionRequest{Model: "gpt-4o", Messages: []types.ChatCompletionMessage{types.NewTextMessage(types.RoleUser, "hi")}}
+
+	if _, err := client.CreateChatCompletion(context.Background(), req); err != nil {
+		t.Fatal(err)
+	}
+	totals := tracker.Total("default")
+	if totals.Requests != 1 || math.Abs(totals.Cost[pricing.CurrencyUSD]-0.0035) > 1e-9 {
+		t.Errorf("期望累计0.0035美元，得到 %+v", totals)
+	}
+
+	stream, err := client.CreateChatCompletionStream(pricing.WithTag(context.Background(), "stream"), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for stream.Next() {
+		if len(stream.Current().Choices) == 0 {
+			t.Error("自动请求的用量数据块不应返回给调用方")
+		}
+	}
+	if totals := tracker.Total("stream"); totals.Requests != 1 || totals.InputTokens != 2000 || totals.OutputTokens != 200 {
+		t.Errorf("流结束时应按最后的用量累计，得到 %+v", totals)
+	}
+	if req.StreamOptions != nil {
+		t.Error("原请求不应被修改")
+	}
+
+	// 调用方自己请求 include_usage 时照常返回用量数据块
+	withUsage := *req
+	withUsage.StreamOptions = &types.StreamOptions{IncludeUsage: true}
+	stream, err = client.CreateChatCompletionStream(pricing.WithTag(context.Background(), "usage"), &withUsage)
+	if err != nil {
+		t.Fatal(err)
+	}
+	chunks := 0
+	for stream.Next() {
+		chunks++
+	}
+	if chunks != 2 || tracker.Total("usage").InputTokens != 2000 {
+		t.Errorf("期望返回2个数据块并累计用量，得到 %d 个，%+v", chunks, tracker.Total("usage"))
+	}
+}